@@ -12,6 +12,7 @@ import (
 
 var doRegister = sync.OnceFunc(func() {
 	internalaccess.RegisterProvider(config.AccessProviderTypeConfigAPIKey, newProvider)
+	internalaccess.RegisterProvider(config.AccessProviderTypeVirtualKey, newVirtualKeyProvider)
 })
 
 // Register ensures the config-access provider is available to the access manager.
@@ -53,32 +54,11 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*internalac
 	if len(p.keys) == 0 {
 		return nil, internalaccess.ErrNotHandled
 	}
-	authHeader := r.Header.Get("Authorization")
-	authHeaderGoogle := r.Header.Get("X-Goog-Api-Key")
-	authHeaderAnthropic := r.Header.Get("X-Api-Key")
-	queryKey := ""
-	queryAuthToken := ""
-	if r.URL != nil {
-		queryKey = r.URL.Query().Get("key")
-		queryAuthToken = r.URL.Query().Get("auth_token")
-	}
-	if authHeader == "" && authHeaderGoogle == "" && authHeaderAnthropic == "" && queryKey == "" && queryAuthToken == "" {
+	candidates := requestKeys(r)
+	if len(candidates) == 0 {
 		return nil, internalaccess.ErrNoCredentials
 	}
 
-	apiKey := extractBearerToken(authHeader)
-
-	candidates := []struct {
-		value  string
-		source string
-	}{
-		{apiKey, "authorization"},
-		{authHeaderGoogle, "x-goog-api-key"},
-		{authHeaderAnthropic, "x-api-key"},
-		{queryKey, "query-key"},
-		{queryAuthToken, "query-auth-token"},
-	}
-
 	for _, candidate := range candidates {
 		if candidate.value == "" {
 			continue
@@ -97,6 +77,37 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*internalac
 	return nil, internalaccess.ErrInvalidCredential
 }
 
+// keyCandidate is a key presented by a request and where it was found.
+type keyCandidate struct {
+	value  string
+	source string
+}
+
+// requestKeys returns the keys presented by r, in order of precedence. A
+// malformed Authorization header yields an empty key.
+func requestKeys(r *http.Request) []keyCandidate {
+	var candidates []keyCandidate
+	if header := r.Header.Get("Authorization"); header != "" {
+		candidates = append(candidates, keyCandidate{extractBearerToken(header), "authorization"})
+	}
+	if key := r.Header.Get("X-Goog-Api-Key"); key != "" {
+		candidates = append(candidates, keyCandidate{key, "x-goog-api-key"})
+	}
+	if key := r.Header.Get("X-Api-Key"); key != "" {
+		candidates = append(candidates, keyCandidate{key, "x-api-key"})
+	}
+	if r.URL != nil {
+		query := r.URL.Query()
+		if key := query.Get("key"); key != "" {
+			candidates = append(candidates, keyCandidate{key, "query-key"})
+		}
+		if token := query.Get("auth_token"); token != "" {
+			candidates = append(candidates, keyCandidate{token, "query-auth-token"})
+		}
+	}
+	return candidates
+}
+
 func extractBearerToken(header string) string {
 	if header == "" {
 		return ""
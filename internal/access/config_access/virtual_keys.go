@@ -0,0 +1,72 @@
+package configaccess
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	internalaccess "github.com/nghyane/llm-mux/internal/access"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+// virtualKeyProvider validates virtual keys by the hash of the presented key.
+type virtualKeyProvider struct {
+	name   string
+	hashes map[string]config.VirtualKey
+}
+
+func newVirtualKeyProvider(cfg *config.AccessProvider, root *config.SDKConfig) (internalaccess.Provider, error) {
+	name := cfg.Name
+	if name == "" {
+		name = config.VirtualKeyAccessProviderName
+	}
+	p := &virtualKeyProvider{name: name, hashes: make(map[string]config.VirtualKey)}
+	if root != nil {
+		for _, key := range root.VirtualKeys {
+			p.hashes[key.KeyHash] = key
+		}
+	}
+	return p, nil
+}
+
+func (p *virtualKeyProvider) Identifier() string {
+	return p.name
+}
+
+func (p *virtualKeyProvider) Authenticate(_ context.Context, r *http.Request) (*internalaccess.Result, error) {
+	if p == nil || len(p.hashes) == 0 {
+		return nil, internalaccess.ErrNotHandled
+	}
+	candidates := requestKeys(r)
+	if len(candidates) == 0 {
+		return nil, internalaccess.ErrNoCredentials
+	}
+	for _, candidate := range candidates {
+		if candidate.value == "" {
+			continue
+		}
+		key, ok := p.hashes[config.HashVirtualKey(candidate.value)]
+		if !ok {
+			continue
+		}
+		if !key.Active(time.Now()) {
+			return nil, internalaccess.ErrInvalidCredential
+		}
+		metadata := map[string]string{
+			"source":      candidate.source,
+			"virtual_key": key.ID,
+		}
+		if key.Name != "" {
+			metadata["name"] = key.Name
+		}
+		if key.Owner != "" {
+			metadata["owner"] = key.Owner
+		}
+		return &internalaccess.Result{
+			Provider:  p.Identifier(),
+			Principal: key.ID,
+			Metadata:  metadata,
+		}, nil
+	}
+	return nil, internalaccess.ErrInvalidCredential
+}
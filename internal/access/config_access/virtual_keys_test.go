@@ -0,0 +1,51 @@
+package configaccess
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	internalaccess "github.com/nghyane/llm-mux/internal/access"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestVirtualKeyProviderAuthenticate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	root := &config.SDKConfig{VirtualKeys: []config.VirtualKey{
+		{ID: "vk-active", Owner: "team-a", KeyHash: config.HashVirtualKey("sk-mux-active")},
+		{ID: "vk-expired", KeyHash: config.HashVirtualKey("sk-mux-expired"), ExpiresAt: &past},
+		{ID: "vk-revoked", KeyHash: config.HashVirtualKey("sk-mux-revoked"), RevokedAt: &past},
+	}}
+	p, err := newVirtualKeyProvider(config.MakeVirtualKeyProvider(root.VirtualKeys), root)
+	if err != nil {
+		t.Fatalf("newVirtualKeyProvider: %v", err)
+	}
+
+	tests := []struct {
+		key           string
+		wantPrincipal string
+		wantErr       error
+	}{
+		{"sk-mux-active", "vk-active", nil},
+		{"sk-mux-expired", "", internalaccess.ErrInvalidCredential},
+		{"sk-mux-revoked", "", internalaccess.ErrInvalidCredential},
+		{"sk-mux-unknown", "", internalaccess.ErrInvalidCredential},
+		{"", "", internalaccess.ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		if tt.key != "" {
+			r.Header.Set("Authorization", "Bearer "+tt.key)
+		}
+		res, err := p.Authenticate(context.Background(), r)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q: err = %v, want %v", tt.key, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (res.Principal != tt.wantPrincipal || res.Metadata["owner"] != "team-a") {
+			t.Errorf("%q: result = %+v, want principal %s", tt.key, res, tt.wantPrincipal)
+		}
+	}
+}
@@ -49,7 +49,10 @@ func ReconcileProviders(oldCfg, newCfg *config.Config, existing []Provider) (res
 			continue
 		}
 
-		forceRebuild := strings.EqualFold(strings.TrimSpace(providerCfg.Type), config.AccessProviderTypeConfigAPIKey)
+		// Built-in providers read their keys from the root config, so they are
+		// rebuilt on every reload.
+		typ := strings.TrimSpace(providerCfg.Type)
+		forceRebuild := strings.EqualFold(typ, config.AccessProviderTypeConfigAPIKey) || strings.EqualFold(typ, config.AccessProviderTypeVirtualKey)
 		if oldCfgProvider, ok := oldCfgMap[key]; ok {
 			isAliased := oldCfgProvider == providerCfg
 			if !forceRebuild && !isAliased && providerConfigEqual(oldCfgProvider, providerCfg) {
@@ -181,6 +184,9 @@ func accessProviderMap(cfg *config.Config) map[string]*config.AccessProvider {
 			}
 		}
 	}
+	if provider := config.MakeVirtualKeyProvider(cfg.VirtualKeys); provider != nil {
+		result[providerIdentifier(provider)] = provider
+	}
 	return result
 }
 
@@ -200,6 +206,9 @@ func collectProviderEntries(cfg *config.Config) []*config.AccessProvider {
 			entries = append(entries, inline)
 		}
 	}
+	if virtual := config.MakeVirtualKeyProvider(cfg.VirtualKeys); virtual != nil {
+		entries = append(entries, virtual)
+	}
 	return entries
 }
 
@@ -83,5 +83,12 @@ func BuildProviders(root *config.SDKConfig) ([]Provider, error) {
 			providers = append(providers, provider)
 		}
 	}
+	if virtual := config.MakeVirtualKeyProvider(root.VirtualKeys); virtual != nil {
+		provider, err := BuildProvider(virtual, root)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
 	return providers, nil
 }
@@ -12,6 +12,8 @@ import (
 	"github.com/nghyane/llm-mux/internal/interfaces"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
 )
 
@@ -45,11 +47,74 @@ func (h *BaseAPIHandler) UpdateClients(cfg *config.SDKConfig) { h.Cfg = cfg }
 
 func (h *BaseAPIHandler) UpdateRouting(routing *config.RoutingConfig) { h.Routing = routing }
 
-func (h *BaseAPIHandler) getFallbackChain(model string) []string {
-	if h.Routing == nil {
+// routeTarget is a model a request may be sent to, optionally pinned to one
+// provider.
+type routeTarget struct {
+	model    string
+	provider string
+	virtual  bool // a target of the requested virtual model
+}
+
+// scopeModel returns the model a virtual key must allow for target to serve a
+// request for modelName: modelName for the targets of a virtual model, which
+// the key was granted as a whole, and the target's own model otherwise.
+func (t routeTarget) scopeModel(modelName string) string {
+	if t.virtual {
+		return modelName
+	}
+	return t.model
+}
+
+// resolveRoute returns the targets planned for a request of modelName. A
+// virtual model expands to its targets in the order chosen for this request;
+// any other model is its own single target.
+func (h *BaseAPIHandler) resolveRoute(ctx context.Context, modelName string) []routeTarget {
+	name := modelName
+	vm := h.Routing.VirtualModel(name)
+	if vm == nil && h.Routing != nil {
+		name = h.Routing.ResolveModelAlias(modelName)
+		vm = h.Routing.VirtualModel(name)
+	}
+	if vm == nil {
+		return []routeTarget{{model: modelName}}
+	}
+	plan := vm.Plan(name, virtualStickyKey(ctx, vm))
+	route := make([]routeTarget, len(plan))
+	for i, t := range plan {
+		route[i] = routeTarget{model: t.Model, provider: t.Provider, virtual: true}
+	}
+	return route
+}
+
+// virtualStickyKey returns the client identity vm keeps on one target: the
+// caller's API key or the configured header.
+func virtualStickyKey(ctx context.Context, vm *config.VirtualModel) string {
+	if vm.Sticky == "api-key" {
+		return usage.APIKeyFromContext(ctx)
+	}
+	if header := vm.StickyHeader(); header != "" {
+		if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+			return c.GetHeader(header)
+		}
+	}
+	return ""
+}
+
+// getFallbackChain returns what to try after route[0] fails: the remaining
+// targets of a virtual model, then the fallbacks configured for model. A
+// request sent with the no-fallback hint gets none.
+func (h *BaseAPIHandler) getFallbackChain(ctx context.Context, route []routeTarget, model string) []routeTarget {
+	if noFallback(ctx) {
 		return nil
 	}
-	return h.Routing.GetFallbackChain(model)
+	fallbacks := route[1:]
+	if h.Routing == nil {
+		return fallbacks
+	}
+	for _, fb := range h.Routing.GetFallbackChain(model) {
+		fallbacks = append(fallbacks, routeTarget{model: fb})
+	}
+	return fallbacks
 }
 
 // Models returns all available models as maps from the global registry.
@@ -57,6 +122,22 @@ func (h *BaseAPIHandler) Models() []map[string]any {
 	return registry.GetGlobalRegistry().GetAvailableModels("openai")
 }
 
+// VirtualModels lists the configured virtual models in the model-list format
+// shared by the OpenAI and Claude endpoints.
+func (h *BaseAPIHandler) VirtualModels() []map[string]any {
+	names := h.Routing.VirtualModelNames()
+	models := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		models = append(models, map[string]any{
+			"id":       name,
+			"object":   "model",
+			"owned_by": "llm-mux",
+			"type":     "virtual",
+		})
+	}
+	return models
+}
+
 func (h *BaseAPIHandler) GetAlt(c *gin.Context) string {
 	alt, hasAlt := c.GetQuery("alt")
 	if !hasAlt {
@@ -72,6 +153,23 @@ func (h *BaseAPIHandler) GetContextWithCancel(ctx context.Context, handler inter
 	newCtx, cancel := context.WithCancel(ctx)
 	newCtx = context.WithValue(newCtx, ctxKeyGin, c)
 	newCtx = context.WithValue(newCtx, ctxKeyHandler, handler)
+	apiKey := c.GetString("apiKey")
+	newCtx = usage.WithAPIKey(newCtx, apiKey)
+	newCtx = h.withVirtualKey(newCtx, apiKey, c.GetString("accessProvider"))
+	newCtx = h.withAuthTags(newCtx, apiKey, c.GetHeader(config.AuthTagHeader))
+	newCtx, timeout := h.withRoutingHints(newCtx, c)
+	if timeout > 0 {
+		var stop context.CancelFunc
+		newCtx, stop = context.WithTimeout(newCtx, timeout)
+		cancelParent := cancel
+		cancel = func() {
+			stop()
+			cancelParent()
+		}
+	}
+	if h.Routing != nil && h.Routing.Admission.IsBackground(apiKey, c.GetHeader(config.PriorityHeader)) {
+		newCtx = provider.WithPriority(newCtx, provider.PriorityLow)
+	}
 	return newCtx, func(params ...any) {
 		if h.Cfg.RequestLog && len(params) == 1 {
 			switch data := params[0].(type) {
@@ -93,6 +191,9 @@ type ctxKey int
 const (
 	ctxKeyGin ctxKey = iota
 	ctxKeyHandler
+	ctxKeyVirtualKey
+	ctxKeyScopeError
+	ctxKeyNoFallback
 )
 
 func appendAPIResponse(c *gin.Context, data []byte) {
@@ -154,23 +255,31 @@ func extractErrorDetails(err error) (int, http.Header) {
 }
 
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	route := h.resolveRoute(ctx, modelName)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(route[0])
+	if errMsg == nil {
+		providers, errMsg = applyKeyScope(ctx, modelName, providers)
+	}
+	if errMsg == nil {
+		errMsg = checkModelKind(normalizedModel, false)
+	}
 	if errMsg != nil {
 		return nil, errMsg
 	}
-	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, false)
+	req, opts := buildRequestOpts(normalizedModel, rawJSON, withFileOwner(ctx, metadata), handlerType, alt, false)
 	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
 	if err == nil {
 		return resp.Payload, nil
 	}
 
-	fallbacks := h.getFallbackChain(normalizedModel)
-	for _, fallbackModel := range fallbacks {
-		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel)
-		if len(fbProviders) == 0 {
+	fallbacks := h.getFallbackChain(ctx, route, normalizedModel)
+	for _, fallback := range fallbacks {
+		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallback)
+		fbProviders, fbErrMsg := applyKeyScope(ctx, fallback.scopeModel(modelName), fbProviders)
+		if fbErrMsg != nil || len(fbProviders) == 0 {
 			continue
 		}
-		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, fbMetadata, handlerType, alt, false)
+		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, withFileOwner(ctx, fbMetadata), handlerType, alt, false)
 		fbResp, fbErr := h.AuthManager.Execute(ctx, fbProviders, fbReq, fbOpts)
 		if fbErr == nil {
 			return fbResp.Payload, nil
@@ -182,11 +291,17 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 }
 
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(h.resolveRoute(ctx, modelName)[0])
+	if errMsg == nil {
+		providers, errMsg = applyKeyScope(ctx, modelName, providers)
+	}
+	if errMsg == nil {
+		errMsg = checkModelKind(normalizedModel, false)
+	}
 	if errMsg != nil {
 		return nil, errMsg
 	}
-	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, false)
+	req, opts := buildRequestOpts(normalizedModel, rawJSON, withFileOwner(ctx, metadata), handlerType, alt, false)
 	resp, err := h.AuthManager.ExecuteCount(ctx, providers, req, opts)
 	if err != nil {
 		status, addon := extractErrorDetails(err)
@@ -195,27 +310,93 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 	return resp.Payload, nil
 }
 
+// ExecuteEmbedWithAuthManager runs an embedding request through the manager,
+// walking the model's fallback chain when the primary model fails.
+func (h *BaseAPIHandler) ExecuteEmbedWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, metadata map[string]any) ([]byte, *interfaces.ErrorMessage) {
+	route := h.resolveRoute(ctx, modelName)
+	providers, normalizedModel, details, errMsg := h.getRequestDetails(route[0])
+	if errMsg == nil {
+		providers, errMsg = applyKeyScope(ctx, modelName, providers)
+	}
+	if errMsg == nil {
+		errMsg = checkModelKind(normalizedModel, true)
+	}
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	req, opts := buildRequestOpts(normalizedModel, rawJSON, mergeMetadata(details, metadata), handlerType, "", false)
+	resp, err := h.AuthManager.ExecuteEmbed(ctx, providers, req, opts)
+	if err == nil {
+		return resp.Payload, nil
+	}
+
+	for _, fallback := range h.getFallbackChain(ctx, route, normalizedModel) {
+		fbProviders, fbNormalizedModel, fbDetails, _ := h.getRequestDetails(fallback)
+		fbProviders, fbErrMsg := applyKeyScope(ctx, fallback.scopeModel(modelName), fbProviders)
+		if fbErrMsg != nil || len(fbProviders) == 0 {
+			continue
+		}
+		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, mergeMetadata(fbDetails, metadata), handlerType, "", false)
+		if fbResp, fbErr := h.AuthManager.ExecuteEmbed(ctx, fbProviders, fbReq, fbOpts); fbErr == nil {
+			return fbResp.Payload, nil
+		}
+	}
+
+	status, addon := extractErrorDetails(err)
+	return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+}
+
+// ExecuteLiveWithAuthManager opens a realtime session for modelName, sending
+// rawJSON as the session setup. The caller owns the returned connection.
+func (h *BaseAPIHandler) ExecuteLiveWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte) (provider.LiveConn, *interfaces.ErrorMessage) {
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(h.resolveRoute(ctx, modelName)[0])
+	if errMsg == nil {
+		providers, errMsg = applyKeyScope(ctx, modelName, providers)
+	}
+	if errMsg == nil {
+		errMsg = checkModelKind(normalizedModel, false)
+	}
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, "", true)
+	conn, err := h.AuthManager.ExecuteLive(ctx, providers, req, opts)
+	if err != nil {
+		status, addon := extractErrorDetails(err)
+		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	}
+	return conn, nil
+}
+
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	route := h.resolveRoute(ctx, modelName)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(route[0])
+	if errMsg == nil {
+		providers, errMsg = applyKeyScope(ctx, modelName, providers)
+	}
+	if errMsg == nil {
+		errMsg = checkModelKind(normalizedModel, false)
+	}
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
 		close(errChan)
 		return nil, errChan
 	}
-	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, true)
+	req, opts := buildRequestOpts(normalizedModel, rawJSON, withFileOwner(ctx, metadata), handlerType, alt, true)
 	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 	if err == nil {
 		return h.wrapStreamChannel(ctx, chunks)
 	}
 
-	fallbacks := h.getFallbackChain(normalizedModel)
-	for _, fallbackModel := range fallbacks {
-		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel)
-		if len(fbProviders) == 0 {
+	fallbacks := h.getFallbackChain(ctx, route, normalizedModel)
+	for _, fallback := range fallbacks {
+		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallback)
+		fbProviders, fbErrMsg := applyKeyScope(ctx, fallback.scopeModel(modelName), fbProviders)
+		if fbErrMsg != nil || len(fbProviders) == 0 {
 			continue
 		}
-		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, fbMetadata, handlerType, alt, true)
+		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, withFileOwner(ctx, fbMetadata), handlerType, alt, true)
 		fbChunks, fbErr := h.AuthManager.ExecuteStream(ctx, fbProviders, fbReq, fbOpts)
 		if fbErr == nil {
 			return h.wrapStreamChannel(ctx, fbChunks)
@@ -264,7 +445,8 @@ func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan pr
 	return dataChan, errChan
 }
 
-func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
+func (h *BaseAPIHandler) getRequestDetails(target routeTarget) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
+	modelName := target.model
 	resolvedModelName := util.ResolveAutoModel(modelName)
 	specifiedProvider := util.ExtractProviderFromPrefixedModelID(resolvedModelName)
 	cleanModelName := util.NormalizeIncomingModelID(resolvedModelName)
@@ -279,6 +461,8 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	if isDynamic {
 		providers = []string{providerName}
 		normalizedModel = extractedModelName
+	} else if target.provider != "" {
+		providers = []string{target.provider}
 	} else if specifiedProvider != "" {
 		providers = []string{specifiedProvider}
 	} else {
@@ -293,6 +477,25 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	return providers, normalizedModel, metadata, nil
 }
 
+// checkModelKind rejects chat requests for embedding models and embedding requests
+// for chat models before an auth is selected, so the mismatch is neither sent
+// upstream nor counted against the auth. Models of OpenAI-compatible providers are
+// only typed when configured with type: embedding, so untyped ones are let through.
+func checkModelKind(model string, embed bool) *interfaces.ErrorMessage {
+	info := registry.GetGlobalRegistry().GetModelInfo(model)
+	if info == nil {
+		return nil
+	}
+	isEmbedding := info.Type == registry.ModelTypeEmbedding
+	switch {
+	case embed && !isEmbedding && info.Type != "openai-compatibility":
+		return &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("model %s does not support embeddings", model)}
+	case !embed && isEmbedding:
+		return &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("model %s is an embedding model and only supports embedding requests", model)}
+	}
+	return nil
+}
+
 func (h *BaseAPIHandler) parseDynamicModel(modelName string) (providerName, model string, isDynamic bool) {
 	if parts := strings.SplitN(modelName, "://", 2); len(parts) == 2 {
 		for _, pName := range h.OpenAICompatProviders {
@@ -304,6 +507,21 @@ func (h *BaseAPIHandler) parseDynamicModel(modelName string) (providerName, mode
 	return "", modelName, false
 }
 
+// mergeMetadata overlays extra on top of base without mutating either map.
+func mergeMetadata(base, extra map[string]any) map[string]any {
+	if len(extra) == 0 {
+		return base
+	}
+	out := make(map[string]any, len(base)+len(extra))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range extra {
+		out[k] = v
+	}
+	return out
+}
+
 func cloneBytes(src []byte) []byte {
 	if len(src) == 0 {
 		return nil
@@ -311,6 +529,12 @@ func cloneBytes(src []byte) []byte {
 	return bytes.Clone(src)
 }
 
+// withFileOwner records the calling API key in metadata so that translators
+// can inline references to files the caller stored through /v1/files.
+func withFileOwner(ctx context.Context, metadata map[string]any) map[string]any {
+	return mergeMetadata(metadata, map[string]any{ir.MetaFileOwner: usage.APIKeyFromContext(ctx)})
+}
+
 func cloneMetadata(src map[string]any) map[string]any {
 	if len(src) == 0 {
 		return nil
@@ -0,0 +1,475 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/batch"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/files"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// messageBatchPrefix prefixes message batch ids and keeps them apart from
+// OpenAI batches in the shared batch store.
+const messageBatchPrefix = "msgbatch_"
+
+// messageBatchEndpoint is the endpoint every message batch request targets.
+const messageBatchEndpoint = "/v1/messages"
+
+var customIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type messageBatchCounts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+// messageBatch is the Anthropic message batch object.
+type messageBatch struct {
+	ID                string             `json:"id"`
+	Type              string             `json:"type"`
+	ProcessingStatus  string             `json:"processing_status"`
+	RequestCounts     messageBatchCounts `json:"request_counts"`
+	EndedAt           *string            `json:"ended_at"`
+	CreatedAt         string             `json:"created_at"`
+	ExpiresAt         string             `json:"expires_at"`
+	ArchivedAt        *string            `json:"archived_at"`
+	CancelInitiatedAt *string            `json:"cancel_initiated_at"`
+	ResultsURL        *string            `json:"results_url"`
+}
+
+func writeClaudeError(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, claudeErrorResponse{
+		Type:  "error",
+		Error: claudeErrorDetail{Type: errType, Message: message},
+	})
+}
+
+// claudeErrorType maps an HTTP status to the Anthropic error type.
+func claudeErrorType(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request_error"
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusForbidden:
+		return "permission_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case 529:
+		return "overloaded_error"
+	default:
+		return "api_error"
+	}
+}
+
+func rfc3339(ts *int64) *string {
+	if ts == nil {
+		return nil
+	}
+	s := time.Unix(*ts, 0).UTC().Format(time.RFC3339)
+	return &s
+}
+
+// toMessageBatch renders b in the Anthropic message batch format.
+func toMessageBatch(c *gin.Context, b *batch.Batch) messageBatch {
+	created := b.CreatedAt
+	mb := messageBatch{
+		ID:                b.ID,
+		Type:              "message_batch",
+		CreatedAt:         *rfc3339(&created),
+		CancelInitiatedAt: rfc3339(b.CancellingAt),
+		RequestCounts: messageBatchCounts{
+			Succeeded: b.RequestCounts.Completed,
+			Errored:   b.RequestCounts.Failed - b.RequestCounts.Expired,
+			Expired:   b.RequestCounts.Expired,
+		},
+	}
+	if exp := rfc3339(b.ExpiresAt); exp != nil {
+		mb.ExpiresAt = *exp
+	}
+
+	counts := &mb.RequestCounts
+	rest := b.RequestCounts.Total - counts.Succeeded - counts.Errored - counts.Expired
+	switch {
+	case b.Status == batch.StatusCancelling:
+		mb.ProcessingStatus = "canceling"
+		counts.Processing = rest
+	case b.Status.Terminal():
+		mb.ProcessingStatus = "ended"
+		switch b.Status {
+		case batch.StatusCancelled:
+			counts.Canceled = rest
+			mb.EndedAt = rfc3339(b.CancelledAt)
+		case batch.StatusFailed:
+			counts.Errored += rest
+			mb.EndedAt = rfc3339(b.FailedAt)
+		case batch.StatusExpired:
+			mb.EndedAt = rfc3339(b.ExpiredAt)
+		default:
+			mb.EndedAt = rfc3339(b.CompletedAt)
+		}
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		url := fmt.Sprintf("%s://%s/v1/messages/batches/%s/results", scheme, c.Request.Host, b.ID)
+		mb.ResultsURL = &url
+	default:
+		mb.ProcessingStatus = "in_progress"
+		counts.Processing = rest
+	}
+	return mb
+}
+
+// batchRunner returns the batch runner, writing a 503 when it is unavailable.
+func batchRunner(c *gin.Context) *batch.Runner {
+	r := batch.Default()
+	if r == nil {
+		writeClaudeError(c, http.StatusServiceUnavailable, "api_error", "Message batches are not available.")
+	}
+	return r
+}
+
+// ownedMessageBatch loads the message batch named by the :id path parameter for
+// the calling API key. On failure it writes the error response and returns nil.
+func ownedMessageBatch(c *gin.Context, r *batch.Runner) *batch.Batch {
+	id := c.Param("id")
+	b, err := r.Store().Get(c.Request.Context(), id)
+	if err == nil && (b.APIKey != c.GetString("apiKey") || !strings.HasPrefix(b.ID, messageBatchPrefix)) {
+		err = batch.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, batch.ErrNotFound) {
+			writeClaudeError(c, http.StatusNotFound, "not_found_error", fmt.Sprintf("Message batch %s not found.", id))
+		} else {
+			writeClaudeError(c, http.StatusInternalServerError, "api_error", err.Error())
+		}
+		return nil
+	}
+	return b
+}
+
+// CreateMessageBatch handles POST /v1/messages/batches. The requests are stored
+// as a batch input file and executed by the shared batch runner, so they can
+// target any model, not only Claude.
+func (h *ClaudeCodeAPIHandler) CreateMessageBatch(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	requests := gjson.GetBytes(rawJSON, "requests")
+	if !requests.IsArray() || len(requests.Array()) == 0 {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", "requests: must be a non-empty array")
+		return
+	}
+	items := requests.Array()
+	if len(items) > batch.MaxRequests {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("requests: must contain at most %d items", batch.MaxRequests))
+		return
+	}
+
+	var input bytes.Buffer
+	seen := make(map[string]struct{}, len(items))
+	for i, item := range items {
+		customID := item.Get("custom_id").String()
+		if !customIDPattern.MatchString(customID) {
+			writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("requests.%d.custom_id: must be 1-64 letters, digits, underscores or hyphens", i))
+			return
+		}
+		if _, dup := seen[customID]; dup {
+			writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("requests.%d.custom_id: duplicate custom_id %q", i, customID))
+			return
+		}
+		seen[customID] = struct{}{}
+		params := item.Get("params")
+		if !params.IsObject() || params.Get("model").String() == "" {
+			writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("requests.%d.params: must be a Messages API request with a model", i))
+			return
+		}
+		line, _ := json.Marshal(struct {
+			CustomID string          `json:"custom_id"`
+			Method   string          `json:"method"`
+			URL      string          `json:"url"`
+			Body     json.RawMessage `json:"body"`
+		}{customID, http.MethodPost, messageBatchEndpoint, json.RawMessage(params.Raw)})
+		input.Write(line)
+		input.WriteByte('\n')
+	}
+
+	apiKey := c.GetString("apiKey")
+	id := files.NewID(messageBatchPrefix)
+	f, err := r.Files().CreateGenerated(c.Request.Context(), files.File{
+		APIKey:   apiKey,
+		Filename: id + "_input.jsonl",
+		Purpose:  files.PurposeMessageBatch,
+		MimeType: "application/jsonl",
+	}, &input)
+	if err != nil {
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", fmt.Sprintf("Failed to store batch requests: %v", err))
+		return
+	}
+
+	created := time.Now()
+	expires := created.Add(24 * time.Hour).Unix()
+	b := &batch.Batch{
+		ID:               id,
+		Object:           "batch",
+		Endpoint:         messageBatchEndpoint,
+		InputFileID:      f.ID,
+		CompletionWindow: "24h",
+		Status:           batch.StatusValidating,
+		CreatedAt:        created.Unix(),
+		ExpiresAt:        &expires,
+		RequestCounts:    batch.RequestCounts{Total: len(items)},
+		APIKey:           apiKey,
+		AccessProvider:   c.GetString("accessProvider"),
+		AuthTags:         c.GetHeader(config.AuthTagHeader),
+	}
+	if err := r.Submit(c.Request.Context(), b); err != nil {
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", fmt.Sprintf("Failed to create batch: %v", err))
+		return
+	}
+	c.JSON(http.StatusOK, toMessageBatch(c, b))
+}
+
+// RetrieveMessageBatch handles GET /v1/messages/batches/:id.
+func (h *ClaudeCodeAPIHandler) RetrieveMessageBatch(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	if b := ownedMessageBatch(c, r); b != nil {
+		c.JSON(http.StatusOK, toMessageBatch(c, b))
+	}
+}
+
+// ListMessageBatches handles GET /v1/messages/batches.
+// Supports the limit (1-1000, default 20), after_id and before_id query parameters.
+func (h *ClaudeCodeAPIHandler) ListMessageBatches(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	limit := 20
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v >= 1 && v <= 1000 {
+		limit = v
+	}
+	list, hasMore, err := r.Store().List(c.Request.Context(), batch.ListOptions{
+		APIKey: c.GetString("apiKey"),
+		Prefix: messageBatchPrefix,
+		After:  c.Query("after_id"),
+		Before: c.Query("before_id"),
+		Limit:  limit,
+	})
+	if err != nil {
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	data := make([]messageBatch, 0, len(list))
+	for _, b := range list {
+		data = append(data, toMessageBatch(c, b))
+	}
+	resp := gin.H{"data": data, "has_more": hasMore, "first_id": nil, "last_id": nil}
+	if len(data) > 0 {
+		resp["first_id"] = data[0].ID
+		resp["last_id"] = data[len(data)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CancelMessageBatch handles POST /v1/messages/batches/:id/cancel.
+func (h *ClaudeCodeAPIHandler) CancelMessageBatch(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	b := ownedMessageBatch(c, r)
+	if b == nil {
+		return
+	}
+	b, err := r.Cancel(c.Request.Context(), b.ID)
+	if err != nil {
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, toMessageBatch(c, b))
+}
+
+// DeleteMessageBatch handles DELETE /v1/messages/batches/:id. Only ended
+// batches can be deleted; their input and result files are removed as well.
+func (h *ClaudeCodeAPIHandler) DeleteMessageBatch(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	b := ownedMessageBatch(c, r)
+	if b == nil {
+		return
+	}
+	if !b.Status.Terminal() {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", "Message batch must have ended before it can be deleted; cancel it first.")
+		return
+	}
+	if err := r.Store().Delete(c.Request.Context(), b.ID); err != nil && !errors.Is(err, batch.ErrNotFound) {
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	for _, id := range []*string{&b.InputFileID, b.OutputFileID, b.ErrorFileID} {
+		if id != nil && *id != "" {
+			_ = r.Files().Delete(c.Request.Context(), *id)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"id": b.ID, "type": "message_batch_deleted"})
+}
+
+// MessageBatchResults handles GET /v1/messages/batches/:id/results and streams
+// one JSON line per request.
+func (h *ClaudeCodeAPIHandler) MessageBatchResults(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	b := ownedMessageBatch(c, r)
+	if b == nil {
+		return
+	}
+	if !b.Status.Terminal() {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Message batch %s has not ended yet.", b.ID))
+		return
+	}
+
+	c.Header("Content-Type", "application/x-jsonl")
+	c.Status(http.StatusOK)
+	w := bufio.NewWriter(c.Writer)
+	defer func() { _ = w.Flush() }()
+
+	written := make(map[string]struct{}, b.RequestCounts.Total)
+	emit := func(customID string, result any) {
+		line, _ := json.Marshal(gin.H{"custom_id": customID, "result": result})
+		_, _ = w.Write(line)
+		_ = w.WriteByte('\n')
+		written[customID] = struct{}{}
+	}
+
+	for _, id := range []*string{b.OutputFileID, b.ErrorFileID} {
+		if id == nil {
+			continue
+		}
+		_ = eachLine(c.Request.Context(), r.Files(), *id, func(line []byte) {
+			emit(gjson.GetBytes(line, "custom_id").String(), messageBatchResult(line))
+		})
+	}
+
+	// Requests without a result never ran: the batch was cancelled or failed.
+	if b.Status == batch.StatusCancelled || b.Status == batch.StatusFailed {
+		_ = eachLine(c.Request.Context(), r.Files(), b.InputFileID, func(line []byte) {
+			customID := gjson.GetBytes(line, "custom_id").String()
+			if _, ok := written[customID]; !ok {
+				emit(customID, unrunMessageBatchResult(b.Status))
+			}
+		})
+	}
+}
+
+// messageBatchResult converts a line of a batch output or error file into the
+// result of a message batch request.
+func messageBatchResult(line []byte) gin.H {
+	root := gjson.ParseBytes(line)
+	status := int(root.Get("response.status_code").Int())
+	body := root.Get("response.body")
+	switch {
+	case root.Get("error.code").String() == batch.CodeExpired:
+		return gin.H{"type": "expired"}
+	case status >= 200 && status < 300:
+		return gin.H{"type": "succeeded", "message": json.RawMessage(body.Raw)}
+	}
+	errBody := body.Raw
+	if !body.IsObject() || !body.Get("error").Exists() {
+		errBody, _ = sjson.Set(`{"type":"error","error":{}}`, "error.type", claudeErrorType(status))
+		errBody, _ = sjson.Set(errBody, "error.message", body.String())
+	}
+	return gin.H{"type": "errored", "error": json.RawMessage(errBody)}
+}
+
+// unrunMessageBatchResult is the result of a request that never ran in a batch
+// that ended with status.
+func unrunMessageBatchResult(status batch.Status) gin.H {
+	if status == batch.StatusCancelled {
+		return gin.H{"type": "canceled"}
+	}
+	return gin.H{"type": "errored", "error": claudeErrorResponse{
+		Type:  "error",
+		Error: claudeErrorDetail{Type: "api_error", Message: "The batch failed before this request ran."},
+	}}
+}
+
+// eachLine calls fn for every non-empty line of file id.
+func eachLine(ctx context.Context, store *files.Store, id string, fn func([]byte)) error {
+	f, err := store.Open(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			fn(line)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ExecuteBatchRequest runs one message batch request through the same
+// Claude→IR→provider path as the non-streaming /v1/messages endpoint.
+func (h *ClaudeCodeAPIHandler) ExecuteBatchRequest(ctx context.Context, _ string, body []byte) (int, []byte) {
+	body, _ = sjson.DeleteBytes(body, "stream")
+	modelName := gjson.GetBytes(body, "model").String()
+	resp, errMsg := h.ExecuteWithAuthManager(ctx, h.HandlerType(), modelName, body, "")
+	if errMsg != nil {
+		status := http.StatusInternalServerError
+		if errMsg.StatusCode > 0 {
+			status = errMsg.StatusCode
+		}
+		msg := http.StatusText(status)
+		if errMsg.Error != nil {
+			msg = errMsg.Error.Error()
+		}
+		data, _ := json.Marshal(claudeErrorResponse{
+			Type:  "error",
+			Error: claudeErrorDetail{Type: claudeErrorType(status), Message: msg},
+		})
+		return status, data
+	}
+	return http.StatusOK, decompressGzip(resp)
+}
@@ -0,0 +1,159 @@
+package claude
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/batch"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/tidwall/gjson"
+)
+
+func TestToMessageBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "http://mux.local/v1/messages/batches", nil)
+	ts := int64(1700000000)
+
+	tests := []struct {
+		name   string
+		batch  batch.Batch
+		status string
+		counts messageBatchCounts
+		ended  bool
+	}{
+		{
+			name:   "in progress",
+			batch:  batch.Batch{Status: batch.StatusInProgress, RequestCounts: batch.RequestCounts{Total: 5, Completed: 2, Failed: 1}},
+			status: "in_progress",
+			counts: messageBatchCounts{Processing: 2, Succeeded: 2, Errored: 1},
+		},
+		{
+			name:   "validating",
+			batch:  batch.Batch{Status: batch.StatusValidating, RequestCounts: batch.RequestCounts{Total: 3}},
+			status: "in_progress",
+			counts: messageBatchCounts{Processing: 3},
+		},
+		{
+			name:   "canceling",
+			batch:  batch.Batch{Status: batch.StatusCancelling, CancellingAt: &ts, RequestCounts: batch.RequestCounts{Total: 5, Completed: 1}},
+			status: "canceling",
+			counts: messageBatchCounts{Processing: 4, Succeeded: 1},
+		},
+		{
+			name:   "completed",
+			batch:  batch.Batch{Status: batch.StatusCompleted, CompletedAt: &ts, RequestCounts: batch.RequestCounts{Total: 2, Completed: 1, Failed: 1}},
+			status: "ended",
+			counts: messageBatchCounts{Succeeded: 1, Errored: 1},
+			ended:  true,
+		},
+		{
+			name:   "cancelled",
+			batch:  batch.Batch{Status: batch.StatusCancelled, CancelledAt: &ts, RequestCounts: batch.RequestCounts{Total: 5, Completed: 1, Failed: 1}},
+			status: "ended",
+			counts: messageBatchCounts{Succeeded: 1, Errored: 1, Canceled: 3},
+			ended:  true,
+		},
+		{
+			name:   "failed",
+			batch:  batch.Batch{Status: batch.StatusFailed, FailedAt: &ts, RequestCounts: batch.RequestCounts{Total: 3, Completed: 1}},
+			status: "ended",
+			counts: messageBatchCounts{Succeeded: 1, Errored: 2},
+			ended:  true,
+		},
+		{
+			name:   "expired",
+			batch:  batch.Batch{Status: batch.StatusExpired, ExpiredAt: &ts, RequestCounts: batch.RequestCounts{Total: 4, Completed: 1, Failed: 3, Expired: 2}},
+			status: "ended",
+			counts: messageBatchCounts{Succeeded: 1, Errored: 1, Expired: 2},
+			ended:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.batch.ID = "msgbatch_x"
+			mb := toMessageBatch(c, &tt.batch)
+			if mb.ProcessingStatus != tt.status {
+				t.Errorf("processing_status = %q, want %q", mb.ProcessingStatus, tt.status)
+			}
+			if mb.RequestCounts != tt.counts {
+				t.Errorf("request_counts = %+v, want %+v", mb.RequestCounts, tt.counts)
+			}
+			if ended := mb.EndedAt != nil; ended != tt.ended {
+				t.Errorf("ended_at set = %v, want %v", ended, tt.ended)
+			}
+			if hasURL := mb.ResultsURL != nil; hasURL != tt.ended {
+				t.Errorf("results_url set = %v, want %v", hasURL, tt.ended)
+			} else if hasURL && *mb.ResultsURL != "http://mux.local/v1/messages/batches/msgbatch_x/results" {
+				t.Errorf("results_url = %s", *mb.ResultsURL)
+			}
+		})
+	}
+}
+
+func TestMessageBatchResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantType  string
+		wantField string
+		wantValue string
+	}{
+		{
+			name:      "succeeded",
+			line:      `{"custom_id":"a","response":{"status_code":200,"body":{"id":"msg_1","type":"message"}},"error":null}`,
+			wantType:  "succeeded",
+			wantField: "message.id",
+			wantValue: "msg_1",
+		},
+		{
+			name:      "errored with claude error body",
+			line:      `{"custom_id":"a","response":{"status_code":429,"body":{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}},"error":null}`,
+			wantType:  "errored",
+			wantField: "error.error.type",
+			wantValue: "rate_limit_error",
+		},
+		{
+			name:      "errored with plain body",
+			line:      `{"custom_id":"a","response":{"status_code":404,"body":"no such model"},"error":null}`,
+			wantType:  "errored",
+			wantField: "error.error.message",
+			wantValue: "no such model",
+		},
+		{
+			name:     "expired",
+			line:     `{"custom_id":"a","response":null,"error":{"code":"` + batch.CodeExpired + `","message":"expired"}}`,
+			wantType: "expired",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _ := json.Marshal(messageBatchResult([]byte(tt.line)))
+			if got := gjson.GetBytes(out, "type").String(); got != tt.wantType {
+				t.Fatalf("type = %q, want %q (%s)", got, tt.wantType, out)
+			}
+			if tt.wantField != "" {
+				if got := gjson.GetBytes(out, tt.wantField).String(); got != tt.wantValue {
+					t.Errorf("%s = %q, want %q", tt.wantField, got, tt.wantValue)
+				}
+			}
+		})
+	}
+}
+
+func TestUnrunMessageBatchResult(t *testing.T) {
+	tests := []struct {
+		status   batch.Status
+		wantType string
+	}{
+		{batch.StatusCancelled, "canceled"},
+		{batch.StatusFailed, "errored"},
+	}
+	for _, tt := range tests {
+		out, _ := json.Marshal(unrunMessageBatchResult(tt.status))
+		if got := gjson.GetBytes(out, "type").String(); got != tt.wantType {
+			t.Errorf("%s: type = %q, want %q", tt.status, got, tt.wantType)
+		}
+	}
+}
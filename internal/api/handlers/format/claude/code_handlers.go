@@ -33,7 +33,7 @@ func (h *ClaudeCodeAPIHandler) HandlerType() string {
 }
 
 func (h *ClaudeCodeAPIHandler) Models() []map[string]any {
-	return registry.GetGlobalRegistry().GetAvailableModels("claude")
+	return append(registry.GetGlobalRegistry().GetAvailableModels("claude"), h.VirtualModels()...)
 }
 
 func (h *ClaudeCodeAPIHandler) ClaudeMessages(c *gin.Context) {
@@ -105,30 +105,32 @@ func (h *ClaudeCodeAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSO
 		return
 	}
 
-	// Decompress gzipped responses - Claude API sometimes returns gzip without Content-Encoding header
-	// This fixes title generation and other non-streaming responses that arrive compressed
-	if len(resp) >= 2 && resp[0] == 0x1f && resp[1] == 0x8b {
-		gr := executor.GzipReaderPool.Get().(*gzip.Reader)
-		if err := gr.Reset(bytes.NewReader(resp)); err != nil {
-			executor.GzipReaderPool.Put(gr)
-			log.Warnf("failed to reset gzip reader: %v", err)
-		} else {
-			defer func() {
-				gr.Close()
-				executor.GzipReaderPool.Put(gr)
-			}()
-			if decompressed, err := io.ReadAll(gr); err != nil {
-				log.Warnf("failed to read decompressed Claude response: %v", err)
-			} else {
-				resp = decompressed
-			}
-		}
-	}
-
-	_, _ = c.Writer.Write(resp)
+	_, _ = c.Writer.Write(decompressGzip(resp))
 	cliCancel()
 }
 
+// decompressGzip inflates gzipped responses - Claude API sometimes returns gzip without
+// Content-Encoding header. This fixes title generation and other non-streaming responses
+// that arrive compressed. Other payloads are returned unchanged.
+func decompressGzip(resp []byte) []byte {
+	if len(resp) < 2 || resp[0] != 0x1f || resp[1] != 0x8b {
+		return resp
+	}
+	gr := executor.GzipReaderPool.Get().(*gzip.Reader)
+	defer executor.GzipReaderPool.Put(gr)
+	if err := gr.Reset(bytes.NewReader(resp)); err != nil {
+		log.Warnf("failed to reset gzip reader: %v", err)
+		return resp
+	}
+	defer gr.Close()
+	decompressed, err := io.ReadAll(gr)
+	if err != nil {
+		log.Warnf("failed to read decompressed Claude response: %v", err)
+		return resp
+	}
+	return decompressed
+}
+
 func (h *ClaudeCodeAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byte) {
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -0,0 +1,188 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/files"
+)
+
+// anthropicFilePurpose is the purpose recorded for files uploaded through the
+// Anthropic Files API, which has no purpose of its own.
+const anthropicFilePurpose = "user_data"
+
+// fileMetadata is the Anthropic file object. Files are shared with /v1/files
+// in the OpenAI format, so an id from either API works in requests of both.
+type fileMetadata struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Filename     string `json:"filename"`
+	MimeType     string `json:"mime_type"`
+	SizeBytes    int64  `json:"size_bytes"`
+	CreatedAt    string `json:"created_at"`
+	Downloadable bool   `json:"downloadable"`
+}
+
+func toFileMetadata(f *files.File) fileMetadata {
+	return fileMetadata{
+		ID:           f.ID,
+		Type:         "file",
+		Filename:     f.Filename,
+		MimeType:     f.MimeType,
+		SizeBytes:    f.Bytes,
+		CreatedAt:    time.Unix(f.CreatedAt, 0).UTC().Format(time.RFC3339),
+		Downloadable: true,
+	}
+}
+
+// fileStore returns the file store, writing a 503 when it is unavailable.
+func fileStore(c *gin.Context) *files.Store {
+	store := files.Default()
+	if store == nil {
+		writeClaudeError(c, http.StatusServiceUnavailable, "api_error", "File storage is not available.")
+	}
+	return store
+}
+
+// ownedFile loads the file named by the :id path parameter for the calling API
+// key. On failure it writes the error response and returns nil.
+func ownedFile(c *gin.Context, store *files.Store) *files.File {
+	id := c.Param("id")
+	f, err := store.Get(c.Request.Context(), id)
+	if err == nil && (f.APIKey != c.GetString("apiKey") || f.Internal()) {
+		err = files.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, files.ErrNotFound) {
+			writeClaudeError(c, http.StatusNotFound, "not_found_error", fmt.Sprintf("File not found: %s", id))
+		} else {
+			writeClaudeError(c, http.StatusInternalServerError, "api_error", err.Error())
+		}
+		return nil
+	}
+	return f
+}
+
+// UploadFile handles POST /v1/files in the Anthropic format: a multipart body
+// with a single "file" field.
+func (h *ClaudeCodeAPIHandler) UploadFile(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("file: %v", err))
+		return
+	}
+	src, err := header.Open()
+	if err != nil {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid file: %v", err))
+		return
+	}
+	defer src.Close()
+
+	f, err := store.Create(c.Request.Context(), files.File{
+		APIKey:   c.GetString("apiKey"),
+		Filename: header.Filename,
+		Purpose:  anthropicFilePurpose,
+		MimeType: files.DetectMimeType(header.Header.Get("Content-Type"), header.Filename, nil),
+	}, src)
+	if err != nil {
+		if errors.Is(err, files.ErrTooLarge) {
+			writeClaudeError(c, http.StatusRequestEntityTooLarge, "request_too_large", err.Error())
+			return
+		}
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", fmt.Sprintf("Failed to store file: %v", err))
+		return
+	}
+	c.JSON(http.StatusOK, toFileMetadata(f))
+}
+
+// ListFiles handles GET /v1/files in the Anthropic format.
+// Supports the limit (1-1000, default 20), after_id and before_id query parameters.
+func (h *ClaudeCodeAPIHandler) ListFiles(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	limit := 20
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v >= 1 && v <= 1000 {
+		limit = v
+	}
+	list, hasMore, err := store.List(c.Request.Context(), files.ListOptions{
+		APIKey: c.GetString("apiKey"),
+		After:  c.Query("after_id"),
+		Before: c.Query("before_id"),
+		Limit:  limit,
+	})
+	if err != nil {
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	data := make([]fileMetadata, 0, len(list))
+	for _, f := range list {
+		data = append(data, toFileMetadata(f))
+	}
+	resp := gin.H{"data": data, "has_more": hasMore, "first_id": nil, "last_id": nil}
+	if len(data) > 0 {
+		resp["first_id"] = data[0].ID
+		resp["last_id"] = data[len(data)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RetrieveFile handles GET /v1/files/:id in the Anthropic format.
+func (h *ClaudeCodeAPIHandler) RetrieveFile(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	if f := ownedFile(c, store); f != nil {
+		c.JSON(http.StatusOK, toFileMetadata(f))
+	}
+}
+
+// RetrieveFileContent handles GET /v1/files/:id/content in the Anthropic format.
+func (h *ClaudeCodeAPIHandler) RetrieveFileContent(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	f := ownedFile(c, store)
+	if f == nil {
+		return
+	}
+	content, err := store.Open(c.Request.Context(), f.ID)
+	if err != nil {
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	defer content.Close()
+	c.Header("Content-Type", f.MimeType)
+	c.Header("Content-Length", strconv.FormatInt(f.Bytes, 10))
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, content)
+}
+
+// DeleteFile handles DELETE /v1/files/:id in the Anthropic format.
+func (h *ClaudeCodeAPIHandler) DeleteFile(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	f := ownedFile(c, store)
+	if f == nil {
+		return
+	}
+	if err := store.Delete(c.Request.Context(), f.ID); err != nil && !errors.Is(err, files.ErrNotFound) {
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": f.ID, "type": "file_deleted"})
+}
@@ -50,6 +50,10 @@ func (h *GeminiAPIHandler) GeminiGetHandler(c *gin.Context) {
 		})
 		return
 	}
+	if strings.HasSuffix(request.Action, ":BidiGenerateContent") {
+		h.GeminiLive(c)
+		return
+	}
 	switch request.Action {
 	case "gemini-3-pro-preview":
 		c.JSON(http.StatusOK, gin.H{
@@ -174,9 +178,34 @@ func (h *GeminiAPIHandler) GeminiHandler(c *gin.Context) {
 		h.handleStreamGenerateContent(c, action[0], rawJSON)
 	case "countTokens":
 		h.handleCountTokens(c, action[0], rawJSON)
+	case "embedContent", "batchEmbedContents":
+		h.handleEmbedContent(c, action[0], method, rawJSON)
+	default:
+		c.JSON(http.StatusNotFound, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Method %s is not supported.", method),
+				Type:    "invalid_request_error",
+			},
+		})
 	}
 }
 
+// handleEmbedContent serves embedContent and batchEmbedContents. The action is
+// passed as request metadata so that the response keeps the shape of the
+// method called, whichever provider computed the embeddings.
+func (h *GeminiAPIHandler) handleEmbedContent(c *gin.Context, modelName, method string, rawJSON []byte) {
+	c.Header("Content-Type", "application/json")
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	resp, errMsg := h.ExecuteEmbedWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, map[string]any{"action": method})
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	_, _ = c.Writer.Write(resp)
+	cliCancel()
+}
+
 func (h *GeminiAPIHandler) handleStreamGenerateContent(c *gin.Context, modelName string, rawJSON []byte) {
 	alt := h.GetAlt(c)
 
@@ -0,0 +1,114 @@
+package gemini
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// liveSetupTimeout bounds the wait for the client's setup message.
+const liveSetupTimeout = 30 * time.Second
+
+var liveUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// GeminiLive proxies a Live API (BidiGenerateContent) WebSocket session. The
+// first client message must be the setup message; its model, or the model in
+// the URL when setup names none, selects the auth. Frames are then relayed
+// unchanged in both directions until either side closes.
+func (h *GeminiAPIHandler) GeminiLive(c *gin.Context) {
+	client, err := liveUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written the HTTP error response.
+		return
+	}
+	defer func() { _ = client.Close() }()
+
+	_ = client.SetReadDeadline(time.Now().Add(liveSetupTimeout))
+	_, setup, err := client.ReadMessage()
+	_ = client.SetReadDeadline(time.Time{})
+	if err != nil {
+		return
+	}
+	modelName := strings.TrimPrefix(gjson.GetBytes(setup, "setup.model").String(), "models/")
+	if modelName == "" {
+		modelName, _, _ = strings.Cut(c.Param("action"), ":")
+	}
+	if modelName == "" {
+		writeLiveClose(client, websocket.CloseInvalidFramePayloadData, "the first message must be a setup message with a model")
+		return
+	}
+
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	upstream, errMsg := h.ExecuteLiveWithAuthManager(cliCtx, h.HandlerType(), modelName, setup)
+	if errMsg != nil {
+		code := websocket.CloseInternalServerErr
+		if errMsg.StatusCode >= 400 && errMsg.StatusCode < 500 {
+			code = websocket.ClosePolicyViolation
+		}
+		writeLiveClose(client, code, errMsg.Error.Error())
+		cliCancel(errMsg.Error)
+		return
+	}
+	defer func() { _ = upstream.Close() }()
+
+	relayLive(client, upstream)
+	cliCancel()
+}
+
+// relayLive copies messages between the client and upstream until one side
+// closes or fails. A close frame is passed on to the other side.
+func relayLive(client *websocket.Conn, upstream provider.LiveConn) {
+	var once sync.Once
+	done := make(chan struct{})
+	finish := func() { once.Do(func() { close(done) }) }
+
+	pipe := func(dst, src provider.LiveConn) {
+		defer finish()
+		for {
+			messageType, data, err := src.ReadMessage()
+			if err != nil {
+				var closeErr *websocket.CloseError
+				if errors.As(err, &closeErr) {
+					code := closeErr.Code
+					if code == websocket.CloseNoStatusReceived || code == websocket.CloseAbnormalClosure {
+						code = websocket.CloseNormalClosure
+					}
+					_ = dst.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, closeErr.Text))
+				}
+				return
+			}
+			if err = dst.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}
+	go pipe(upstream, client)
+	go pipe(client, upstream)
+
+	<-done
+	// Closing both ends unblocks the reader still waiting on the other side.
+	_ = client.Close()
+	_ = upstream.Close()
+}
+
+// writeLiveClose closes the client session with code and reason, truncating the
+// reason to the 123 bytes a close frame can carry.
+func writeLiveClose(conn *websocket.Conn, code int, reason string) {
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+}
@@ -0,0 +1,107 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// WithClientScope scopes ctx to a client that is not making an HTTP request
+// of its own, such as one whose batch is running: the key restrictions that
+// GetContextWithCancel applies to its requests apply to ctx. apiKey and
+// accessProvider are what authentication stored for the client's request and
+// authTags is the auth tag header it sent.
+func (h *BaseAPIHandler) WithClientScope(ctx context.Context, apiKey, accessProvider, authTags string) context.Context {
+	if accessProvider == config.VirtualKeyAccessProviderName && !h.Cfg.VirtualKey(apiKey).Active(time.Now()) {
+		return context.WithValue(ctx, ctxKeyScopeError, &interfaces.ErrorMessage{StatusCode: http.StatusUnauthorized, Error: errors.New("the API key is no longer valid")})
+	}
+	ctx = h.withVirtualKey(ctx, apiKey, accessProvider)
+	return h.withAuthTags(ctx, apiKey, authTags)
+}
+
+// withVirtualKey scopes ctx to the virtual key the request authenticated with,
+// if any: its provider and auth label restrictions apply to auth selection and
+// its metadata to usage records.
+func (h *BaseAPIHandler) withVirtualKey(ctx context.Context, apiKey, accessProvider string) context.Context {
+	if accessProvider != config.VirtualKeyAccessProviderName {
+		return ctx
+	}
+	vk := h.Cfg.VirtualKey(apiKey)
+	if vk == nil {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, ctxKeyVirtualKey, vk)
+	if len(vk.Providers) > 0 || len(vk.AuthLabels) > 0 {
+		ctx = provider.WithAuthScope(ctx, &provider.AuthScope{Providers: vk.Providers, Labels: vk.AuthLabels})
+	}
+	return usage.WithMetadata(ctx, vk.Metadata)
+}
+
+// withAuthTags restricts ctx to the auths carrying the tags bound to the
+// request's API key, narrowed by header, the auth tag header. A header asking
+// for a tag outside the binding is recorded for applyKeyScope to reject.
+func (h *BaseAPIHandler) withAuthTags(ctx context.Context, apiKey, header string) context.Context {
+	bound := h.Routing.AuthTagsFor(apiKey)
+	if vk, _ := ctx.Value(ctxKeyVirtualKey).(*config.VirtualKey); vk != nil && len(vk.AuthTags) > 0 {
+		bound = vk.AuthTags
+	}
+	tags, err := config.ResolveAuthTags(bound, header)
+	if err != nil {
+		return context.WithValue(ctx, ctxKeyScopeError, &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: err})
+	}
+	if len(tags) == 0 {
+		return ctx
+	}
+	var scope provider.AuthScope
+	if current := provider.AuthScopeFromContext(ctx); current != nil {
+		scope = *current
+	}
+	scope.Tags = tags
+	return provider.WithAuthScope(ctx, &scope)
+}
+
+// applyKeyScope rejects modelName when the request's virtual key may not use
+// it, and drops the providers the key or a provider hint rules out. It also
+// reports a rejected auth tag or routing hint header.
+func applyKeyScope(ctx context.Context, modelName string, providers []string) ([]string, *interfaces.ErrorMessage) {
+	if errMsg, _ := ctx.Value(ctxKeyScopeError).(*interfaces.ErrorMessage); errMsg != nil {
+		return nil, errMsg
+	}
+	vk, _ := ctx.Value(ctxKeyVirtualKey).(*config.VirtualKey)
+	if vk != nil && len(vk.Models) > 0 && !keyAllowsModel(vk, modelName) {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("model %s is not allowed for this API key", modelName)}
+	}
+	scope := provider.AuthScopeFromContext(ctx)
+	if scope == nil || len(scope.Providers) == 0 {
+		return providers, nil
+	}
+	allowed := make([]string, 0, len(providers))
+	for _, p := range providers {
+		if scope.AllowsProvider(p) {
+			allowed = append(allowed, p)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("no provider this request may use serves model %s", modelName)}
+	}
+	return allowed, nil
+}
+
+func keyAllowsModel(vk *config.VirtualKey, modelName string) bool {
+	model := strings.ToLower(modelName)
+	for _, pattern := range vk.Models {
+		if sseutil.MatchModelPattern(strings.ToLower(pattern), model) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package ollama
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/tidwall/gjson"
+)
+
+// Embed handles POST /api/embed. The input, a string or an array of strings,
+// is sent as an OpenAI embeddings request so that every embedding provider
+// can serve it.
+func (h *OllamaAPIHandler) Embed(c *gin.Context) {
+	req, ok := h.parseEmbedRequest(c, "input")
+	if !ok {
+		return
+	}
+	start := time.Now()
+	resp, ok := h.embed(c, req, req.Get("input").Value())
+	if !ok {
+		return
+	}
+	embeddings := make([]json.RawMessage, 0)
+	for _, item := range gjson.GetBytes(resp, "data").Array() {
+		embeddings = append(embeddings, json.RawMessage(item.Get("embedding").Raw))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"model":             req.Get("model").String(),
+		"embeddings":        embeddings,
+		"total_duration":    time.Since(start).Nanoseconds(),
+		"load_duration":     0,
+		"prompt_eval_count": gjson.GetBytes(resp, "usage.prompt_tokens").Int(),
+	})
+}
+
+// Embeddings handles the legacy POST /api/embeddings, which embeds a single
+// prompt.
+func (h *OllamaAPIHandler) Embeddings(c *gin.Context) {
+	req, ok := h.parseEmbedRequest(c, "prompt")
+	if !ok {
+		return
+	}
+	resp, ok := h.embed(c, req, req.Get("prompt").String())
+	if !ok {
+		return
+	}
+	embedding := gjson.GetBytes(resp, "data.0.embedding").Raw
+	if embedding == "" {
+		embedding = "[]"
+	}
+	c.JSON(http.StatusOK, gin.H{"embedding": json.RawMessage(embedding)})
+}
+
+// parseEmbedRequest reads the body and checks that model and the input field
+// are set, writing a 400 otherwise.
+func (h *OllamaAPIHandler) parseEmbedRequest(c *gin.Context, inputField string) (gjson.Result, bool) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
+
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return gjson.Result{}, false
+	}
+	req := gjson.ParseBytes(rawJSON)
+	for _, field := range []string{"model", inputField} {
+		if !req.Get(field).Exists() {
+			c.JSON(http.StatusBadRequest, format.ErrorResponse{
+				Error: format.ErrorDetail{
+					Message: field + " is required",
+					Type:    "invalid_request_error",
+				},
+			})
+			return gjson.Result{}, false
+		}
+	}
+	return req, true
+}
+
+// embed runs input through the embedding path of the manager and returns the
+// OpenAI embeddings response, writing the error response on failure.
+func (h *OllamaAPIHandler) embed(c *gin.Context, req gjson.Result, input any) ([]byte, bool) {
+	modelName := req.Get("model").String()
+	payload := map[string]any{"model": modelName, "input": input}
+	if d := req.Get("dimensions").Int(); d > 0 {
+		payload["dimensions"] = d
+	}
+	rawJSON, err := json.Marshal(payload)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Failed to convert request: %v", err),
+				Type:    "server_error",
+			},
+		})
+		return nil, false
+	}
+	h.loaded.touch(modelName, req.Get("keep_alive"))
+
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	resp, errMsg := h.ExecuteEmbedWithAuthManager(cliCtx, constant.OpenAI, modelName, rawJSON, nil)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return nil, false
+	}
+	cliCancel()
+	return resp, true
+}
@@ -23,11 +23,13 @@ const (
 
 type OllamaAPIHandler struct {
 	*format.BaseAPIHandler
+	loaded *loadedModels
 }
 
 func NewOllamaAPIHandler(apiHandlers *format.BaseAPIHandler) *OllamaAPIHandler {
 	return &OllamaAPIHandler{
 		BaseAPIHandler: apiHandlers,
+		loaded:         newLoadedModels(),
 	}
 }
 
@@ -117,12 +119,16 @@ func (h *OllamaAPIHandler) Show(c *gin.Context) {
 		modelName = name
 	}
 
-	if modelName == "" {
-		modelName = "unknown"
+	showResponse, ok := from_ir.ToOllamaShowResponse(modelName)
+	if !ok {
+		c.JSON(http.StatusNotFound, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("model '%s' not found", modelName),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
 	}
-
-	// Generate Ollama show response
-	showResponse := from_ir.ToOllamaShowResponse(modelName)
 	c.Data(http.StatusOK, "application/json", showResponse)
 }
 
@@ -178,6 +184,8 @@ func (h *OllamaAPIHandler) Chat(c *gin.Context) {
 		return
 	}
 
+	h.loaded.touch(modelName, ollamaRequest.Get("keep_alive"))
+
 	// Use OpenAI handler to process the request
 	openaiHandler := openai.NewOpenAIAPIHandler(h.BaseAPIHandler)
 
@@ -240,6 +248,8 @@ func (h *OllamaAPIHandler) Generate(c *gin.Context) {
 		return
 	}
 
+	h.loaded.touch(modelName, ollamaRequest.Get("keep_alive"))
+
 	// Use OpenAI handler to process the request
 	openaiHandler := openai.NewOpenAIAPIHandler(h.BaseAPIHandler)
 
@@ -0,0 +1,156 @@
+package ollama
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+// defaultKeepAlive is how long Ollama keeps a model loaded after a request.
+const defaultKeepAlive = 5 * time.Minute
+
+// loadedModels emulates Ollama's loaded-model list: remote models are never
+// loaded, so a model counts as loaded for keep_alive after its last request
+// through the Ollama API.
+type loadedModels struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+func newLoadedModels() *loadedModels {
+	return &loadedModels{expires: make(map[string]time.Time)}
+}
+
+// touch records a request for model with the given keep_alive value, a
+// duration string or a number of seconds. Zero unloads the model and a
+// negative value keeps it loaded indefinitely.
+func (l *loadedModels) touch(model string, keepAlive gjson.Result) {
+	d := defaultKeepAlive
+	switch keepAlive.Type {
+	case gjson.Number:
+		d = time.Duration(keepAlive.Float() * float64(time.Second))
+	case gjson.String:
+		if parsed, err := time.ParseDuration(keepAlive.String()); err == nil {
+			d = parsed
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case d == 0:
+		delete(l.expires, model)
+	case d < 0:
+		l.expires[model] = time.Date(2318, 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		l.expires[model] = time.Now().Add(d)
+	}
+}
+
+// list returns the models still loaded at now with their expiry, dropping
+// expired entries.
+func (l *loadedModels) list(now time.Time) map[string]time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[string]time.Time, len(l.expires))
+	for model, exp := range l.expires {
+		if now.After(exp) {
+			delete(l.expires, model)
+			continue
+		}
+		out[model] = exp
+	}
+	return out
+}
+
+// Ps handles GET /api/ps, listing the models used within their keep_alive.
+func (h *OllamaAPIHandler) Ps(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
+
+	loaded := h.loaded.list(time.Now())
+	names := make([]string, 0, len(loaded))
+	for name := range loaded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	models := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		entry := map[string]any{
+			"name":       name,
+			"model":      name,
+			"size":       0,
+			"digest":     "",
+			"expires_at": loaded[name].UTC().Format(time.RFC3339),
+			"size_vram":  0,
+			"details": map[string]any{
+				"parent_model":       "",
+				"format":             "gguf",
+				"family":             "Ollama",
+				"families":           []string{"Ollama"},
+				"parameter_size":     "0B",
+				"quantization_level": "Q4_0",
+			},
+		}
+		if info := registry.GetGlobalRegistry().GetModelInfo(name); info != nil {
+			if info.ContextLength > 0 {
+				entry["context_length"] = info.ContextLength
+			} else if info.InputTokenLimit > 0 {
+				entry["context_length"] = info.InputTokenLimit
+			}
+		}
+		models = append(models, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"models": models})
+}
+
+// Pull handles POST /api/pull. Models are served remotely, so pulling only
+// checks that the model exists and reports success, streamed as NDJSON
+// unless stream is false.
+func (h *OllamaAPIHandler) Pull(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Server", fmt.Sprintf("ollama/%s", OllamaVersion))
+
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+	req := gjson.ParseBytes(rawJSON)
+	modelName := req.Get("model").String()
+	if modelName == "" {
+		modelName = req.Get("name").String()
+	}
+	if modelName == "" || registry.GetGlobalRegistry().GetModelInfo(modelName) == nil {
+		c.JSON(http.StatusNotFound, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("pull model manifest: model '%s' not found", modelName),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	if stream := req.Get("stream"); stream.Exists() && !stream.Bool() {
+		c.JSON(http.StatusOK, gin.H{"status": "success"})
+		return
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	for _, status := range []string{"pulling manifest", "verifying sha256 digest", "writing manifest", "success"} {
+		_, _ = c.Writer.WriteString(`{"status":"` + status + `"}` + "\n")
+	}
+	c.Writer.Flush()
+}
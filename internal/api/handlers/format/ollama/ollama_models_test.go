@@ -0,0 +1,42 @@
+package ollama
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestLoadedModelsKeepAlive(t *testing.T) {
+	l := newLoadedModels()
+	now := time.Now()
+
+	l.touch("default", gjson.Result{})
+	l.touch("seconds", gjson.Parse(`30`))
+	l.touch("duration", gjson.Parse(`"1h"`))
+	l.touch("forever", gjson.Parse(`-1`))
+	l.touch("unloaded", gjson.Result{})
+	l.touch("unloaded", gjson.Parse(`0`))
+
+	loaded := l.list(now)
+	if _, ok := loaded["unloaded"]; ok || len(loaded) != 4 {
+		t.Fatalf("loaded = %v", loaded)
+	}
+	if exp := loaded["seconds"]; exp.Sub(now) > 31*time.Second {
+		t.Errorf("keep_alive 30 expires at %v", exp)
+	}
+	if exp := loaded["duration"]; exp.Sub(now) < 59*time.Minute {
+		t.Errorf("keep_alive 1h expires at %v", exp)
+	}
+
+	later := l.list(now.Add(10 * time.Minute))
+	if _, ok := later["default"]; ok {
+		t.Errorf("model still loaded after the default keep_alive")
+	}
+	if _, ok := later["seconds"]; ok {
+		t.Errorf("model still loaded after keep_alive 30")
+	}
+	if _, ok := later["forever"]; !ok {
+		t.Errorf("negative keep_alive expired")
+	}
+}
@@ -0,0 +1,342 @@
+package openai
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/files"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// defaultTranscriptionModel serves transcriptions without a model or for
+	// OpenAI transcription models that no provider offers.
+	defaultTranscriptionModel = "gemini-2.5-flash"
+	// defaultSpeechModel does the same for speech.
+	defaultSpeechModel = "gemini-2.5-flash-preview-tts"
+	// speechSampleRate is the rate of the 16-bit mono PCM Gemini TTS returns.
+	speechSampleRate = 24000
+	// maxSpeechInput is the input limit of /v1/audio/speech.
+	maxSpeechInput = 4096
+)
+
+// transcriptSegmentsSchema is the structured output requested for the
+// timestamped transcription formats.
+var transcriptSegmentsSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"language": map[string]any{"type": "string", "description": "ISO-639-1 code of the spoken language"},
+		"segments": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"start": map[string]any{"type": "number", "description": "start time in seconds"},
+					"end":   map[string]any{"type": "number", "description": "end time in seconds"},
+					"text":  map[string]any{"type": "string"},
+				},
+				"required": []string{"start", "end", "text"},
+			},
+		},
+	},
+	"required": []string{"language", "segments"},
+}
+
+// transcriptSegment is one timed span of a transcript.
+type transcriptSegment struct {
+	ID    int     `json:"id"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// AudioTranscriptions handles POST /v1/audio/transcriptions.
+// The uploaded audio is sent to a multimodal model with a transcription
+// prompt. The json and text formats ask for the plain transcript; srt, vtt and
+// verbose_json ask for timed segments as structured output.
+func (h *OpenAIAPIHandler) AudioTranscriptions(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("file is required: %v", err))
+		return
+	}
+	responseFormat := c.DefaultPostForm("response_format", "json")
+	switch responseFormat {
+	case "json", "text", "srt", "vtt", "verbose_json":
+	default:
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("unsupported response_format %q", responseFormat))
+		return
+	}
+	src, err := header.Open()
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid file: %v", err))
+		return
+	}
+	data, err := io.ReadAll(src)
+	_ = src.Close()
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid file: %v", err))
+		return
+	}
+	format, ok := inputAudioFormat(files.DetectMimeType(header.Header.Get("Content-Type"), header.Filename, data))
+	if !ok {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("%s is not an audio file", header.Filename))
+		return
+	}
+
+	model := resolveModel(c.PostForm("model"), defaultTranscriptionModel, "whisper", "gpt-4o-transcribe", "gpt-4o-mini-transcribe")
+	timed := responseFormat != "json" && responseFormat != "text"
+	payload := map[string]any{
+		"model": model,
+		"messages": []any{map[string]any{"role": "user", "content": []any{
+			map[string]any{"type": "text", "text": transcriptionPrompt(c.PostForm("language"), c.PostForm("prompt"), timed)},
+			map[string]any{"type": "input_audio", "input_audio": map[string]string{"data": base64.StdEncoding.EncodeToString(data), "format": format}},
+		}}},
+	}
+	if t, errParse := strconv.ParseFloat(c.PostForm("temperature"), 64); errParse == nil {
+		payload["temperature"] = t
+	}
+	if timed {
+		payload["response_format"] = map[string]any{
+			"type":        "json_schema",
+			"json_schema": map[string]any{"name": "transcript", "schema": transcriptSegmentsSchema},
+		}
+	}
+	rawJSON, err := json.Marshal(payload)
+	if err != nil {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), model, rawJSON, "")
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	content := strings.TrimSpace(gjson.GetBytes(resp, "choices.0.message.content").String())
+
+	switch responseFormat {
+	case "text":
+		c.String(http.StatusOK, content+"\n")
+	case "json":
+		inputTokens := gjson.GetBytes(resp, "usage.prompt_tokens").Int()
+		outputTokens := gjson.GetBytes(resp, "usage.completion_tokens").Int()
+		c.JSON(http.StatusOK, gin.H{
+			"text": content,
+			"usage": gin.H{
+				"type":          "tokens",
+				"input_tokens":  inputTokens,
+				"output_tokens": outputTokens,
+				"total_tokens":  inputTokens + outputTokens,
+			},
+		})
+	default:
+		language, segments, errParse := parseTranscriptSegments(content)
+		if errParse != nil {
+			writeAPIError(c, http.StatusBadGateway, "server_error", fmt.Sprintf("model %s returned an invalid transcript: %v", model, errParse))
+			cliCancel(errParse)
+			return
+		}
+		writeTimedTranscript(c, responseFormat, language, segments)
+	}
+	cliCancel()
+}
+
+// AudioSpeech handles POST /v1/audio/speech.
+// The input is sent to a Gemini TTS model with audio output; OpenAI voices are
+// mapped onto Gemini voices during translation. Gemini returns raw PCM, which
+// is served as wav (the default here) or pcm; compressed formats are rejected.
+func (h *OpenAIAPIHandler) AudioSpeech(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	root := gjson.ParseBytes(rawJSON)
+	input := root.Get("input").String()
+	voice := root.Get("voice").String()
+	switch {
+	case strings.TrimSpace(input) == "":
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", "input is required")
+		return
+	case len([]rune(input)) > maxSpeechInput:
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("input must be at most %d characters", maxSpeechInput))
+		return
+	case voice == "":
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", "voice is required")
+		return
+	}
+	responseFormat := root.Get("response_format").String()
+	if responseFormat == "" {
+		responseFormat = "wav"
+	}
+	if responseFormat != "wav" && responseFormat != "pcm" {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("response_format %q is not supported, use \"wav\" or \"pcm\"", responseFormat))
+		return
+	}
+	if instructions := root.Get("instructions").String(); instructions != "" {
+		// Gemini TTS takes style directions as part of the text.
+		input = instructions + ": " + input
+	}
+
+	model := resolveModel(root.Get("model").String(), defaultSpeechModel, "tts-", "gpt-4o-mini-tts")
+	payload, err := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []any{map[string]any{"role": "user", "content": input}},
+		"modalities": []string{"audio"},
+		"audio":      map[string]string{"voice": voice, "format": "pcm16"},
+	})
+	if err != nil {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), model, payload, "")
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	pcm, err := base64.StdEncoding.DecodeString(gjson.GetBytes(resp, "choices.0.message.audio.data").String())
+	if err != nil || len(pcm) == 0 {
+		err = fmt.Errorf("model %s returned no audio", model)
+		writeAPIError(c, http.StatusBadGateway, "server_error", err.Error())
+		cliCancel(err)
+		return
+	}
+	if responseFormat == "pcm" {
+		c.Data(http.StatusOK, "audio/pcm", pcm)
+	} else {
+		c.Data(http.StatusOK, "audio/wav", pcmToWAV(pcm, speechSampleRate))
+	}
+	cliCancel()
+}
+
+// inputAudioFormat maps the media type of an upload onto an input_audio
+// format. Video containers are accepted for their audio track.
+func inputAudioFormat(mimeType string) (string, bool) {
+	kind, sub, _ := strings.Cut(mimeType, "/")
+	if kind != "audio" && kind != "video" {
+		return "", false
+	}
+	switch sub {
+	case "wav", "wave", "x-wav", "vnd.wave":
+		return "wav", true
+	case "mpeg", "mp3", "mpga":
+		return "mp3", true
+	case "mp4", "m4a", "x-m4a":
+		return "m4a", true
+	}
+	return strings.TrimPrefix(sub, "x-"), true
+}
+
+// transcriptionPrompt builds the instruction sent with the audio. The prompt
+// form field carries vocabulary or preceding context, as it does for Whisper.
+func transcriptionPrompt(language, context string, timed bool) string {
+	var sb strings.Builder
+	sb.WriteString("Transcribe this audio verbatim")
+	if language != "" {
+		fmt.Fprintf(&sb, ". The speech is in the language with ISO-639-1 code %q", language)
+	}
+	if timed {
+		sb.WriteString(". Split the transcript into segments of at most a few sentences with their start and end times in seconds, and report the spoken language")
+	} else {
+		sb.WriteString(". Reply with the transcript only, without any commentary")
+	}
+	sb.WriteString(".")
+	if context != "" {
+		sb.WriteString("\n\nContext and spelling hints:\n")
+		sb.WriteString(context)
+	}
+	return sb.String()
+}
+
+// parseTranscriptSegments decodes the structured transcript of a timed request.
+func parseTranscriptSegments(content string) (string, []transcriptSegment, error) {
+	var out struct {
+		Language string              `json:"language"`
+		Segments []transcriptSegment `json:"segments"`
+	}
+	if err := json.Unmarshal([]byte(content), &out); err != nil {
+		return "", nil, err
+	}
+	for i := range out.Segments {
+		out.Segments[i].ID = i
+		out.Segments[i].Text = strings.TrimSpace(out.Segments[i].Text)
+	}
+	return out.Language, out.Segments, nil
+}
+
+// writeTimedTranscript writes segments as srt, vtt or verbose_json.
+func writeTimedTranscript(c *gin.Context, responseFormat, language string, segments []transcriptSegment) {
+	texts := make([]string, len(segments))
+	for i, s := range segments {
+		texts[i] = s.Text
+	}
+	switch responseFormat {
+	case "verbose_json":
+		var duration float64
+		if len(segments) > 0 {
+			duration = segments[len(segments)-1].End
+		}
+		if segments == nil {
+			segments = []transcriptSegment{}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"task":     "transcribe",
+			"language": language,
+			"duration": duration,
+			"text":     strings.Join(texts, " "),
+			"segments": segments,
+		})
+	case "srt":
+		var sb strings.Builder
+		for i, s := range segments {
+			fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n\n", i+1, subtitleTime(s.Start, ","), subtitleTime(s.End, ","), s.Text)
+		}
+		c.String(http.StatusOK, sb.String())
+	case "vtt":
+		var sb strings.Builder
+		sb.WriteString("WEBVTT\n\n")
+		for _, s := range segments {
+			fmt.Fprintf(&sb, "%s --> %s\n%s\n\n", subtitleTime(s.Start, "."), subtitleTime(s.End, "."), s.Text)
+		}
+		c.String(http.StatusOK, sb.String())
+	}
+}
+
+// subtitleTime formats seconds as HH:MM:SS followed by sep and milliseconds.
+func subtitleTime(seconds float64, sep string) string {
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// pcmToWAV wraps 16-bit mono little-endian PCM in a WAV header.
+func pcmToWAV(pcm []byte, sampleRate int) []byte {
+	const channels, bitsPerSample = 1, 16
+	out := make([]byte, 44+len(pcm))
+	copy(out[0:], "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(36+len(pcm)))
+	copy(out[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(out[16:], 16)
+	binary.LittleEndian.PutUint16(out[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(out[22:], channels)
+	binary.LittleEndian.PutUint32(out[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(out[28:], uint32(sampleRate*channels*bitsPerSample/8))
+	binary.LittleEndian.PutUint16(out[32:], channels*bitsPerSample/8)
+	binary.LittleEndian.PutUint16(out[34:], bitsPerSample)
+	copy(out[36:], "data")
+	binary.LittleEndian.PutUint32(out[40:], uint32(len(pcm)))
+	copy(out[44:], pcm)
+	return out
+}
@@ -0,0 +1,71 @@
+package openai
+
+import (
+	"encoding/binary"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInputAudioFormat(t *testing.T) {
+	tests := map[string]string{
+		"audio/wav":   "wav",
+		"audio/x-wav": "wav",
+		"audio/mpeg":  "mp3",
+		"audio/mp4":   "m4a",
+		"audio/flac":  "flac",
+		"video/webm":  "webm",
+	}
+	for mimeType, want := range tests {
+		if got, ok := inputAudioFormat(mimeType); !ok || got != want {
+			t.Errorf("inputAudioFormat(%q) = %q, %v; want %q", mimeType, got, ok, want)
+		}
+	}
+	if _, ok := inputAudioFormat("image/png"); ok {
+		t.Errorf("image accepted as audio")
+	}
+}
+
+func TestWriteTimedTranscript(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, segments, err := parseTranscriptSegments(`{"language":"en","segments":[{"start":0,"end":1.5,"text":" Hello. "},{"start":1.5,"end":3661.25,"text":"Bye."}]}`)
+	if err != nil {
+		t.Fatalf("parseTranscriptSegments: %v", err)
+	}
+
+	tests := map[string]string{
+		"srt": "1\n00:00:00,000 --> 00:00:01,500\nHello.\n\n2\n00:00:01,500 --> 01:01:01,250\nBye.\n\n",
+		"vtt": "WEBVTT\n\n00:00:00.000 --> 00:00:01.500\nHello.\n\n00:00:01.500 --> 01:01:01.250\nBye.\n\n",
+	}
+	for format, want := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		writeTimedTranscript(c, format, "en", segments)
+		if got := w.Body.String(); got != want {
+			t.Errorf("%s =\n%q\nwant\n%q", format, got, want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	writeTimedTranscript(c, "verbose_json", "en", segments)
+	want := `{"duration":3661.25,"language":"en","segments":[{"id":0,"start":0,"end":1.5,"text":"Hello."},{"id":1,"start":1.5,"end":3661.25,"text":"Bye."}],"task":"transcribe","text":"Hello. Bye."}`
+	if got := w.Body.String(); got != want {
+		t.Errorf("verbose_json = %s", got)
+	}
+}
+
+func TestPCMToWAV(t *testing.T) {
+	pcm := []byte{1, 2, 3, 4}
+	wav := pcmToWAV(pcm, speechSampleRate)
+	if len(wav) != 48 || string(wav[0:4]) != "RIFF" || string(wav[8:16]) != "WAVEfmt " || string(wav[36:40]) != "data" {
+		t.Fatalf("malformed header % x", wav[:44])
+	}
+	if rate := binary.LittleEndian.Uint32(wav[24:]); rate != speechSampleRate {
+		t.Errorf("sample rate = %d", rate)
+	}
+	if size := binary.LittleEndian.Uint32(wav[40:]); size != 4 {
+		t.Errorf("data size = %d", size)
+	}
+}
@@ -0,0 +1,237 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/batch"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/files"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// batchEndpoints lists the endpoints a batch may target.
+var batchEndpoints = map[string]struct{}{
+	"/v1/chat/completions": {},
+	"/v1/completions":      {},
+	"/v1/embeddings":       {},
+	"/v1/responses":        {},
+}
+
+// openAIBatchPrefix prefixes OpenAI batch ids; message batches use "msgbatch_".
+const openAIBatchPrefix = "batch_"
+
+// batchCompletionWindow is the only completion window accepted, as in the OpenAI API.
+const batchCompletionWindow = 24 * time.Hour
+
+// batchRunner returns the batch runner, writing a 503 when it is unavailable.
+func batchRunner(c *gin.Context) *batch.Runner {
+	r := batch.Default()
+	if r == nil {
+		writeAPIError(c, http.StatusServiceUnavailable, "server_error", "Batch processing is not available.")
+	}
+	return r
+}
+
+// ownedBatch loads the batch named by the :id path parameter for the calling API key.
+// On failure it writes the error response and returns nil.
+func ownedBatch(c *gin.Context, r *batch.Runner) *batch.Batch {
+	id := c.Param("id")
+	b, err := r.Store().Get(c.Request.Context(), id)
+	if err == nil && (b.APIKey != c.GetString("apiKey") || !strings.HasPrefix(b.ID, openAIBatchPrefix)) {
+		err = batch.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, batch.ErrNotFound) {
+			writeAPIError(c, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("No batch found with id '%s'.", id))
+		} else {
+			writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		}
+		return nil
+	}
+	return b
+}
+
+// CreateBatch handles POST /v1/batches.
+func (h *OpenAIAPIHandler) CreateBatch(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	var req struct {
+		InputFileID      string            `json:"input_file_id"`
+		Endpoint         string            `json:"endpoint"`
+		CompletionWindow string            `json:"completion_window"`
+		Metadata         map[string]string `json:"metadata"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	if _, ok := batchEndpoints[req.Endpoint]; !ok {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Unsupported endpoint %q.", req.Endpoint))
+		return
+	}
+	if req.CompletionWindow != "24h" {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", "completion_window must be \"24h\".")
+		return
+	}
+	if len(req.Metadata) > 16 {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", "metadata may contain at most 16 pairs.")
+		return
+	}
+
+	apiKey := c.GetString("apiKey")
+	input, err := r.Files().Get(c.Request.Context(), req.InputFileID)
+	if err != nil || input.APIKey != apiKey || input.Internal() {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("No such File object: %s", req.InputFileID))
+		return
+	}
+	if input.Purpose != "batch" {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", "The input file must be uploaded with purpose \"batch\".")
+		return
+	}
+
+	created := time.Now()
+	expires := created.Add(batchCompletionWindow).Unix()
+	b := &batch.Batch{
+		ID:               files.NewID(openAIBatchPrefix),
+		Object:           "batch",
+		Endpoint:         req.Endpoint,
+		InputFileID:      input.ID,
+		CompletionWindow: req.CompletionWindow,
+		Status:           batch.StatusValidating,
+		CreatedAt:        created.Unix(),
+		ExpiresAt:        &expires,
+		Metadata:         req.Metadata,
+		APIKey:           apiKey,
+		AccessProvider:   c.GetString("accessProvider"),
+		AuthTags:         c.GetHeader(config.AuthTagHeader),
+	}
+	if err := r.Submit(c.Request.Context(), b); err != nil {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", fmt.Sprintf("Failed to create batch: %v", err))
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+// RetrieveBatch handles GET /v1/batches/:id.
+func (h *OpenAIAPIHandler) RetrieveBatch(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	if b := ownedBatch(c, r); b != nil {
+		c.JSON(http.StatusOK, b)
+	}
+}
+
+// CancelBatch handles POST /v1/batches/:id/cancel.
+func (h *OpenAIAPIHandler) CancelBatch(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	b := ownedBatch(c, r)
+	if b == nil {
+		return
+	}
+	b, err := r.Cancel(c.Request.Context(), b.ID)
+	if err != nil {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+// ListBatches handles GET /v1/batches.
+// Supports the limit (1-100, default 20) and after query parameters.
+func (h *OpenAIAPIHandler) ListBatches(c *gin.Context) {
+	r := batchRunner(c)
+	if r == nil {
+		return
+	}
+	limit := 20
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v >= 1 && v <= 100 {
+		limit = v
+	}
+	list, hasMore, err := r.Store().List(c.Request.Context(), batch.ListOptions{
+		APIKey: c.GetString("apiKey"),
+		Prefix: openAIBatchPrefix,
+		After:  c.Query("after"),
+		Limit:  limit,
+	})
+	if err != nil {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	resp := gin.H{"object": "list", "data": list, "has_more": hasMore}
+	if len(list) > 0 {
+		resp["first_id"] = list[0].ID
+		resp["last_id"] = list[len(list)-1].ID
+	} else {
+		resp["data"] = []*batch.Batch{}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ExecuteBatchRequest runs one line of a batch input file. It is the
+// batch.ExecFunc used by the batch runner and mirrors the non-streaming path
+// of the matching HTTP endpoint.
+func (h *OpenAIAPIHandler) ExecuteBatchRequest(ctx context.Context, endpoint string, body []byte) (int, []byte) {
+	body, _ = sjson.DeleteBytes(body, "stream")
+	modelName := gjson.GetBytes(body, "model").String()
+	if modelName == "" {
+		return batchErrorBody(http.StatusBadRequest, "invalid_request_error", "model is required")
+	}
+
+	var (
+		resp   []byte
+		errMsg *interfaces.ErrorMessage
+	)
+	switch endpoint {
+	case "/v1/chat/completions":
+		resp, errMsg = h.ExecuteWithAuthManager(ctx, h.HandlerType(), modelName, body, "")
+	case "/v1/completions":
+		resp, errMsg = h.ExecuteWithAuthManager(ctx, h.HandlerType(), modelName, convertCompletionsRequestToChatCompletions(body), "")
+		if errMsg == nil {
+			resp = convertChatCompletionsResponseToCompletions(resp)
+		}
+	case "/v1/responses":
+		resp, errMsg = h.ExecuteWithAuthManager(ctx, constant.OpenaiResponse, modelName, body, "")
+	case "/v1/embeddings":
+		resp, errMsg = h.ExecuteEmbedWithAuthManager(ctx, h.HandlerType(), modelName, body, nil)
+	default:
+		return batchErrorBody(http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Unsupported endpoint %q.", endpoint))
+	}
+	if errMsg != nil {
+		status := http.StatusInternalServerError
+		if errMsg.StatusCode > 0 {
+			status = errMsg.StatusCode
+		}
+		msg := http.StatusText(status)
+		if errMsg.Error != nil {
+			msg = errMsg.Error.Error()
+		}
+		return batchErrorBody(status, "server_error", msg)
+	}
+	return http.StatusOK, resp
+}
+
+func batchErrorBody(status int, errType, message string) (int, []byte) {
+	data, _ := json.Marshal(format.ErrorResponse{
+		Error: format.ErrorDetail{Message: message, Type: errType},
+	})
+	return status, data
+}
@@ -0,0 +1,200 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/batch"
+	"github.com/nghyane/llm-mux/internal/files"
+)
+
+// writeAPIError writes an OpenAI-style error body with the given status.
+func writeAPIError(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, format.ErrorResponse{
+		Error: format.ErrorDetail{Message: message, Type: errType},
+	})
+}
+
+// fileStore returns the file store, writing a 503 when it is unavailable.
+func fileStore(c *gin.Context) *files.Store {
+	store := files.Default()
+	if store == nil {
+		writeAPIError(c, http.StatusServiceUnavailable, "server_error", "File storage is not available.")
+	}
+	return store
+}
+
+// ownedFile loads the file named by the :id path parameter for the calling API key.
+// Internal files are reported as missing unless withInternal is set. On failure
+// it writes the error response and returns nil.
+func ownedFile(c *gin.Context, store *files.Store, withInternal bool) *files.File {
+	id := c.Param("id")
+	f, err := store.Get(c.Request.Context(), id)
+	if err == nil && (f.APIKey != c.GetString("apiKey") || (f.Internal() && !withInternal)) {
+		err = files.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, files.ErrNotFound) {
+			writeAPIError(c, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("No such File object: %s", id))
+		} else {
+			writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		}
+		return nil
+	}
+	return f
+}
+
+// UploadFile handles POST /v1/files with a multipart body carrying "file" and
+// "purpose", and optionally expires_after[anchor] and expires_after[seconds].
+func (h *OpenAIAPIHandler) UploadFile(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	purpose := c.PostForm("purpose")
+	if purpose == "" {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", "purpose is required")
+		return
+	}
+	if purpose == files.PurposeMessageBatch || purpose == files.PurposeImageGeneration {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("purpose %q is reserved", purpose))
+		return
+	}
+	var expiresAt *int64
+	if anchor, seconds := c.PostForm("expires_after[anchor]"), c.PostForm("expires_after[seconds]"); anchor != "" || seconds != "" {
+		n, errParse := strconv.ParseInt(seconds, 10, 64)
+		if anchor != "created_at" || errParse != nil || n < 3600 || n > 2592000 {
+			writeAPIError(c, http.StatusBadRequest, "invalid_request_error", "expires_after requires anchor \"created_at\" and seconds between 3600 and 2592000")
+			return
+		}
+		exp := time.Now().Unix() + n
+		expiresAt = &exp
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("file is required: %v", err))
+		return
+	}
+	src, err := header.Open()
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid file: %v", err))
+		return
+	}
+	defer src.Close()
+
+	f, err := store.Create(c.Request.Context(), files.File{
+		APIKey:    c.GetString("apiKey"),
+		Filename:  header.Filename,
+		Purpose:   purpose,
+		MimeType:  files.DetectMimeType(header.Header.Get("Content-Type"), header.Filename, nil),
+		ExpiresAt: expiresAt,
+	}, src)
+	if err != nil {
+		if errors.Is(err, files.ErrTooLarge) {
+			writeAPIError(c, http.StatusRequestEntityTooLarge, "invalid_request_error", err.Error())
+			return
+		}
+		writeAPIError(c, http.StatusInternalServerError, "server_error", fmt.Sprintf("Failed to store file: %v", err))
+		return
+	}
+	c.JSON(http.StatusOK, f)
+}
+
+// ListFiles handles GET /v1/files.
+// Supports the purpose, limit (1-10000, default 10000) and after query parameters.
+func (h *OpenAIAPIHandler) ListFiles(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	limit := 10000
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v >= 1 && v <= 10000 {
+		limit = v
+	}
+	list, hasMore, err := store.List(c.Request.Context(), files.ListOptions{
+		APIKey:  c.GetString("apiKey"),
+		Purpose: c.Query("purpose"),
+		After:   c.Query("after"),
+		Limit:   limit,
+	})
+	if err != nil {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	resp := gin.H{"object": "list", "data": list, "has_more": hasMore}
+	if len(list) > 0 {
+		resp["first_id"] = list[0].ID
+		resp["last_id"] = list[len(list)-1].ID
+	} else {
+		resp["data"] = []*files.File{}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RetrieveFile handles GET /v1/files/:id.
+func (h *OpenAIAPIHandler) RetrieveFile(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	if f := ownedFile(c, store, false); f != nil {
+		c.JSON(http.StatusOK, f)
+	}
+}
+
+// RetrieveFileContent handles GET /v1/files/:id/content.
+func (h *OpenAIAPIHandler) RetrieveFileContent(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	f := ownedFile(c, store, false)
+	if f == nil {
+		return
+	}
+	content, err := store.Open(c.Request.Context(), f.ID)
+	if err != nil {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	defer content.Close()
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Length", strconv.FormatInt(f.Bytes, 10))
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, content)
+}
+
+// DeleteFile handles DELETE /v1/files/:id. The input file of a message batch
+// can only be deleted once the batch is gone; deleting the batch removes it.
+func (h *OpenAIAPIHandler) DeleteFile(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	f := ownedFile(c, store, true)
+	if f == nil {
+		return
+	}
+	if r := batch.Default(); f.Internal() && r != nil {
+		batchID, err := r.Store().ByInputFile(c.Request.Context(), f.ID)
+		if err == nil {
+			writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("File %s belongs to message batch %s; delete the batch instead.", f.ID, batchID))
+			return
+		}
+		if !errors.Is(err, batch.ErrNotFound) {
+			writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+	}
+	if err := store.Delete(c.Request.Context(), f.ID); err != nil && !errors.Is(err, files.ErrNotFound) {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": f.ID, "object": "file", "deleted": true})
+}
@@ -51,7 +51,7 @@ func (h *OpenAIAPIHandler) HandlerType() string {
 // Models returns the OpenAI-compatible model metadata supported by this handler.
 func (h *OpenAIAPIHandler) Models() []map[string]any {
 	modelRegistry := registry.GetGlobalRegistry()
-	return modelRegistry.GetAvailableModels("openai")
+	return append(modelRegistry.GetAvailableModels("openai"), h.VirtualModels()...)
 }
 
 // OpenAIModels handles the /v1/models endpoint.
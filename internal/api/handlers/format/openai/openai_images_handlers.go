@@ -0,0 +1,367 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/files"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+// defaultImageModel serves image requests without a model and requests naming
+// an OpenAI image model that no provider offers.
+const defaultImageModel = "gemini-2.5-flash-image"
+
+// imageURLLifetime is how long images returned with response_format "url" stay
+// downloadable, matching the hour OpenAI keeps its image URLs.
+const imageURLLifetime = time.Hour
+
+// maxImagesPerRequest bounds n, as the OpenAI API does.
+const maxImagesPerRequest = 10
+
+// geminiAspectRatios are the aspect ratios Gemini image models accept.
+var geminiAspectRatios = []string{"1:1", "2:3", "3:2", "3:4", "4:3", "4:5", "5:4", "9:16", "16:9", "21:9"}
+
+// imageRequest holds the parameters shared by image generations and edits.
+type imageRequest struct {
+	Model          string
+	Prompt         string
+	N              int
+	ResponseFormat string
+	AspectRatio    string
+	ImageSize      string
+	Images         []string // data URIs of the images to edit
+}
+
+// ImageGenerations handles POST /v1/images/generations.
+// The prompt is sent as a chat completion with image output to an
+// image-capable model, and the generated images are returned as b64_json or
+// as URLs served by the proxy.
+func (h *OpenAIAPIHandler) ImageGenerations(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	root := gjson.ParseBytes(rawJSON)
+	req, errMsg := parseImageRequest(func(key string) string { return root.Get(key).String() })
+	if errMsg != "" {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", errMsg)
+		return
+	}
+	h.generateImages(c, req)
+}
+
+// ImageEdits handles POST /v1/images/edits.
+// The multipart body carries the prompt, one or more images ("image" or
+// "image[]") and an optional mask, which is passed to the model as an extra
+// image since Gemini has no native mask support.
+func (h *OpenAIAPIHandler) ImageEdits(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid multipart body: %v", err))
+		return
+	}
+	req, errMsg := parseImageRequest(c.PostForm)
+	if errMsg != "" {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", errMsg)
+		return
+	}
+	uploads := append(form.File["image"], form.File["image[]"]...)
+	if len(uploads) == 0 {
+		writeAPIError(c, http.StatusBadRequest, "invalid_request_error", "image is required")
+		return
+	}
+	if masks := form.File["mask"]; len(masks) > 0 {
+		uploads = append(uploads, masks[0])
+		req.Prompt += "\n\nThe last image is a mask: edit only the areas where it is transparent and keep the rest unchanged."
+	}
+	for _, header := range uploads {
+		src, errOpen := header.Open()
+		if errOpen != nil {
+			writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid image: %v", errOpen))
+			return
+		}
+		data, errRead := io.ReadAll(src)
+		_ = src.Close()
+		if errRead != nil {
+			writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid image: %v", errRead))
+			return
+		}
+		mimeType := files.DetectMimeType(header.Header.Get("Content-Type"), header.Filename, data)
+		if !strings.HasPrefix(mimeType, "image/") {
+			writeAPIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("%s is not an image", header.Filename))
+			return
+		}
+		req.Images = append(req.Images, "data:"+mimeType+";base64,"+base64.StdEncoding.EncodeToString(data))
+	}
+	h.generateImages(c, req)
+}
+
+// ImageContent handles GET /v1/images/content/:id, serving an image generated
+// with response_format "url". The route is unauthenticated like OpenAI's image
+// URLs; the random file id is the only credential, and only generated images
+// are served.
+func (h *OpenAIAPIHandler) ImageContent(c *gin.Context) {
+	store := fileStore(c)
+	if store == nil {
+		return
+	}
+	f, err := store.Get(c.Request.Context(), c.Param("id"))
+	if err != nil || f.Purpose != files.PurposeImageGeneration {
+		writeAPIError(c, http.StatusNotFound, "invalid_request_error", "Image not found")
+		return
+	}
+	content, err := store.Open(c.Request.Context(), f.ID)
+	if err != nil {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	defer content.Close()
+	c.Header("Content-Type", f.MimeType)
+	c.Header("Content-Length", strconv.FormatInt(f.Bytes, 10))
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, content)
+}
+
+// parseImageRequest reads the image parameters through get, which looks up a
+// JSON field or a form value. It returns a message describing the first
+// invalid parameter.
+func parseImageRequest(get func(string) string) (*imageRequest, string) {
+	req := &imageRequest{
+		Model:          resolveModel(get("model"), defaultImageModel, "dall-e", "gpt-image"),
+		Prompt:         get("prompt"),
+		N:              1,
+		ResponseFormat: get("response_format"),
+		AspectRatio:    get("aspect_ratio"),
+		ImageSize:      get("image_size"),
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		return nil, "prompt is required"
+	}
+	if v := get("n"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxImagesPerRequest {
+			return nil, fmt.Sprintf("n must be between 1 and %d", maxImagesPerRequest)
+		}
+		req.N = n
+	}
+	switch req.ResponseFormat {
+	case "":
+		req.ResponseFormat = "b64_json"
+	case "b64_json", "url":
+	default:
+		return nil, fmt.Sprintf("response_format must be \"b64_json\" or \"url\", got %q", req.ResponseFormat)
+	}
+	if size := get("size"); size != "" && size != "auto" {
+		aspectRatio, imageSize, ok := imageConfigForSize(size)
+		if !ok {
+			return nil, fmt.Sprintf("invalid size %q, expected WIDTHxHEIGHT or auto", size)
+		}
+		if req.AspectRatio == "" {
+			req.AspectRatio = aspectRatio
+		}
+		if req.ImageSize == "" {
+			req.ImageSize = imageSize
+		}
+	}
+	return req, ""
+}
+
+// resolveModel returns model, or fallback when model is empty or names an
+// OpenAI model (by one of openAIPrefixes) that no provider offers. Clients
+// hard-coding dall-e-3, whisper-1 or tts-1 thus work unchanged.
+func resolveModel(model, fallback string, openAIPrefixes ...string) string {
+	if model == "" {
+		return fallback
+	}
+	for _, prefix := range openAIPrefixes {
+		if strings.HasPrefix(model, prefix) && registry.GetGlobalRegistry().GetModelInfo(model) == nil {
+			return fallback
+		}
+	}
+	return model
+}
+
+// imageConfigForSize maps an OpenAI WIDTHxHEIGHT size onto the closest
+// Gemini aspect ratio, and onto an image size for resolutions above the 1K
+// that Gemini produces by default.
+func imageConfigForSize(size string) (aspectRatio, imageSize string, ok bool) {
+	w, h, found := strings.Cut(size, "x")
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if !found || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return "", "", false
+	}
+	target := math.Log(float64(width) / float64(height))
+	best := math.Inf(1)
+	for _, ar := range geminiAspectRatios {
+		num, den, _ := strings.Cut(ar, ":")
+		n, _ := strconv.Atoi(num)
+		d, _ := strconv.Atoi(den)
+		if diff := math.Abs(math.Log(float64(n)/float64(d)) - target); diff < best {
+			best, aspectRatio = diff, ar
+		}
+	}
+	switch longest := max(width, height); {
+	case longest >= 4096:
+		imageSize = "4K"
+	case longest >= 2048:
+		imageSize = "2K"
+	}
+	return aspectRatio, imageSize, true
+}
+
+// buildImageChatRequest builds the chat completion that asks req.Model for
+// image output.
+func buildImageChatRequest(req *imageRequest) ([]byte, error) {
+	content := []any{map[string]any{"type": "text", "text": req.Prompt}}
+	for _, uri := range req.Images {
+		content = append(content, map[string]any{"type": "image_url", "image_url": map[string]string{"url": uri}})
+	}
+	payload := map[string]any{
+		"model":      req.Model,
+		"messages":   []any{map[string]any{"role": "user", "content": content}},
+		"modalities": []string{"image", "text"},
+	}
+	imageConfig := map[string]string{}
+	if req.AspectRatio != "" {
+		imageConfig["aspect_ratio"] = req.AspectRatio
+	}
+	if req.ImageSize != "" {
+		imageConfig["image_size"] = req.ImageSize
+	}
+	if len(imageConfig) > 0 {
+		payload["image_config"] = imageConfig
+	}
+	return json.Marshal(payload)
+}
+
+// generateImages runs one chat completion per requested image and writes the
+// OpenAI images response.
+func (h *OpenAIAPIHandler) generateImages(c *gin.Context, req *imageRequest) {
+	payload, err := buildImageChatRequest(req)
+	if err != nil {
+		writeAPIError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+
+	results := make([][]byte, req.N)
+	errs := make([]*interfaces.ErrorMessage, req.N)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), req.Model, payload, "")
+		}(i)
+	}
+	wg.Wait()
+	for _, errMsg := range errs {
+		if errMsg != nil {
+			h.WriteErrorResponse(c, errMsg)
+			cliCancel(errMsg.Error)
+			return
+		}
+	}
+
+	var images []string
+	var inputTokens, outputTokens int64
+	for _, resp := range results {
+		for _, url := range gjson.GetBytes(resp, "choices.#.message.images.#.image_url.url|@flatten").Array() {
+			images = append(images, url.String())
+		}
+		inputTokens += gjson.GetBytes(resp, "usage.prompt_tokens").Int()
+		outputTokens += gjson.GetBytes(resp, "usage.completion_tokens").Int()
+	}
+	if len(images) == 0 {
+		msg := fmt.Sprintf("model %s returned no image", req.Model)
+		if text := gjson.GetBytes(results[0], "choices.0.message.content").String(); text != "" {
+			msg += ": " + text
+		}
+		writeAPIError(c, http.StatusBadGateway, "server_error", msg)
+		cliCancel(fmt.Errorf("%s", msg))
+		return
+	}
+
+	data := make([]gin.H, 0, len(images))
+	for i, uri := range images {
+		mimeType, b64, ok := splitImageDataURI(uri)
+		if !ok {
+			continue
+		}
+		if req.ResponseFormat == "b64_json" {
+			data = append(data, gin.H{"b64_json": b64})
+			continue
+		}
+		url, errStore := storeGeneratedImage(c, mimeType, b64, i)
+		if errStore != nil {
+			writeAPIError(c, http.StatusInternalServerError, "server_error", fmt.Sprintf("Failed to store image: %v", errStore))
+			cliCancel(errStore)
+			return
+		}
+		data = append(data, gin.H{"url": url})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"created": time.Now().Unix(),
+		"data":    data,
+		"usage": gin.H{
+			"input_tokens":  inputTokens,
+			"output_tokens": outputTokens,
+			"total_tokens":  inputTokens + outputTokens,
+		},
+	})
+	cliCancel()
+}
+
+// splitImageDataURI splits a base64 data URI into its media type and payload.
+func splitImageDataURI(uri string) (mimeType, b64 string, ok bool) {
+	header, b64, found := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !found || !strings.HasSuffix(header, ";base64") {
+		return "", "", false
+	}
+	return strings.TrimSuffix(header, ";base64"), b64, true
+}
+
+// storeGeneratedImage saves an image in the file store for imageURLLifetime
+// and returns the URL serving it.
+func storeGeneratedImage(c *gin.Context, mimeType, b64 string, index int) (string, error) {
+	store := files.Default()
+	if store == nil {
+		return "", fmt.Errorf("file storage is not available")
+	}
+	raw, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return "", err
+	}
+	ext := strings.TrimPrefix(mimeType, "image/")
+	expiresAt := time.Now().Add(imageURLLifetime).Unix()
+	f, err := store.CreateGenerated(c.Request.Context(), files.File{
+		APIKey:    c.GetString("apiKey"),
+		Filename:  fmt.Sprintf("image-%d.%s", index, ext),
+		Purpose:   files.PurposeImageGeneration,
+		MimeType:  mimeType,
+		ExpiresAt: &expiresAt,
+	}, bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/v1/images/content/%s", scheme, c.Request.Host, f.ID), nil
+}
@@ -0,0 +1,61 @@
+package openai
+
+import "testing"
+
+func TestImageConfigForSize(t *testing.T) {
+	tests := []struct {
+		size        string
+		aspectRatio string
+		imageSize   string
+		ok          bool
+	}{
+		{"1024x1024", "1:1", "", true},
+		{"1792x1024", "16:9", "", true},
+		{"1024x1792", "9:16", "", true},
+		{"1536x1024", "3:2", "", true},
+		{"1024x1536", "2:3", "", true},
+		{"2048x2048", "1:1", "2K", true},
+		{"5120x2160", "21:9", "4K", true},
+		{"large", "", "", false},
+		{"0x512", "", "", false},
+	}
+	for _, tt := range tests {
+		aspectRatio, imageSize, ok := imageConfigForSize(tt.size)
+		if aspectRatio != tt.aspectRatio || imageSize != tt.imageSize || ok != tt.ok {
+			t.Errorf("imageConfigForSize(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.size, aspectRatio, imageSize, ok, tt.aspectRatio, tt.imageSize, tt.ok)
+		}
+	}
+}
+
+func TestParseImageRequest(t *testing.T) {
+	form := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+
+	req, msg := parseImageRequest(form(map[string]string{"prompt": "a cat", "model": "dall-e-3", "size": "1792x1024"}))
+	if msg != "" {
+		t.Fatalf("unexpected error %q", msg)
+	}
+	if req.Model != defaultImageModel || req.N != 1 || req.ResponseFormat != "b64_json" || req.AspectRatio != "16:9" {
+		t.Errorf("request = %+v", req)
+	}
+
+	req, _ = parseImageRequest(form(map[string]string{"prompt": "a cat", "size": "1024x1024", "aspect_ratio": "4:3"}))
+	if req.AspectRatio != "4:3" {
+		t.Errorf("explicit aspect_ratio overridden by size: %q", req.AspectRatio)
+	}
+
+	for name, values := range map[string]map[string]string{
+		"missing prompt":  {"n": "1"},
+		"n too large":     {"prompt": "x", "n": "11"},
+		"bad format":      {"prompt": "x", "response_format": "png"},
+		"malformed size":  {"prompt": "x", "size": "big"},
+		"non-numeric n":   {"prompt": "x", "n": "two"},
+		"whitespace only": {"prompt": "  "},
+	} {
+		if _, msg := parseImageRequest(form(values)); msg == "" {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
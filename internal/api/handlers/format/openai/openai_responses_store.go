@@ -0,0 +1,198 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/responses"
+	"github.com/tidwall/gjson"
+)
+
+// expandHistory inlines the conversation referenced by previous_response_id.
+// On failure it writes the error response and returns ok=false.
+func (h *OpenAIResponsesAPIHandler) expandHistory(c *gin.Context, rawJSON []byte) (payload, history []byte, ok bool) {
+	store := responses.Default()
+	prevID := gjson.GetBytes(rawJSON, "previous_response_id").String()
+	if store == nil {
+		if prevID != "" {
+			c.JSON(http.StatusBadRequest, format.ErrorResponse{
+				Error: format.ErrorDetail{
+					Message: "previous_response_id is not supported: response storage is disabled",
+					Type:    "invalid_request_error",
+				},
+			})
+			return nil, nil, false
+		}
+		return rawJSON, nil, true
+	}
+
+	payload, history, err := responses.Expand(c.Request.Context(), store, rawJSON, c.GetString("apiKey"))
+	if err != nil {
+		if errors.Is(err, responses.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, format.ErrorResponse{
+				Error: format.ErrorDetail{
+					Message: fmt.Sprintf("Previous response with id '%s' not found.", prevID),
+					Type:    "invalid_request_error",
+					Code:    "previous_response_not_found",
+				},
+			})
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Failed to load previous response: %v", err),
+				Type:    "server_error",
+			},
+		})
+		return nil, nil, false
+	}
+	return payload, history, true
+}
+
+// storeResponse saves a completed response unless the request opted out with store:false.
+func (h *OpenAIResponsesAPIHandler) storeResponse(c *gin.Context, rawJSON, history, response []byte) {
+	store := responses.Default()
+	if store == nil || history == nil || !responses.ShouldStore(rawJSON) {
+		return
+	}
+	prevID := gjson.GetBytes(rawJSON, "previous_response_id").String()
+	rec := responses.NewRecord(bytes.Clone(response), history, c.GetString("apiKey"), prevID)
+	if rec == nil {
+		return
+	}
+	// The client may disconnect right after the final event; do not tie the write to its context.
+	if err := store.Put(context.WithoutCancel(c.Request.Context()), rec); err != nil {
+		log.Warnf("responses: failed to store response %s: %v", rec.ID, err)
+	}
+}
+
+// completedResponse returns the response object carried by a response.completed
+// stream event, or nil for any other chunk.
+func completedResponse(chunk []byte) []byte {
+	i := bytes.Index(chunk, []byte("data:"))
+	if i < 0 {
+		return nil
+	}
+	data := bytes.TrimSpace(chunk[i+len("data:"):])
+	if gjson.GetBytes(data, "type").String() != "response.completed" {
+		return nil
+	}
+	response := gjson.GetBytes(data, "response")
+	if !response.IsObject() {
+		return nil
+	}
+	return []byte(response.Raw)
+}
+
+// lookupResponse loads the response named by the :id path parameter for the calling API key.
+// On failure it writes the error response and returns nil.
+func (h *OpenAIResponsesAPIHandler) lookupResponse(c *gin.Context) *responses.Record {
+	id := c.Param("id")
+	store := responses.Default()
+	var (
+		rec *responses.Record
+		err = responses.ErrNotFound
+	)
+	if store != nil {
+		rec, err = store.Get(c.Request.Context(), id)
+	}
+	if err == nil && rec.APIKey != c.GetString("apiKey") {
+		err = responses.ErrNotFound
+	}
+	if err != nil {
+		status, errType := http.StatusInternalServerError, "server_error"
+		msg := err.Error()
+		if errors.Is(err, responses.ErrNotFound) {
+			status, errType = http.StatusNotFound, "invalid_request_error"
+			msg = fmt.Sprintf("Response with id '%s' not found.", id)
+		}
+		c.JSON(status, format.ErrorResponse{
+			Error: format.ErrorDetail{Message: msg, Type: errType},
+		})
+		return nil
+	}
+	return rec
+}
+
+// GetResponse handles GET /v1/responses/:id.
+func (h *OpenAIResponsesAPIHandler) GetResponse(c *gin.Context) {
+	rec := h.lookupResponse(c)
+	if rec == nil {
+		return
+	}
+	c.Data(http.StatusOK, "application/json", rec.Response)
+}
+
+// DeleteResponse handles DELETE /v1/responses/:id.
+func (h *OpenAIResponsesAPIHandler) DeleteResponse(c *gin.Context) {
+	rec := h.lookupResponse(c)
+	if rec == nil {
+		return
+	}
+	if err := responses.Default().Delete(c.Request.Context(), rec.ID); err != nil && !errors.Is(err, responses.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, format.ErrorResponse{
+			Error: format.ErrorDetail{Message: err.Error(), Type: "server_error"},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"id":      rec.ID,
+		"object":  "response.deleted",
+		"deleted": true,
+	})
+}
+
+// ListInputItems handles GET /v1/responses/:id/input_items.
+// Supports the limit (1-100, default 20), order (asc|desc, default desc) and after query parameters.
+func (h *OpenAIResponsesAPIHandler) ListInputItems(c *gin.Context) {
+	rec := h.lookupResponse(c)
+	if rec == nil {
+		return
+	}
+
+	items := gjson.ParseBytes(rec.Input).Array()
+	if c.DefaultQuery("order", "desc") == "desc" {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	if after := c.Query("after"); after != "" {
+		for i, item := range items {
+			if item.Get("id").String() == after {
+				items = items[i+1:]
+				break
+			}
+		}
+	}
+	limit := 20
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v >= 1 && v <= 100 {
+		limit = v
+	}
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"object":"list","data":[`)
+	for i, item := range items {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(item.Raw)
+	}
+	buf.WriteString(`],"has_more":`)
+	buf.WriteString(strconv.FormatBool(hasMore))
+	if len(items) > 0 {
+		fmt.Fprintf(&buf, `,"first_id":%q,"last_id":%q`, items[0].Get("id").String(), items[len(items)-1].Get("id").String())
+	}
+	buf.WriteByte('}')
+	c.Data(http.StatusOK, "application/json", buf.Bytes())
+}
@@ -0,0 +1,142 @@
+package format
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// withRoutingHints applies the routing hint headers of the request to ctx and
+// returns the timeout it asks for, or 0. A hint the API key may not send, or
+// one that cannot be parsed, is recorded for applyKeyScope to reject.
+func (h *BaseAPIHandler) withRoutingHints(ctx context.Context, c *gin.Context) (context.Context, time.Duration) {
+	providers := config.ParseHintList(c.GetHeader(config.ProviderHintHeader))
+	authID := strings.TrimSpace(c.GetHeader(config.AuthHintHeader))
+	noFallback := strings.TrimSpace(c.GetHeader(config.NoFallbackHintHeader))
+	timeout := strings.TrimSpace(c.GetHeader(config.TimeoutHintHeader))
+	if len(providers) == 0 && authID == "" && noFallback == "" && timeout == "" {
+		return ctx, 0
+	}
+	reject := func(status int, err error) (context.Context, time.Duration) {
+		if existing, _ := ctx.Value(ctxKeyScopeError).(*interfaces.ErrorMessage); existing != nil {
+			return ctx, 0
+		}
+		return context.WithValue(ctx, ctxKeyScopeError, &interfaces.ErrorMessage{StatusCode: status, Error: err}), 0
+	}
+
+	var hints config.RoutingHintsConfig
+	if h.Routing != nil {
+		hints = h.Routing.Hints
+	}
+	key := c.GetString("apiKey")
+	if vk, _ := ctx.Value(ctxKeyVirtualKey).(*config.VirtualKey); vk != nil {
+		key = vk.ID
+	}
+	for _, hint := range []struct {
+		name string
+		set  bool
+	}{
+		{config.HintProvider, len(providers) > 0},
+		{config.HintAuth, authID != ""},
+		{config.HintNoFallback, noFallback != ""},
+		{config.HintTimeout, timeout != ""},
+	} {
+		if hint.set && !hints.Allows(key, hint.name) {
+			return reject(http.StatusForbidden, fmt.Errorf("routing hint %s is not allowed for this API key", hint.name))
+		}
+	}
+
+	var single bool
+	if noFallback != "" {
+		var err error
+		if single, err = strconv.ParseBool(noFallback); err != nil {
+			return reject(http.StatusBadRequest, fmt.Errorf("invalid %s header %q", config.NoFallbackHintHeader, noFallback))
+		}
+	}
+	var deadline time.Duration
+	if timeout != "" {
+		d, err := parseHintTimeout(timeout)
+		if err != nil {
+			return reject(http.StatusBadRequest, fmt.Errorf("invalid %s header %q", config.TimeoutHintHeader, timeout))
+		}
+		deadline = hints.ClampTimeout(d)
+	}
+
+	if len(providers) > 0 || authID != "" {
+		var scope provider.AuthScope
+		if current := provider.AuthScopeFromContext(ctx); current != nil {
+			scope = *current
+		}
+		if authID != "" {
+			auth, ok := h.AuthManager.GetByID(authID)
+			if !ok {
+				return reject(http.StatusBadRequest, fmt.Errorf("unknown auth %s", authID))
+			}
+			scope.AuthID = authID
+			providers = narrowProviders(providers, []string{auth.Provider})
+			if len(providers) == 0 {
+				return reject(http.StatusBadRequest, fmt.Errorf("auth %s does not belong to the hinted provider", authID))
+			}
+		}
+		scope.Providers = narrowProviders(scope.Providers, providers)
+		if len(scope.Providers) == 0 {
+			return reject(http.StatusForbidden, fmt.Errorf("hinted provider is not allowed for this API key"))
+		}
+		ctx = provider.WithAuthScope(ctx, &scope)
+	}
+	if single {
+		ctx = context.WithValue(ctx, ctxKeyNoFallback, true)
+		ctx = provider.WithSingleAttempt(ctx)
+	}
+	return ctx, deadline
+}
+
+// narrowProviders intersects two provider lists, where an empty list allows
+// every provider.
+func narrowProviders(current, hinted []string) []string {
+	if len(current) == 0 {
+		return hinted
+	}
+	if len(hinted) == 0 {
+		return current
+	}
+	narrowed := make([]string, 0, len(hinted))
+	for _, p := range hinted {
+		if slices.Contains(current, p) {
+			narrowed = append(narrowed, p)
+		}
+	}
+	return narrowed
+}
+
+// parseHintTimeout accepts a Go duration ("90s", "2m") or whole seconds.
+func parseHintTimeout(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		secs, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return 0, err
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive")
+	}
+	return d, nil
+}
+
+// noFallback reports whether the request asked not to fall back to other
+// models.
+func noFallback(ctx context.Context) bool {
+	single, _ := ctx.Value(ctxKeyNoFallback).(bool)
+	return single
+}
@@ -0,0 +1,20 @@
+package management
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// GetAdmission reports the admission queues: requests waiting for a cooling-down
+// model, and how many were admitted, timed out or turned away.
+func (h *Handler) GetAdmission(c *gin.Context) {
+	enabled := false
+	if cfg := h.getConfig(); cfg != nil {
+		enabled = cfg.Routing.Admission.Enabled
+	}
+	queues := []provider.AdmissionQueueStats{}
+	if h.authManager != nil {
+		queues = h.authManager.AdmissionStats()
+	}
+	respondOK(c, gin.H{"enabled": enabled, "queues": queues})
+}
@@ -164,6 +164,30 @@ func (h *Handler) enrichWithQuotaState(entry gin.H, authID string, qm *provider.
 	if !state.LastExhaustedAt.IsZero() {
 		qs["last_exhausted_at"] = state.LastExhaustedAt
 	}
+	if real := state.RealQuota; real != nil {
+		rq := gin.H{
+			"remaining_fraction": real.RemainingFraction,
+			"fetched_at":         real.FetchedAt,
+		}
+		if !real.WindowResetAt.IsZero() {
+			rq["window_reset_at"] = real.WindowResetAt
+		}
+		if len(real.Windows) > 0 {
+			windows := make([]gin.H, 0, len(real.Windows))
+			for _, w := range real.Windows {
+				window := gin.H{"name": w.Name, "used_fraction": w.UsedFraction}
+				if w.Duration > 0 {
+					window["window_seconds"] = int64(w.Duration.Seconds())
+				}
+				if !w.ResetAt.IsZero() {
+					window["reset_at"] = w.ResetAt
+				}
+				windows = append(windows, window)
+			}
+			rq["windows"] = windows
+		}
+		qs["real_quota"] = rq
+	}
 
 	entry["quota_state"] = qs
 }
@@ -245,6 +269,10 @@ func (h *Handler) buildAuthFileEntry(auth *provider.Auth) gin.H {
 			entry["account"] = account
 		}
 	}
+	if provider.IsReserveAuth(auth.Metadata) {
+		entry["tier"] = provider.AuthTierReserve
+	}
+	entry["weight"] = provider.AuthWeight(auth.Metadata)
 	if !auth.CreatedAt.IsZero() {
 		entry["created_at"] = auth.CreatedAt
 	}
@@ -513,6 +541,73 @@ func (h *Handler) DeleteAuthFile(c *gin.Context) {
 	respondOK(c, gin.H{"status": "ok"})
 }
 
+// authFilePatch is the body of PatchAuthFile. Omitted fields are left as
+// they are.
+type authFilePatch struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Tier   *string `json:"tier"`
+	Weight *int    `json:"weight"`
+}
+
+// PatchAuthFile sets the routing tier and weight of an auth, identified by id
+// or file name. They are stored in the auth's metadata, and so in its file.
+func (h *Handler) PatchAuthFile(c *gin.Context) {
+	if h.authManager == nil {
+		respondError(c, http.StatusServiceUnavailable, ErrCodeInternalError, "core auth manager unavailable")
+		return
+	}
+	var body authFilePatch
+	if err := c.ShouldBindJSON(&body); err != nil || (body.ID == "" && body.Name == "") {
+		respondBadRequest(c, "invalid body")
+		return
+	}
+	var auth *provider.Auth
+	for _, candidate := range h.authManager.List() {
+		if (body.ID != "" && candidate.ID == body.ID) || (body.ID == "" && candidate.FileName == body.Name) {
+			auth = candidate
+			break
+		}
+	}
+	if auth == nil {
+		respondNotFound(c, "auth not found")
+		return
+	}
+	if auth.Metadata == nil || isRuntimeOnlyAuth(auth) {
+		respondBadRequest(c, "auth is not file-backed")
+		return
+	}
+	if body.Tier != nil {
+		switch tier := strings.ToLower(strings.TrimSpace(*body.Tier)); tier {
+		case provider.AuthTierReserve:
+			auth.Metadata["tier"] = tier
+		case "", "primary":
+			delete(auth.Metadata, "tier")
+		default:
+			respondBadRequest(c, "tier must be primary or reserve")
+			return
+		}
+	}
+	if body.Weight != nil {
+		if *body.Weight < 0 {
+			respondBadRequest(c, "weight must not be negative")
+			return
+		}
+		if *body.Weight <= 1 {
+			delete(auth.Metadata, "weight")
+		} else {
+			auth.Metadata["weight"] = *body.Weight
+		}
+	}
+	auth.UpdatedAt = time.Now()
+	updated, err := h.authManager.Update(c.Request.Context(), auth)
+	if err != nil {
+		respondInternalError(c, fmt.Sprintf("failed to update auth: %v", err))
+		return
+	}
+	respondOK(c, h.buildAuthFileEntry(updated))
+}
+
 func (h *Handler) authIDForPath(path string) string {
 	path = strings.TrimSpace(path)
 	if path == "" {
@@ -214,6 +214,88 @@ func (h *Handler) DeleteOAuthExcludedModels(c *gin.Context) {
 	h.persist(c)
 }
 
+// api-key-limits: map[string]APIKeyLimit
+func (h *Handler) GetAPIKeyLimits(c *gin.Context) {
+	cfg := h.getConfig()
+	respondOK(c, gin.H{"api-key-limits": config.NormalizeAPIKeyLimits(cfg.APIKeyLimits)})
+}
+
+func (h *Handler) PutAPIKeyLimits(c *gin.Context) {
+	data, err := c.GetRawData()
+	if err != nil {
+		respondBadRequest(c, "failed to read body")
+		return
+	}
+	var entries map[string]config.APIKeyLimit
+	if err = json.Unmarshal(data, &entries); err != nil {
+		var wrapper struct {
+			Items map[string]config.APIKeyLimit `json:"items"`
+		}
+		if err2 := json.Unmarshal(data, &wrapper); err2 != nil {
+			respondBadRequest(c, "invalid body")
+			return
+		}
+		entries = wrapper.Items
+	}
+	h.cfgMu.Lock()
+	h.cfg.APIKeyLimits = config.NormalizeAPIKeyLimits(entries)
+	h.cfgMu.Unlock()
+	h.persist(c)
+}
+
+// PatchAPIKeyLimits sets the limits of one key; a body without any limit
+// removes its entry.
+func (h *Handler) PatchAPIKeyLimits(c *gin.Context) {
+	var body struct {
+		Key   *string            `json:"key"`
+		Limit config.APIKeyLimit `json:"limit"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Key == nil {
+		respondBadRequest(c, "invalid body")
+		return
+	}
+	key := strings.TrimSpace(*body.Key)
+	if key == "" {
+		respondBadRequest(c, "invalid key")
+		return
+	}
+	if body.Limit.IsZero() {
+		h.deleteAPIKeyLimit(c, key)
+		return
+	}
+	h.cfgMu.Lock()
+	if h.cfg.APIKeyLimits == nil {
+		h.cfg.APIKeyLimits = make(map[string]config.APIKeyLimit)
+	}
+	h.cfg.APIKeyLimits[key] = body.Limit
+	h.cfgMu.Unlock()
+	h.persist(c)
+}
+
+func (h *Handler) DeleteAPIKeyLimits(c *gin.Context) {
+	key := strings.TrimSpace(c.Query("key"))
+	if key == "" {
+		respondBadRequest(c, "missing key")
+		return
+	}
+	h.deleteAPIKeyLimit(c, key)
+}
+
+func (h *Handler) deleteAPIKeyLimit(c *gin.Context, key string) {
+	h.cfgMu.Lock()
+	if _, ok := h.cfg.APIKeyLimits[key]; !ok {
+		h.cfgMu.Unlock()
+		respondNotFound(c, "key not found")
+		return
+	}
+	delete(h.cfg.APIKeyLimits, key)
+	if len(h.cfg.APIKeyLimits) == 0 {
+		h.cfg.APIKeyLimits = nil
+	}
+	h.cfgMu.Unlock()
+	h.persist(c)
+}
+
 // providers: []Provider
 func (h *Handler) GetProviders(c *gin.Context) {
 	cfg := h.getConfig()
@@ -1,6 +1,13 @@
 package management
 
-import "github.com/gin-gonic/gin"
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
 
 func (h *Handler) GetSwitchProject(c *gin.Context) {
 	respondOK(c, gin.H{"switch-project": h.cfg.QuotaExceeded.SwitchProject})
@@ -33,3 +40,140 @@ func (h *Handler) PutSwitchPreviewModel(c *gin.Context) {
 	}
 	respondOK(c, gin.H{"switch-preview-model": h.cfg.QuotaExceeded.SwitchPreviewModel})
 }
+
+// quota: QuotaConfig
+
+// GetQuota returns the quota overrides and the quota config in effect for
+// each provider that has a built-in or overridden one.
+func (h *Handler) GetQuota(c *gin.Context) {
+	cfg := h.getConfig()
+	effective := gin.H{}
+	if h.authManager != nil {
+		if qm := h.authManager.GetQuotaManager(); qm != nil {
+			providers := provider.QuotaProviders()
+			for name := range cfg.Quota.Providers {
+				providers = append(providers, name)
+			}
+			for _, name := range providers {
+				effective[name] = quotaConfigView(qm.ProviderConfig(name))
+			}
+		}
+	}
+	respondOK(c, gin.H{"quota": cfg.Quota, "effective": effective})
+}
+
+func quotaConfigView(cfg *provider.ProviderQuotaConfig) config.QuotaOverride {
+	sticky := cfg.StickyEnabled
+	view := config.QuotaOverride{
+		Window:        cfg.WindowDuration.String(),
+		Type:          cfg.QuotaType.String(),
+		Limit:         cfg.EstimatedLimit,
+		StaggerBucket: cfg.StaggerBucket.String(),
+		Sticky:        &sticky,
+	}
+	if cfg.RefuseOverage {
+		view.RefuseOverage = &cfg.RefuseOverage
+	}
+	return view
+}
+
+func (h *Handler) PutQuota(c *gin.Context) {
+	data, err := c.GetRawData()
+	if err != nil {
+		respondBadRequest(c, "failed to read body")
+		return
+	}
+	var quota config.QuotaConfig
+	if err = json.Unmarshal(data, &quota); err != nil {
+		respondBadRequest(c, "invalid body")
+		return
+	}
+	if quota, err = quota.Normalize(); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	h.cfgMu.Lock()
+	h.cfg.Quota = quota
+	h.cfgMu.Unlock()
+	h.persist(c)
+}
+
+// PatchQuota sets the override of one provider or auth; a body without any
+// field set removes it.
+func (h *Handler) PatchQuota(c *gin.Context) {
+	var body struct {
+		Provider *string              `json:"provider"`
+		Auth     *string              `json:"auth"`
+		Override config.QuotaOverride `json:"override"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || (body.Provider == nil) == (body.Auth == nil) {
+		respondBadRequest(c, "invalid body: set one of provider or auth")
+		return
+	}
+	patch := config.QuotaConfig{}
+	if body.Provider != nil {
+		patch.Providers = map[string]config.QuotaOverride{*body.Provider: body.Override}
+	} else {
+		patch.Auths = map[string]config.QuotaOverride{*body.Auth: body.Override}
+	}
+	normalized, err := patch.Normalize()
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if normalized.Providers == nil && normalized.Auths == nil {
+		h.deleteQuotaOverride(c, body.Provider, body.Auth)
+		return
+	}
+	h.cfgMu.Lock()
+	for name, override := range normalized.Providers {
+		if h.cfg.Quota.Providers == nil {
+			h.cfg.Quota.Providers = make(map[string]config.QuotaOverride)
+		}
+		h.cfg.Quota.Providers[name] = override
+	}
+	for id, override := range normalized.Auths {
+		if h.cfg.Quota.Auths == nil {
+			h.cfg.Quota.Auths = make(map[string]config.QuotaOverride)
+		}
+		h.cfg.Quota.Auths[id] = override
+	}
+	h.cfgMu.Unlock()
+	h.persist(c)
+}
+
+func (h *Handler) DeleteQuota(c *gin.Context) {
+	var providerName, authID *string
+	if v := c.Query("provider"); v != "" {
+		providerName = &v
+	}
+	if v := c.Query("auth"); v != "" {
+		authID = &v
+	}
+	if (providerName == nil) == (authID == nil) {
+		respondBadRequest(c, "missing provider or auth")
+		return
+	}
+	h.deleteQuotaOverride(c, providerName, authID)
+}
+
+func (h *Handler) deleteQuotaOverride(c *gin.Context, providerName, authID *string) {
+	target, key := &h.cfg.Quota.Auths, ""
+	if providerName != nil {
+		target, key = &h.cfg.Quota.Providers, strings.ToLower(strings.TrimSpace(*providerName))
+	} else {
+		key = strings.TrimSpace(*authID)
+	}
+	h.cfgMu.Lock()
+	if _, ok := (*target)[key]; !ok {
+		h.cfgMu.Unlock()
+		respondNotFound(c, "override not found")
+		return
+	}
+	delete(*target, key)
+	if len(*target) == 0 {
+		*target = nil
+	}
+	h.cfgMu.Unlock()
+	h.persist(c)
+}
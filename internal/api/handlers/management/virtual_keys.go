@@ -0,0 +1,162 @@
+package management
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+// virtualKeyRequest is the body of a virtual key creation.
+type virtualKeyRequest struct {
+	Name       string            `json:"name"`
+	Owner      string            `json:"owner"`
+	Models     []string          `json:"models"`
+	Providers  []string          `json:"providers"`
+	AuthLabels []string          `json:"auth-labels"`
+	AuthTags   []string          `json:"auth-tags"`
+	Metadata   map[string]string `json:"metadata"`
+	ExpiresAt  *time.Time        `json:"expires-at"`
+}
+
+// newVirtualKeySecret returns a fresh random key.
+func newVirtualKeySecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return config.VirtualKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+func newVirtualKeyID() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "vk-" + hex.EncodeToString(buf), nil
+}
+
+// setVirtualKeySecret stores the hash of secret in vk.
+func setVirtualKeySecret(vk *config.VirtualKey, secret string) {
+	vk.KeyHash = config.HashVirtualKey(secret)
+	vk.KeyHint = secret[len(secret)-4:]
+}
+
+// ListVirtualKeys lists the virtual keys, revoked ones included. Keys are
+// only shown when created or rotated.
+func (h *Handler) ListVirtualKeys(c *gin.Context) {
+	cfg := h.getConfig()
+	keys := cfg.VirtualKeys
+	if keys == nil {
+		keys = []config.VirtualKey{}
+	}
+	respondOK(c, gin.H{"virtual-keys": keys})
+}
+
+// CreateVirtualKey creates a virtual key and returns it. This is the only time
+// the key itself is returned.
+func (h *Handler) CreateVirtualKey(c *gin.Context) {
+	var body virtualKeyRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondBadRequest(c, "invalid body")
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		respondBadRequest(c, "expires-at is in the past")
+		return
+	}
+	id, err := newVirtualKeyID()
+	if err != nil {
+		respondInternalError(c, "failed to generate key")
+		return
+	}
+	secret, err := newVirtualKeySecret()
+	if err != nil {
+		respondInternalError(c, "failed to generate key")
+		return
+	}
+	providers := make([]string, 0, len(body.Providers))
+	for _, p := range body.Providers {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			providers = append(providers, p)
+		}
+	}
+	vk := config.VirtualKey{
+		ID:         id,
+		Name:       strings.TrimSpace(body.Name),
+		Owner:      strings.TrimSpace(body.Owner),
+		Models:     body.Models,
+		Providers:  providers,
+		AuthLabels: body.AuthLabels,
+		AuthTags:   config.NormalizeAuthTags(body.AuthTags),
+		Metadata:   body.Metadata,
+		CreatedAt:  time.Now().UTC(),
+		ExpiresAt:  body.ExpiresAt,
+	}
+	setVirtualKeySecret(&vk, secret)
+
+	h.cfgMu.Lock()
+	h.cfg.VirtualKeys = append(h.cfg.VirtualKeys, vk)
+	h.cfgMu.Unlock()
+	if !h.persistSilent() {
+		respondInternalError(c, "failed to save config")
+		return
+	}
+	respondOK(c, gin.H{"key": secret, "virtual-key": vk})
+}
+
+// RotateVirtualKey replaces the key of a virtual key, keeping its ID and
+// scope. The old key stops working at once.
+func (h *Handler) RotateVirtualKey(c *gin.Context) {
+	secret, err := newVirtualKeySecret()
+	if err != nil {
+		respondInternalError(c, "failed to generate key")
+		return
+	}
+	h.cfgMu.Lock()
+	vk := h.cfg.VirtualKey(c.Param("id"))
+	if vk == nil {
+		h.cfgMu.Unlock()
+		respondNotFound(c, "virtual key not found")
+		return
+	}
+	if vk.RevokedAt != nil {
+		h.cfgMu.Unlock()
+		respondBadRequest(c, "virtual key is revoked")
+		return
+	}
+	setVirtualKeySecret(vk, secret)
+	rotated := *vk
+	h.cfgMu.Unlock()
+	if !h.persistSilent() {
+		respondInternalError(c, "failed to save config")
+		return
+	}
+	respondOK(c, gin.H{"key": secret, "virtual-key": rotated})
+}
+
+// RevokeVirtualKey disables a virtual key for good. It stays listed so that
+// its usage can still be attributed.
+func (h *Handler) RevokeVirtualKey(c *gin.Context) {
+	h.cfgMu.Lock()
+	vk := h.cfg.VirtualKey(c.Param("id"))
+	if vk == nil {
+		h.cfgMu.Unlock()
+		respondNotFound(c, "virtual key not found")
+		return
+	}
+	if vk.RevokedAt == nil {
+		now := time.Now().UTC()
+		vk.RevokedAt = &now
+	}
+	revoked := *vk
+	h.cfgMu.Unlock()
+	if !h.persistSilent() {
+		respondInternalError(c, "failed to save config")
+		return
+	}
+	respondOK(c, gin.H{"virtual-key": revoked})
+}
@@ -22,6 +22,7 @@ func (s *Server) registerManagementRoutes() {
 	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware())
 	{
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
+		mgmt.GET("/admission", s.mgmt.GetAdmission)
 		mgmt.GET("/config", s.mgmt.GetConfig)
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
@@ -51,6 +52,11 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.PATCH("/api-keys", s.mgmt.PatchAPIKeys)
 		mgmt.DELETE("/api-keys", s.mgmt.DeleteAPIKeys)
 
+		mgmt.GET("/virtual-keys", s.mgmt.ListVirtualKeys)
+		mgmt.POST("/virtual-keys", s.mgmt.CreateVirtualKey)
+		mgmt.POST("/virtual-keys/:id/rotate", s.mgmt.RotateVirtualKey)
+		mgmt.DELETE("/virtual-keys/:id", s.mgmt.RevokeVirtualKey)
+
 		mgmt.GET("/providers", s.mgmt.GetProviders)
 		mgmt.PUT("/providers", s.mgmt.PutProviders)
 		mgmt.DELETE("/providers", s.mgmt.DeleteProvider)
@@ -74,9 +80,20 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.PATCH("/oauth-excluded-models", s.mgmt.PatchOAuthExcludedModels)
 		mgmt.DELETE("/oauth-excluded-models", s.mgmt.DeleteOAuthExcludedModels)
 
+		mgmt.GET("/quota", s.mgmt.GetQuota)
+		mgmt.PUT("/quota", s.mgmt.PutQuota)
+		mgmt.PATCH("/quota", s.mgmt.PatchQuota)
+		mgmt.DELETE("/quota", s.mgmt.DeleteQuota)
+
+		mgmt.GET("/api-key-limits", s.mgmt.GetAPIKeyLimits)
+		mgmt.PUT("/api-key-limits", s.mgmt.PutAPIKeyLimits)
+		mgmt.PATCH("/api-key-limits", s.mgmt.PatchAPIKeyLimits)
+		mgmt.DELETE("/api-key-limits", s.mgmt.DeleteAPIKeyLimits)
+
 		mgmt.GET("/auth-files", s.mgmt.ListAuthFiles)
 		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
+		mgmt.PATCH("/auth-files", s.mgmt.PatchAuthFile)
 		mgmt.DELETE("/auth-files", s.mgmt.DeleteAuthFile)
 		mgmt.POST("/vertex/import", s.mgmt.ImportVertexCredential)
 
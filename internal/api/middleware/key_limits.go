@@ -0,0 +1,209 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the per-API-key limit middleware that enforces the
+// api-key-limits configuration and reports it in x-ratelimit-* headers.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// keyLimitWindow is the window of requests-per-minute limits.
+const keyLimitWindow = time.Minute
+
+// keyLimitState is the request window and in-flight count of one API key.
+type keyLimitState struct {
+	windowStart time.Time
+	requests    int
+	inFlight    int
+}
+
+// KeyLimiter enforces per-API-key request rates, token budgets and
+// concurrency. Limits are looked up through getLimit on every request so that
+// config reloads apply at once; token usage comes from tokens. The HTTP
+// middleware and the batch runner share one limiter, so batch requests count
+// against the same limits as the key's own requests.
+type KeyLimiter struct {
+	getLimit func(apiKey string) (config.APIKeyLimit, bool)
+	tokens   *usage.KeyTokens
+
+	mu     sync.Mutex
+	states map[string]*keyLimitState
+}
+
+// rateLimitStatus is the state of one limit reported in x-ratelimit-* headers.
+type rateLimitStatus struct {
+	kind      string
+	limit     int64
+	remaining int64
+	reset     time.Duration
+}
+
+// keyAdmission is the outcome of admitting one request. A request is rejected
+// when message is set; an admitted request calls release, if set, when it ends.
+type keyAdmission struct {
+	statuses   []rateLimitStatus
+	message    string
+	retryAfter time.Duration
+	release    func()
+}
+
+// NewKeyLimiter returns a limiter for the limits getLimit reports.
+func NewKeyLimiter(getLimit func(apiKey string) (config.APIKeyLimit, bool), tokens *usage.KeyTokens) *KeyLimiter {
+	return &KeyLimiter{getLimit: getLimit, tokens: tokens, states: make(map[string]*keyLimitState)}
+}
+
+// KeyLimitMiddleware creates a Gin middleware that enforces per-API-key
+// limits with a limiter of its own. See KeyLimiter.Middleware.
+func KeyLimitMiddleware(getLimit func(apiKey string) (config.APIKeyLimit, bool), tokens *usage.KeyTokens) gin.HandlerFunc {
+	return NewKeyLimiter(getLimit, tokens).Middleware()
+}
+
+// Middleware creates a Gin middleware that enforces the limits of l. It must
+// run after authentication, which stores the client key as "apiKey".
+//
+// Requests over a limit are rejected with 429 and Retry-After. Admitted and
+// rejected requests alike carry the OpenAI-style x-ratelimit-limit-*,
+// x-ratelimit-remaining-* and x-ratelimit-reset-* headers for the requests
+// and tokens limits that apply.
+func (l *KeyLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admission := l.admit(c.Request.Context(), c.GetString("apiKey"), time.Now())
+		for _, st := range admission.statuses {
+			setRateLimitHeaders(c, st.kind, st.limit, st.remaining, st.reset)
+		}
+		if admission.message != "" {
+			rejectKeyLimit(c, admission.retryAfter, admission.message)
+			return
+		}
+		if admission.release != nil {
+			defer admission.release()
+		}
+		c.Next()
+	}
+}
+
+// Admit admits one request of apiKey that does not pass through the
+// middleware, such as a batch request. When the key is over a limit it
+// returns ok false and how long to wait before trying again; otherwise the
+// caller must call release once the request ends.
+func (l *KeyLimiter) Admit(ctx context.Context, apiKey string) (release func(), retryAfter time.Duration, ok bool) {
+	admission := l.admit(ctx, apiKey, time.Now())
+	if admission.message != "" {
+		return nil, admission.retryAfter, false
+	}
+	if admission.release == nil {
+		return func() {}, 0, true
+	}
+	return admission.release, 0, true
+}
+
+func (l *KeyLimiter) admit(ctx context.Context, apiKey string, now time.Time) keyAdmission {
+	var admission keyAdmission
+	if apiKey == "" {
+		return admission
+	}
+	limit, ok := l.getLimit(apiKey)
+	if !ok || limit.IsZero() {
+		return admission
+	}
+
+	if limit.TokensPerDay > 0 || limit.TokensPerMonth > 0 {
+		budget, remaining, reset := tokenBudget(limit, l.tokens.Totals(ctx, apiKey), now)
+		admission.statuses = append(admission.statuses, rateLimitStatus{"tokens", budget, remaining, reset})
+		if remaining <= 0 {
+			admission.message, admission.retryAfter = "token budget exhausted for this API key", reset
+			return admission
+		}
+	}
+
+	l.mu.Lock()
+	state := l.states[apiKey]
+	if state == nil {
+		state = &keyLimitState{}
+		l.states[apiKey] = state
+	}
+	if now.Sub(state.windowStart) >= keyLimitWindow {
+		state.windowStart, state.requests = now, 0
+	}
+	if limit.RequestsPerMinute > 0 {
+		reset := state.windowStart.Add(keyLimitWindow).Sub(now)
+		remaining := int64(limit.RequestsPerMinute - state.requests)
+		if remaining > 0 {
+			remaining--
+		}
+		admission.statuses = append(admission.statuses, rateLimitStatus{"requests", int64(limit.RequestsPerMinute), remaining, reset})
+		if state.requests >= limit.RequestsPerMinute {
+			l.mu.Unlock()
+			admission.message, admission.retryAfter = "request rate limit exceeded for this API key", reset
+			return admission
+		}
+	}
+	if limit.MaxConcurrent > 0 && state.inFlight >= limit.MaxConcurrent {
+		l.mu.Unlock()
+		admission.message = fmt.Sprintf("too many concurrent requests for this API key (limit %d)", limit.MaxConcurrent)
+		admission.retryAfter = time.Second
+		return admission
+	}
+	state.requests++
+	state.inFlight++
+	l.mu.Unlock()
+
+	admission.release = func() {
+		l.mu.Lock()
+		state.inFlight--
+		if state.inFlight == 0 && time.Since(state.windowStart) >= keyLimitWindow {
+			delete(l.states, apiKey)
+		}
+		l.mu.Unlock()
+	}
+	return admission
+}
+
+// tokenBudget returns the tighter of the daily and monthly token budgets of
+// limit: its size, the tokens left and the time until it resets.
+func tokenBudget(limit config.APIKeyLimit, used usage.TokenTotals, now time.Time) (budget, remaining int64, reset time.Duration) {
+	utc := now.UTC()
+	found := false
+	if limit.TokensPerDay > 0 {
+		budget, remaining = limit.TokensPerDay, limit.TokensPerDay-used.Day
+		reset = time.Date(utc.Year(), utc.Month(), utc.Day()+1, 0, 0, 0, 0, time.UTC).Sub(utc)
+		found = true
+	}
+	if limit.TokensPerMonth > 0 {
+		monthRemaining := limit.TokensPerMonth - used.Month
+		if !found || monthRemaining < remaining {
+			budget, remaining = limit.TokensPerMonth, monthRemaining
+			reset = time.Date(utc.Year(), utc.Month()+1, 1, 0, 0, 0, 0, time.UTC).Sub(utc)
+		}
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	return budget, remaining, reset
+}
+
+func setRateLimitHeaders(c *gin.Context, kind string, limit, remaining int64, reset time.Duration) {
+	c.Header("x-ratelimit-limit-"+kind, strconv.FormatInt(limit, 10))
+	c.Header("x-ratelimit-remaining-"+kind, strconv.FormatInt(remaining, 10))
+	c.Header("x-ratelimit-reset-"+kind, reset.Round(time.Second).String())
+}
+
+func rejectKeyLimit(c *gin.Context, retryAfter time.Duration, message string) {
+	seconds := int64((retryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error": gin.H{"message": message, "type": "rate_limit_error"},
+	})
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// newKeyLimitEngine limits the key "limited". When release is set, handlers
+// report on entered and block until release is closed.
+func newKeyLimitEngine(limit config.APIKeyLimit, entered, release chan struct{}) *gin.Engine {
+	return newKeyLimitEngineWithTokens(limit, usage.NewKeyTokens(), entered, release)
+}
+
+func newKeyLimitEngineWithTokens(limit config.APIKeyLimit, tokens *usage.KeyTokens, entered, release chan struct{}) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("apiKey", c.GetHeader("Authorization"))
+		c.Next()
+	})
+	engine.Use(KeyLimitMiddleware(func(apiKey string) (config.APIKeyLimit, bool) {
+		return limit, apiKey == "limited"
+	}, tokens))
+	engine.GET("/", func(c *gin.Context) {
+		if release != nil {
+			entered <- struct{}{}
+			<-release
+		}
+		c.Status(http.StatusOK)
+	})
+	return engine
+}
+
+func serveKeyLimit(engine *gin.Engine, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", apiKey)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestKeyLimitMiddlewareRequestsPerMinute(t *testing.T) {
+	engine := newKeyLimitEngine(config.APIKeyLimit{RequestsPerMinute: 2}, nil, nil)
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		rec := serveKeyLimit(engine, "limited")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i, rec.Code)
+		}
+		if got := rec.Header().Get("x-ratelimit-remaining-requests"); got != wantRemaining {
+			t.Errorf("request %d: remaining %q, want %q", i, got, wantRemaining)
+		}
+	}
+	rec := serveKeyLimit(engine, "limited")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request: status %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" || rec.Header().Get("x-ratelimit-limit-requests") != "2" {
+		t.Errorf("429 headers = %v", rec.Header())
+	}
+	if rec := serveKeyLimit(engine, "other"); rec.Code != http.StatusOK {
+		t.Errorf("unlimited key: status %d, want 200", rec.Code)
+	}
+}
+
+func TestKeyLimitMiddlewareMaxConcurrent(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	engine := newKeyLimitEngine(config.APIKeyLimit{MaxConcurrent: 1}, entered, release)
+
+	done := make(chan int)
+	go func() { done <- serveKeyLimit(engine, "limited").Code }()
+	<-entered
+	if rec := serveKeyLimit(engine, "limited"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("concurrent request: status %d, want 429", rec.Code)
+	}
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Fatalf("first request: status %d, want 200", code)
+	}
+	go func() { <-entered }()
+	if rec := serveKeyLimit(engine, "limited"); rec.Code != http.StatusOK {
+		t.Errorf("request after release: status %d, want 200", rec.Code)
+	}
+}
+
+func TestKeyLimitMiddlewareTokenBudget(t *testing.T) {
+	tokens := usage.NewKeyTokens()
+	engine := newKeyLimitEngineWithTokens(config.APIKeyLimit{TokensPerDay: 100, TokensPerMonth: 1000}, tokens, nil, nil)
+
+	rec := serveKeyLimit(engine, "limited")
+	if rec.Code != http.StatusOK || rec.Header().Get("x-ratelimit-remaining-tokens") != "100" {
+		t.Fatalf("first request: status %d, headers %v", rec.Code, rec.Header())
+	}
+	tokens.HandleUsage(context.Background(), usage.Record{APIKey: "limited", Usage: &ir.Usage{TotalTokens: 100}})
+	rec = serveKeyLimit(engine, "limited")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over budget: status %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("x-ratelimit-limit-tokens"); got != "100" {
+		t.Errorf("limit-tokens = %q, want the daily budget", got)
+	}
+}
+
+func TestKeyLimiterAdmitSharesLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewKeyLimiter(func(apiKey string) (config.APIKeyLimit, bool) {
+		return config.APIKeyLimit{MaxConcurrent: 1}, apiKey == "limited"
+	}, usage.NewKeyTokens())
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("apiKey", c.GetHeader("Authorization"))
+		c.Next()
+	})
+	engine.Use(limiter.Middleware())
+	engine.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	release, _, ok := limiter.Admit(context.Background(), "limited")
+	if !ok {
+		t.Fatal("Admit rejected the first request")
+	}
+	if _, retryAfter, ok := limiter.Admit(context.Background(), "limited"); ok || retryAfter <= 0 {
+		t.Fatalf("second Admit = %v, retry after %v; want rejected", ok, retryAfter)
+	}
+	if rec := serveKeyLimit(engine, "limited"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("HTTP request during admitted request: status %d, want 429", rec.Code)
+	}
+	release()
+	if rec := serveKeyLimit(engine, "limited"); rec.Code != http.StatusOK {
+		t.Errorf("HTTP request after release: status %d, want 200", rec.Code)
+	}
+}
@@ -16,8 +16,10 @@ import (
 	"github.com/nghyane/llm-mux/internal/api/handlers/format/ollama"
 	"github.com/nghyane/llm-mux/internal/api/handlers/format/openai"
 	"github.com/nghyane/llm-mux/internal/api/middleware"
+	"github.com/nghyane/llm-mux/internal/config"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/oauth"
+	"github.com/nghyane/llm-mux/internal/usage"
 )
 
 // setupRoutes configures the API routes for the server.
@@ -29,29 +31,75 @@ func (s *Server) setupRoutes() {
 	claudeCodeHandlers := claude.NewClaudeCodeAPIHandler(s.handlers)
 	openaiResponsesHandlers := openai.NewOpenAIResponsesAPIHandler(s.handlers)
 	ollamaHandlers := ollama.NewOllamaAPIHandler(s.handlers)
+	s.keyLimiter = middleware.NewKeyLimiter(s.apiKeyLimit, usage.DefaultKeyTokens())
+	keyLimits := s.keyLimiter.Middleware()
 
 	// OpenAI compatible API routes
 	v1 := s.engine.Group("/v1")
 	v1.Use(middleware.RequestSizeLimitMiddleware(s.cfg.MaxRequestSize))
 	v1.Use(s.conditionalAuthMiddleware())
+	v1.Use(keyLimits)
 	{
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
 		v1.POST("/completions", openaiHandlers.Completions)
+		v1.POST("/embeddings", openaiHandlers.Embeddings)
 		v1.POST("/messages", claudeCodeHandlers.ClaudeMessages)
 		v1.POST("/messages/count_tokens", claudeCodeHandlers.ClaudeCountTokens)
+		v1.POST("/messages/batches", claudeCodeHandlers.CreateMessageBatch)
+		v1.GET("/messages/batches", claudeCodeHandlers.ListMessageBatches)
+		v1.GET("/messages/batches/:id", claudeCodeHandlers.RetrieveMessageBatch)
+		v1.DELETE("/messages/batches/:id", claudeCodeHandlers.DeleteMessageBatch)
+		v1.POST("/messages/batches/:id/cancel", claudeCodeHandlers.CancelMessageBatch)
+		v1.GET("/messages/batches/:id/results", claudeCodeHandlers.MessageBatchResults)
 		v1.POST("/responses", openaiResponsesHandlers.Responses)
+		v1.GET("/responses/:id", openaiResponsesHandlers.GetResponse)
+		v1.DELETE("/responses/:id", openaiResponsesHandlers.DeleteResponse)
+		v1.GET("/responses/:id/input_items", openaiResponsesHandlers.ListInputItems)
+		v1.POST("/batches", openaiHandlers.CreateBatch)
+		v1.GET("/batches", openaiHandlers.ListBatches)
+		v1.GET("/batches/:id", openaiHandlers.RetrieveBatch)
+		v1.POST("/batches/:id/cancel", openaiHandlers.CancelBatch)
+		v1.POST("/images/generations", openaiHandlers.ImageGenerations)
+		v1.POST("/images/edits", openaiHandlers.ImageEdits)
+		v1.POST("/audio/transcriptions", openaiHandlers.AudioTranscriptions)
+		v1.POST("/audio/speech", openaiHandlers.AudioSpeech)
+	}
+
+	// Image URLs returned by /v1/images are fetched without credentials, like
+	// OpenAI's, so this route sits outside the authenticated group.
+	s.engine.GET("/v1/images/content/:id", openaiHandlers.ImageContent)
+
+	// File uploads get their own group so that files.max-size-mb, not
+	// max-request-size, bounds the body. OpenAI and Anthropic share the paths;
+	// requests carrying anthropic-version get the Anthropic format.
+	v1Files := s.engine.Group("/v1/files")
+	v1Files.Use(middleware.RequestSizeLimitMiddleware(s.cfg.Files.MaxSizeBytes()))
+	v1Files.Use(s.conditionalAuthMiddleware())
+	v1Files.Use(keyLimits)
+	{
+		v1Files.POST("", unifiedFilesHandler(openaiHandlers.UploadFile, claudeCodeHandlers.UploadFile))
+		v1Files.GET("", unifiedFilesHandler(openaiHandlers.ListFiles, claudeCodeHandlers.ListFiles))
+		v1Files.GET("/:id", unifiedFilesHandler(openaiHandlers.RetrieveFile, claudeCodeHandlers.RetrieveFile))
+		v1Files.GET("/:id/content", unifiedFilesHandler(openaiHandlers.RetrieveFileContent, claudeCodeHandlers.RetrieveFileContent))
+		v1Files.DELETE("/:id", unifiedFilesHandler(openaiHandlers.DeleteFile, claudeCodeHandlers.DeleteFile))
 	}
 
 	// Gemini compatible API routes
 	v1beta := s.engine.Group("/v1beta")
 	v1beta.Use(middleware.RequestSizeLimitMiddleware(s.cfg.MaxRequestSize))
 	v1beta.Use(s.conditionalAuthMiddleware())
+	v1beta.Use(keyLimits)
 	{
 		v1beta.GET("/models", geminiHandlers.GeminiModels)
 		v1beta.POST("/models/:action", geminiHandlers.GeminiHandler)
 		v1beta.GET("/models/:action", geminiHandlers.GeminiGetHandler)
 	}
+	// Gemini Live sessions on the path the Google SDKs connect to.
+	for _, version := range []string{"v1alpha", "v1beta"} {
+		s.engine.GET("/ws/google.ai.generativelanguage."+version+".GenerativeService.BidiGenerateContent",
+			s.conditionalAuthMiddleware(), keyLimits, geminiHandlers.GeminiLive)
+	}
 
 	// Root endpoint
 	s.engine.GET("/", func(c *gin.Context) {
@@ -66,29 +114,26 @@ func (s *Server) setupRoutes() {
 	})
 	s.engine.POST("/v1internal:method", geminiCLIHandlers.CLIHandler)
 
-	// Ollama compatible API routes (no authentication required, like in the example)
-	// Handle /api/version without auth (before auth check)
+	// Ollama compatible API routes. Ollama clients rarely send a key, so these
+	// are only authenticated when ollama-auth is set; /api/version never is.
 	s.engine.GET("/api/version", ollamaHandlers.Version)
 	s.engine.GET("/ollama/api/version", ollamaHandlers.Version)
 
-	// Handle other Ollama endpoints (with optional auth - can work without API key)
-	apiGroup := s.engine.Group("/api")
-	apiGroup.Use(middleware.RequestSizeLimitMiddleware(s.cfg.MaxRequestSize))
-	{
-		apiGroup.GET("/tags", ollamaHandlers.Tags)
-		apiGroup.POST("/chat", ollamaHandlers.Chat)
-		apiGroup.POST("/generate", ollamaHandlers.Generate)
-		apiGroup.POST("/show", ollamaHandlers.Show)
-	}
-
-	// Also support /ollama/api/* paths
-	ollamaGroup := s.engine.Group("/ollama/api")
-	ollamaGroup.Use(middleware.RequestSizeLimitMiddleware(s.cfg.MaxRequestSize))
-	{
-		ollamaGroup.GET("/tags", ollamaHandlers.Tags)
-		ollamaGroup.POST("/chat", ollamaHandlers.Chat)
-		ollamaGroup.POST("/generate", ollamaHandlers.Generate)
-		ollamaGroup.POST("/show", ollamaHandlers.Show)
+	for _, prefix := range []string{"/api", "/ollama/api"} {
+		ollamaGroup := s.engine.Group(prefix)
+		ollamaGroup.Use(middleware.RequestSizeLimitMiddleware(s.cfg.MaxRequestSize))
+		ollamaGroup.Use(s.ollamaAuthMiddleware())
+		ollamaGroup.Use(keyLimits)
+		{
+			ollamaGroup.GET("/tags", ollamaHandlers.Tags)
+			ollamaGroup.GET("/ps", ollamaHandlers.Ps)
+			ollamaGroup.POST("/chat", ollamaHandlers.Chat)
+			ollamaGroup.POST("/generate", ollamaHandlers.Generate)
+			ollamaGroup.POST("/embed", ollamaHandlers.Embed)
+			ollamaGroup.POST("/embeddings", ollamaHandlers.Embeddings)
+			ollamaGroup.POST("/show", ollamaHandlers.Show)
+			ollamaGroup.POST("/pull", ollamaHandlers.Pull)
+		}
 	}
 
 	// OAuth callback endpoints (reuse main server port)
@@ -145,6 +190,18 @@ func (s *Server) unifiedModelsHandler(openaiHandler *openai.OpenAIAPIHandler, cl
 	}
 }
 
+// unifiedFilesHandler serves a /v1/files route with the Anthropic handler when
+// the request carries an anthropic-version header and with the OpenAI one otherwise.
+func unifiedFilesHandler(openaiHandler, claudeHandler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("anthropic-version") != "" {
+			claudeHandler(c)
+		} else {
+			openaiHandler(c)
+		}
+	}
+}
+
 // AttachWebsocketRoute registers a websocket upgrade handler on the primary Gin engine.
 // The handler is served as-is without additional middleware beyond the standard stack already configured.
 func (s *Server) AttachWebsocketRoute(path string, handler http.Handler) {
@@ -195,6 +252,26 @@ func (s *Server) conditionalAuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// apiKeyLimit returns the api-key-limits entry for apiKey from the current
+// config.
+func (s *Server) apiKeyLimit(apiKey string) (config.APIKeyLimit, bool) {
+	return s.cfg.APIKeyLimitFor(apiKey)
+}
+
+// ollamaAuthMiddleware applies conditionalAuthMiddleware to the Ollama routes
+// when ollama-auth is enabled. The flag is read per request so that config
+// reloads take effect.
+func (s *Server) ollamaAuthMiddleware() gin.HandlerFunc {
+	auth := s.conditionalAuthMiddleware()
+	return func(c *gin.Context) {
+		if s.cfg == nil || !s.cfg.OllamaAuth {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
 // AuthMiddleware returns a Gin middleware handler that authenticates requests
 // using the configured authentication providers. When no providers are available,
 // it allows all requests (legacy behaviour).
@@ -19,14 +19,20 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/nghyane/llm-mux/internal/access"
 	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format/claude"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format/openai"
 	managementHandlers "github.com/nghyane/llm-mux/internal/api/handlers/management"
 	"github.com/nghyane/llm-mux/internal/api/middleware"
 	"github.com/nghyane/llm-mux/internal/api/modules"
 	ampmodule "github.com/nghyane/llm-mux/internal/api/modules/amp"
+	"github.com/nghyane/llm-mux/internal/batch"
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/files"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/responses"
+	"github.com/nghyane/llm-mux/internal/sqlstore"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
 	"gopkg.in/yaml.v3"
@@ -125,6 +131,9 @@ type Server struct {
 	mgmt      *managementHandlers.Handler
 	ampModule *ampmodule.AmpModule
 
+	// keyLimiter enforces api-key-limits for HTTP and batch requests alike.
+	keyLimiter *middleware.KeyLimiter
+
 	managementRoutesRegistered atomic.Bool
 	managementRoutesEnabled    atomic.Bool
 
@@ -230,6 +239,22 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 	// Setup routes
 	s.setupRoutes()
 
+	// Batch requests run through the same handlers as their HTTP endpoints.
+	if runner := batch.Default(); runner != nil {
+		openaiBatch := openai.NewOpenAIAPIHandler(s.handlers).ExecuteBatchRequest
+		runner.SetScope(func(ctx context.Context, b *batch.Batch) context.Context {
+			return s.handlers.WithClientScope(ctx, b.APIKey, b.AccessProvider, b.AuthTags)
+		})
+		runner.SetAdmit(s.keyLimiter.Admit)
+		runner.Start(batch.Executors{
+			"/v1/chat/completions": openaiBatch,
+			"/v1/completions":      openaiBatch,
+			"/v1/embeddings":       openaiBatch,
+			"/v1/responses":        openaiBatch,
+			"/v1/messages":         claude.NewClaudeCodeAPIHandler(s.handlers).ExecuteBatchRequest,
+		})
+	}
+
 	// Register Amp module using V2 interface with Context
 	s.ampModule = ampmodule.New(
 		ampmodule.WithAccessManager(accessManager),
@@ -327,6 +352,10 @@ func (s *Server) Stop(ctx context.Context) error {
 	if err := usage.Stop(); err != nil {
 		log.Warnf("Failed to stop usage persistence: %v", err)
 	}
+	responses.Stop()
+	batch.Stop()
+	files.Stop()
+	sqlstore.CloseAll()
 
 	log.Debug("API server stopped")
 	return nil
@@ -455,6 +484,7 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	s.handlers.OpenAICompatProviders = providerNames
 
 	s.handlers.UpdateClients(&cfg.SDKConfig)
+	s.handlers.UpdateRouting(&cfg.Routing)
 
 	if s.mgmt != nil {
 		s.mgmt.SetConfig(cfg)
@@ -144,6 +144,45 @@ func (s *FileTokenStore) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// quotaStateFile holds the saved quota state in the auth directory. It has no
+// .json suffix so that it is neither listed nor watched as an auth file.
+const quotaStateFile = ".llm-mux-quota-state"
+
+// LoadQuotaState implements provider.QuotaStateStore.
+func (s *FileTokenStore) LoadQuotaState(ctx context.Context) ([]byte, error) {
+	dir := s.baseDirSnapshot()
+	if dir == "" {
+		return nil, fmt.Errorf("auth filestore: directory not configured")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, quotaStateFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("auth filestore: read quota state failed: %w", err)
+	}
+	return data, nil
+}
+
+// SaveQuotaState implements provider.QuotaStateStore.
+func (s *FileTokenStore) SaveQuotaState(ctx context.Context, data []byte) error {
+	dir := s.baseDirSnapshot()
+	if dir == "" {
+		return fmt.Errorf("auth filestore: directory not configured")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := filepath.Join(dir, quotaStateFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("auth filestore: write quota state failed: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("auth filestore: rename quota state failed: %w", err)
+	}
+	return nil
+}
+
 func (s *FileTokenStore) resolveDeletePath(id string) (string, error) {
 	if strings.ContainsRune(id, os.PathSeparator) || filepath.IsAbs(id) {
 		return id, nil
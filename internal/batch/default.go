@@ -0,0 +1,56 @@
+package batch
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/files"
+	"github.com/nghyane/llm-mux/internal/sqlstore"
+)
+
+const defaultConcurrency = 4
+
+var (
+	defaultMu     sync.RWMutex
+	defaultRunner *Runner
+)
+
+// Default returns the process-wide runner, or nil when it is not initialized.
+func Default() *Runner {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultRunner
+}
+
+// Initialize creates the process-wide runner from cfg. The file store must be
+// initialized first. The runner does not execute anything until Start.
+func Initialize(cfg *config.Config) error {
+	fs := files.Default()
+	if fs == nil {
+		return errors.New("batch: file store is not initialized")
+	}
+	db, err := sqlstore.Open(sqlstore.StateDSN(cfg))
+	if err != nil || db == nil {
+		return err
+	}
+	store, err := NewStore(db)
+	if err != nil {
+		return err
+	}
+	concurrency := cfg.Batch.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	defaultMu.Lock()
+	defaultRunner = NewRunner(store, fs, concurrency)
+	defaultMu.Unlock()
+	return nil
+}
+
+// Stop halts the process-wide runner, if any.
+func Stop() {
+	if r := Default(); r != nil {
+		r.Stop()
+	}
+}
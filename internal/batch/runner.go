@@ -0,0 +1,665 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/files"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/usage"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// MaxRequests is the largest number of lines accepted in one input file.
+	MaxRequests = 50000
+
+	// CodeExpired is the error code of error file lines for requests that
+	// did not run before the completion window ended.
+	CodeExpired = "batch_expired"
+
+	queueSize = 1024
+)
+
+// ExecFunc executes one request body against endpoint and returns the HTTP
+// status code and response body that the endpoint would have produced.
+type ExecFunc func(ctx context.Context, endpoint string, body []byte) (int, []byte)
+
+// Executors maps each batch endpoint to the function that executes its requests.
+type Executors map[string]ExecFunc
+
+// ScopeFunc returns ctx restricted to what the client that submitted b may
+// use, so that its requests are held to the same key restrictions as the
+// client's own HTTP requests.
+type ScopeFunc func(ctx context.Context, b *Batch) context.Context
+
+// AdmitFunc admits one request of the client key apiKey against the key's
+// limits. When ok, release must be called once the request ends; otherwise
+// the request is tried again after retryAfter.
+type AdmitFunc func(ctx context.Context, apiKey string) (release func(), retryAfter time.Duration, ok bool)
+
+// Runner executes batches in the background. Every unfinished batch is
+// processed by its own goroutine, and all of them share concurrency request
+// slots at provider.PriorityLow. Each batch waits for at most one slot at a
+// time, so slots are handed out round-robin and a large batch cannot starve
+// the batches submitted after it.
+type Runner struct {
+	store       *Store
+	files       *files.Store
+	concurrency int
+
+	execs Executors
+	scope ScopeFunc
+	admit AdmitFunc
+	queue chan string
+	slots chan struct{}
+
+	mu      sync.Mutex
+	running map[string]*job
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type job struct {
+	batch  *Batch
+	cancel context.CancelFunc
+}
+
+// inputLine is one request of an input file.
+type inputLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// NewRunner returns a runner that keeps state in store and reads and writes
+// file contents through fs.
+func NewRunner(store *Store, fs *files.Store, concurrency int) *Runner {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		store:       store,
+		files:       fs,
+		concurrency: concurrency,
+		queue:       make(chan string, queueSize),
+		slots:       make(chan struct{}, concurrency),
+		running:     make(map[string]*job),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Store returns the underlying batch store.
+func (r *Runner) Store() *Store { return r.store }
+
+// Files returns the file store used for input and output files.
+func (r *Runner) Files() *files.Store { return r.files }
+
+// Start begins processing with execs and resumes batches left unfinished by a
+// previous run.
+func (r *Runner) Start(execs Executors) {
+	r.execs = execs
+	r.wg.Add(1)
+	go r.loop()
+}
+
+// SetScope makes every request of a batch run in the context scope returns
+// for it. It must be called before Start.
+func (r *Runner) SetScope(scope ScopeFunc) {
+	r.scope = scope
+}
+
+// SetAdmit makes every request of a batch wait until admit lets it count
+// against the limits of the batch's API key. It must be called before Start.
+func (r *Runner) SetAdmit(admit AdmitFunc) {
+	r.admit = admit
+}
+
+// Stop halts processing. Batches in progress are resumed on the next Start.
+func (r *Runner) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Submit persists a new batch and queues it for execution.
+func (r *Runner) Submit(ctx context.Context, b *Batch) error {
+	if err := r.store.Put(ctx, b); err != nil {
+		return err
+	}
+	select {
+	case r.queue <- b.ID:
+	default:
+		// The queue is full; the batch is picked up from the store by the next sweep.
+		log.Warnf("batch: queue full, %s deferred", b.ID)
+	}
+	return nil
+}
+
+// Cancel requests cancellation of batch id and returns its updated state.
+// Only validating and in-progress batches can be cancelled; requests that
+// already completed are kept and written to the output file.
+func (r *Runner) Cancel(ctx context.Context, id string) (*Batch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if j, ok := r.running[id]; ok {
+		if cancellable(j.batch.Status) {
+			j.batch.Status = StatusCancelling
+			j.batch.CancellingAt = now()
+			if err := r.store.Put(ctx, j.batch); err != nil {
+				return nil, err
+			}
+			j.cancel()
+		}
+		return clone(j.batch), nil
+	}
+
+	b, err := r.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cancellable(b.Status) {
+		b.Status = StatusCancelling
+		b.CancellingAt = now()
+		if err := r.store.Put(ctx, b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (r *Runner) loop() {
+	defer r.wg.Done()
+	r.sweep()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case id := <-r.queue:
+			r.start(id)
+		case <-ticker.C:
+			r.sweep()
+		}
+	}
+}
+
+// sweep starts every unfinished batch that is not already running. It resumes
+// work after a restart and picks up batches deferred while the queue was full.
+func (r *Runner) sweep() {
+	pending, err := r.store.Unfinished(r.ctx)
+	if err != nil {
+		if r.ctx.Err() == nil {
+			log.Warnf("batch: failed to load unfinished batches: %v", err)
+		}
+		return
+	}
+	for _, b := range pending {
+		r.start(b.ID)
+	}
+}
+
+// start launches a goroutine processing batch id unless one is already running.
+// The batch is loaded under the runner lock so that a concurrent Cancel either
+// sees the job or has already persisted the cancellation.
+func (r *Runner) start(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.running[id]; ok || r.ctx.Err() != nil {
+		return
+	}
+	b, err := r.store.Get(r.ctx, id)
+	if err != nil {
+		log.Warnf("batch: failed to load %s: %v", id, err)
+		return
+	}
+	if b.Status.Terminal() {
+		return
+	}
+	ctx, cancel := context.WithCancel(r.ctx)
+	j := &job{batch: b, cancel: cancel}
+	r.running[id] = j
+	r.wg.Add(1)
+	go r.process(ctx, j)
+}
+
+// process runs the batch of j to completion or until the runner stops.
+func (r *Runner) process(ctx context.Context, j *job) {
+	defer r.wg.Done()
+	defer j.cancel()
+	defer func() {
+		r.mu.Lock()
+		delete(r.running, j.batch.ID)
+		r.mu.Unlock()
+	}()
+
+	total, lineErrs, err := r.validate(j.batch)
+	if err != nil || len(lineErrs) > 0 {
+		r.update(j, func(b *Batch) {
+			b.Status = StatusFailed
+			b.FailedAt = now()
+			if err != nil {
+				lineErrs = []LineError{{Code: "invalid_file", Message: err.Error()}}
+			}
+			b.Errors = &Errors{Object: "list", Data: lineErrs}
+		})
+		return
+	}
+
+	var run bool
+	r.update(j, func(b *Batch) {
+		if b.Status == StatusValidating {
+			b.Status = StatusInProgress
+			b.InProgressAt = now()
+		}
+		b.RequestCounts.Total = total
+		run = b.Status == StatusInProgress
+	})
+
+	if run {
+		if err := r.execute(ctx, j); err != nil {
+			log.Warnf("batch: failed to read input of %s: %v", j.batch.ID, err)
+		}
+		if r.ctx.Err() != nil {
+			// Shutting down; the batch resumes from its recorded results on restart.
+			return
+		}
+	}
+	r.finalize(j)
+}
+
+// scanInput calls fn with the 0-based index and raw bytes of every non-empty
+// line of the input file of b, stopping early when fn returns false.
+func (r *Runner) scanInput(b *Batch, fn func(i int, raw []byte) bool) error {
+	f, err := r.files.Open(r.ctx, b.InputFileID)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	i := 0
+	for {
+		raw, errRead := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(raw)) > 0 {
+			if !fn(i, raw) {
+				return nil
+			}
+			i++
+		}
+		if errRead == io.EOF {
+			return nil
+		}
+		if errRead != nil {
+			return errRead
+		}
+	}
+}
+
+// eachLine calls fn for every request of the input file of b. Lines have been
+// validated before execution starts, so unparsable ones are skipped.
+func (r *Runner) eachLine(b *Batch, fn func(i int, line inputLine) bool) error {
+	return r.scanInput(b, func(i int, raw []byte) bool {
+		var line inputLine
+		if json.Unmarshal(raw, &line) != nil {
+			return true
+		}
+		return fn(i, line)
+	})
+}
+
+// validate checks the input file of b line by line and returns the number of requests.
+func (r *Runner) validate(b *Batch) (int, []LineError, error) {
+	var (
+		errs  []LineError
+		seen  = make(map[string]struct{})
+		total int
+	)
+	err := r.scanInput(b, func(i int, raw []byte) bool {
+		total++
+		n := i + 1
+		var line inputLine
+		switch {
+		case json.Unmarshal(raw, &line) != nil:
+			errs = append(errs, LineError{Code: "invalid_json_line", Message: "This line is not parseable as valid JSON.", Line: &n})
+		case line.CustomID == "":
+			errs = append(errs, LineError{Code: "missing_required_parameter", Message: "custom_id is required.", Line: &n})
+		case line.Method != "POST":
+			errs = append(errs, LineError{Code: "invalid_method", Message: "The only supported method is POST.", Line: &n})
+		case line.URL != b.Endpoint:
+			errs = append(errs, LineError{Code: "mismatched_endpoint", Message: fmt.Sprintf("The url %q does not match the batch endpoint %q.", line.URL, b.Endpoint), Line: &n})
+		case !gjson.ValidBytes(line.Body) || !gjson.ParseBytes(line.Body).IsObject():
+			errs = append(errs, LineError{Code: "invalid_request", Message: "body must be a JSON object.", Line: &n})
+		default:
+			if _, dup := seen[line.CustomID]; dup {
+				errs = append(errs, LineError{Code: "duplicate_custom_id", Message: fmt.Sprintf("The custom_id %q is used more than once.", line.CustomID), Line: &n})
+			}
+			seen[line.CustomID] = struct{}{}
+		}
+		return total <= MaxRequests
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	switch {
+	case total == 0:
+		errs = append(errs, LineError{Code: "empty_file", Message: "The input file contains no requests."})
+	case total > MaxRequests:
+		errs = append(errs, LineError{Code: "too_many_requests", Message: fmt.Sprintf("The input file contains more than %d requests.", MaxRequests)})
+	}
+	return total, errs, nil
+}
+
+// execute runs every line that has no recorded result yet.
+func (r *Runner) execute(ctx context.Context, j *job) error {
+	done := make(map[int]struct{})
+	_ = r.store.Results(r.ctx, j.batch.ID, func(lineNo int, _ bool, _ []byte) error {
+		done[lineNo] = struct{}{}
+		return nil
+	})
+
+	if j.batch.ExpiresAt != nil {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, time.Unix(*j.batch.ExpiresAt, 0))
+		defer cancelDeadline()
+	}
+	ctx = provider.WithPriority(ctx, provider.PriorityLow)
+	ctx = usage.WithAPIKey(ctx, j.batch.APIKey)
+	if r.scope != nil {
+		ctx = r.scope(ctx, j.batch)
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	return r.eachLine(j.batch, func(i int, line inputLine) bool {
+		if _, ok := done[i]; ok {
+			return true
+		}
+		release, ok := r.admitRequest(ctx, j.batch.APIKey)
+		if !ok {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			release()
+			return false
+		case r.slots <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			<-r.slots
+			release()
+			return false
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-r.slots
+				release()
+				wg.Done()
+			}()
+			status, body := r.run(ctx, line.URL, line.Body)
+			if ctx.Err() != nil {
+				// Cancelled or expired mid-flight; the line is reported as not run.
+				return
+			}
+			ok := status >= 200 && status < 300
+			out := resultLine(line.CustomID, status, body, nil)
+			if err := r.store.PutResult(r.ctx, j.batch.ID, i, ok, out); err != nil {
+				log.Warnf("batch: failed to record result for %s line %d: %v", j.batch.ID, i+1, err)
+				return
+			}
+			r.update(j, func(b *Batch) {
+				if ok {
+					b.RequestCounts.Completed++
+				} else {
+					b.RequestCounts.Failed++
+				}
+			})
+		}()
+		return true
+	})
+}
+
+// admitRequest waits until the next request of a batch submitted with apiKey
+// is admitted. It does so before taking a slot, so that a batch held back by
+// its key's limits leaves the slots to other batches. It returns false when
+// ctx ends first.
+func (r *Runner) admitRequest(ctx context.Context, apiKey string) (func(), bool) {
+	if r.admit == nil {
+		return func() {}, true
+	}
+	for {
+		release, retryAfter, ok := r.admit(ctx, apiKey)
+		if ok {
+			return release, true
+		}
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, false
+		case <-timer.C:
+		}
+	}
+}
+
+// finalize writes the output and error files and moves b to its final state.
+// Results are streamed through temporary files so that large batches are not
+// held in memory.
+func (r *Runner) finalize(j *job) {
+	r.mu.Lock()
+	status := j.batch.Status
+	r.mu.Unlock()
+	expired := status != StatusCancelling && j.batch.ExpiresAt != nil && time.Now().Unix() >= *j.batch.ExpiresAt
+
+	r.update(j, func(b *Batch) {
+		if b.Status == StatusInProgress {
+			b.Status = StatusFinalizing
+			b.FinalizingAt = now()
+		}
+	})
+
+	ctx := r.ctx
+	var completed, failed, expiredCount int
+	outputID, errorID, err := r.writeResults(ctx, j.batch, func(output, errorsOut *bufio.Writer) error {
+		recorded := make(map[int]struct{})
+		err := r.store.Results(ctx, j.batch.ID, func(lineNo int, ok bool, data []byte) error {
+			recorded[lineNo] = struct{}{}
+			w := errorsOut
+			if ok {
+				completed++
+				w = output
+			} else {
+				failed++
+			}
+			_, _ = w.Write(data)
+			return w.WriteByte('\n')
+		})
+		if err != nil || !expired {
+			return err
+		}
+		return r.eachLine(j.batch, func(i int, line inputLine) bool {
+			if _, ok := recorded[i]; ok {
+				return true
+			}
+			expiredCount++
+			_, _ = errorsOut.Write(resultLine(line.CustomID, 0, nil, &LineError{Code: CodeExpired, Message: "This request could not be executed before the completion window expired."}))
+			return errorsOut.WriteByte('\n') == nil
+		})
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warnf("batch: failed to write output files for %s: %v", j.batch.ID, err)
+		r.update(j, func(b *Batch) {
+			b.Status = StatusFailed
+			b.FailedAt = now()
+			b.Errors = &Errors{Object: "list", Data: []LineError{{Code: "output_failed", Message: err.Error()}}}
+		})
+		return
+	}
+
+	r.update(j, func(b *Batch) {
+		b.OutputFileID = outputID
+		b.ErrorFileID = errorID
+		b.RequestCounts.Completed = completed
+		b.RequestCounts.Failed = failed + expiredCount
+		b.RequestCounts.Expired = expiredCount
+		switch {
+		case b.Status == StatusCancelling:
+			b.Status = StatusCancelled
+			b.CancelledAt = now()
+		case expired:
+			b.Status = StatusExpired
+			b.ExpiredAt = now()
+		default:
+			b.Status = StatusCompleted
+			b.CompletedAt = now()
+		}
+	})
+	_ = r.store.DeleteResults(ctx, j.batch.ID)
+}
+
+// writeResults lets fill write the output and error lines of b to temporary
+// files and stores the non-empty ones as the batch's output and error files.
+func (r *Runner) writeResults(ctx context.Context, b *Batch, fill func(output, errorsOut *bufio.Writer) error) (outputID, errorID *string, err error) {
+	output, err := os.CreateTemp("", "llm-mux-batch-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer removeTemp(output)
+	errorsOut, err := os.CreateTemp("", "llm-mux-batch-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer removeTemp(errorsOut)
+
+	ow, ew := bufio.NewWriter(output), bufio.NewWriter(errorsOut)
+	if err := fill(ow, ew); err != nil {
+		return nil, nil, err
+	}
+	if err := ow.Flush(); err != nil {
+		return nil, nil, err
+	}
+	if err := ew.Flush(); err != nil {
+		return nil, nil, err
+	}
+	if outputID, err = r.storeFile(ctx, b, "output", output); err != nil {
+		return nil, nil, err
+	}
+	if errorID, err = r.storeFile(ctx, b, "error", errorsOut); err != nil {
+		return nil, nil, err
+	}
+	return outputID, errorID, nil
+}
+
+// run executes one request with the executor registered for endpoint.
+func (r *Runner) run(ctx context.Context, endpoint string, body []byte) (int, []byte) {
+	exec, ok := r.execs[endpoint]
+	if !ok {
+		data, _ := json.Marshal(map[string]any{"error": map[string]string{
+			"message": fmt.Sprintf("Unsupported endpoint %q.", endpoint),
+			"type":    "invalid_request_error",
+		}})
+		return http.StatusBadRequest, data
+	}
+	return exec(ctx, endpoint, body)
+}
+
+// storeFile stores the contents of tmp as the output or error file of b.
+// Empty files are not created.
+func (r *Runner) storeFile(ctx context.Context, b *Batch, kind string, tmp *os.File) (*string, error) {
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	f, err := r.files.CreateGenerated(ctx, files.File{
+		APIKey:   b.APIKey,
+		Filename: b.ID + "_" + kind + ".jsonl",
+		Purpose:  "batch_output",
+		MimeType: "application/jsonl",
+	}, tmp)
+	if err != nil {
+		return nil, err
+	}
+	return &f.ID, nil
+}
+
+func removeTemp(f *os.File) {
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+}
+
+// update applies fn to the batch of j under the runner lock and persists it.
+func (r *Runner) update(j *job, fn func(*Batch)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(j.batch)
+	if err := r.store.Put(r.ctx, j.batch); err != nil && !errors.Is(err, context.Canceled) {
+		log.Warnf("batch: failed to persist %s: %v", j.batch.ID, err)
+	}
+}
+
+// resultLine formats one line of an output or error file.
+func resultLine(customID string, status int, body []byte, lineErr *LineError) []byte {
+	type response struct {
+		StatusCode int             `json:"status_code"`
+		RequestID  string          `json:"request_id"`
+		Body       json.RawMessage `json:"body"`
+	}
+	out := struct {
+		ID       string     `json:"id"`
+		CustomID string     `json:"custom_id"`
+		Response *response  `json:"response"`
+		Error    *LineError `json:"error"`
+	}{
+		ID:       files.NewID("batch_req_"),
+		CustomID: customID,
+		Error:    lineErr,
+	}
+	if lineErr == nil {
+		if !gjson.ValidBytes(body) {
+			body, _ = json.Marshal(string(body))
+		}
+		out.Response = &response{StatusCode: status, RequestID: files.NewID("req_"), Body: body}
+	}
+	data, _ := json.Marshal(out)
+	return data
+}
+
+func cancellable(s Status) bool {
+	return s == StatusValidating || s == StatusInProgress
+}
+
+func now() *int64 {
+	ts := time.Now().Unix()
+	return &ts
+}
+
+func clone(b *Batch) *Batch {
+	c := *b
+	return &c
+}
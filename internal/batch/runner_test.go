@@ -0,0 +1,378 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/files"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/sqlstore"
+	"github.com/tidwall/gjson"
+)
+
+const testEndpoint = "/v1/chat/completions"
+
+func newTestStores(t *testing.T) (*Store, *files.Store) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := sqlstore.OpenSQLite(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	store, err := NewStore(db)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	blobs, err := files.NewDiskBlobs(filepath.Join(dir, "files"))
+	if err != nil {
+		t.Fatalf("files.NewDiskBlobs: %v", err)
+	}
+	fs, err := files.NewStore(db, blobs, 0, 0)
+	if err != nil {
+		t.Fatalf("files.NewStore: %v", err)
+	}
+	return store, fs
+}
+
+func submit(t *testing.T, r *Runner, input string, expiresAt int64) *Batch {
+	t.Helper()
+	ctx := context.Background()
+	f, err := r.Files().Create(ctx, files.File{APIKey: "key", Filename: "input.jsonl", Purpose: "batch"}, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("create input: %v", err)
+	}
+	b := &Batch{
+		ID:          files.NewID("batch_"),
+		Object:      "batch",
+		Endpoint:    testEndpoint,
+		InputFileID: f.ID,
+		Status:      StatusValidating,
+		CreatedAt:   time.Now().Unix(),
+		ExpiresAt:   &expiresAt,
+		APIKey:      "key",
+	}
+	if err := r.Submit(ctx, b); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	return b
+}
+
+func lines(n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteString(`{"custom_id":"req-` + string(rune('a'+i)) + `","method":"POST","url":"` + testEndpoint + `","body":{"n":` + string(rune('0'+i)) + `}}` + "\n")
+	}
+	return sb.String()
+}
+
+// waitFor polls batch id until cond holds.
+func waitFor(t *testing.T, store *Store, id string, cond func(*Batch) bool) *Batch {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b, err := store.Get(context.Background(), id)
+		if err == nil && cond(b) {
+			return b
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for batch %s (last: %+v, err %v)", id, b, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func readFile(t *testing.T, fs *files.Store, id *string) []string {
+	t.Helper()
+	if id == nil {
+		return nil
+	}
+	f, err := fs.Open(context.Background(), *id)
+	if err != nil {
+		t.Fatalf("open %s: %v", *id, err)
+	}
+	defer f.Close()
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		out = append(out, sc.Text())
+	}
+	return out
+}
+
+func hasStatus(s Status) func(*Batch) bool {
+	return func(b *Batch) bool { return b.Status == s }
+}
+
+// recorder is an ExecFunc that counts calls per request and blocks the
+// requests listed in block until their context ends.
+type recorder struct {
+	mu    sync.Mutex
+	calls map[int64]int
+	block map[int64]bool
+}
+
+func (rec *recorder) exec(ctx context.Context, _ string, body []byte) (int, []byte) {
+	n := gjson.GetBytes(body, "n").Int()
+	rec.mu.Lock()
+	if rec.calls == nil {
+		rec.calls = make(map[int64]int)
+	}
+	rec.calls[n]++
+	rec.mu.Unlock()
+	if rec.block[n] {
+		<-ctx.Done()
+		return 500, []byte(`{}`)
+	}
+	return 200, []byte(`{"ok":true}`)
+}
+
+func (rec *recorder) count(n int64) int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.calls[n]
+}
+
+func TestRunnerCompletesBatch(t *testing.T) {
+	store, fs := newTestStores(t)
+	r := NewRunner(store, fs, 2)
+	rec := &recorder{}
+	r.Start(Executors{testEndpoint: rec.exec})
+	defer r.Stop()
+
+	b := submit(t, r, lines(3), time.Now().Add(time.Hour).Unix())
+	b = waitFor(t, store, b.ID, hasStatus(StatusCompleted))
+	if b.RequestCounts.Total != 3 || b.RequestCounts.Completed != 3 {
+		t.Fatalf("request counts = %+v", b.RequestCounts)
+	}
+	out := readFile(t, fs, b.OutputFileID)
+	if len(out) != 3 || b.ErrorFileID != nil {
+		t.Fatalf("output has %d lines, error file %v", len(out), b.ErrorFileID)
+	}
+	if got := gjson.Get(out[0], "response.status_code").Int(); got != 200 {
+		t.Errorf("status_code = %d", got)
+	}
+}
+
+func TestRunnerRejectsInvalidInput(t *testing.T) {
+	store, fs := newTestStores(t)
+	r := NewRunner(store, fs, 1)
+	rec := &recorder{}
+	r.Start(Executors{testEndpoint: rec.exec})
+	defer r.Stop()
+
+	input := "not json\n" +
+		`{"method":"POST","url":"` + testEndpoint + `","body":{}}` + "\n" +
+		`{"custom_id":"x","method":"POST","url":"/v1/embeddings","body":{}}` + "\n"
+	b := submit(t, r, input, time.Now().Add(time.Hour).Unix())
+	b = waitFor(t, store, b.ID, hasStatus(StatusFailed))
+	if b.Errors == nil || len(b.Errors.Data) != 3 {
+		t.Fatalf("errors = %+v", b.Errors)
+	}
+	want := []string{"invalid_json_line", "missing_required_parameter", "mismatched_endpoint"}
+	for i, e := range b.Errors.Data {
+		if e.Code != want[i] || e.Line == nil || *e.Line != i+1 {
+			t.Errorf("error %d = %s line %v, want %s line %d", i, e.Code, e.Line, want[i], i+1)
+		}
+	}
+	if len(rec.calls) != 0 {
+		t.Errorf("invalid batch executed requests")
+	}
+}
+
+func TestRunnerCancelKeepsCompletedResults(t *testing.T) {
+	store, fs := newTestStores(t)
+	r := NewRunner(store, fs, 1)
+	rec := &recorder{block: map[int64]bool{1: true}}
+	r.Start(Executors{testEndpoint: rec.exec})
+	defer r.Stop()
+
+	b := submit(t, r, lines(3), time.Now().Add(time.Hour).Unix())
+	waitFor(t, store, b.ID, func(b *Batch) bool { return b.RequestCounts.Completed == 1 })
+	if _, err := r.Cancel(context.Background(), b.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	b = waitFor(t, store, b.ID, hasStatus(StatusCancelled))
+	if b.RequestCounts.Completed != 1 || b.RequestCounts.Failed != 0 {
+		t.Fatalf("request counts = %+v", b.RequestCounts)
+	}
+	if out := readFile(t, fs, b.OutputFileID); len(out) != 1 || gjson.Get(out[0], "custom_id").String() != "req-a" {
+		t.Fatalf("output = %v", out)
+	}
+	if rec.count(2) != 0 {
+		t.Errorf("request after cancellation was executed")
+	}
+}
+
+func TestRunnerResumesWithoutRerunningRecordedLines(t *testing.T) {
+	store, fs := newTestStores(t)
+	r := NewRunner(store, fs, 1)
+	first := &recorder{block: map[int64]bool{1: true}}
+	r.Start(Executors{testEndpoint: first.exec})
+
+	b := submit(t, r, lines(3), time.Now().Add(time.Hour).Unix())
+	waitFor(t, store, b.ID, func(b *Batch) bool { return b.RequestCounts.Completed == 1 })
+	r.Stop()
+	if got, _ := store.Get(context.Background(), b.ID); got.Status != StatusInProgress {
+		t.Fatalf("status after Stop = %s, want in_progress", got.Status)
+	}
+
+	r2 := NewRunner(store, fs, 1)
+	second := &recorder{}
+	r2.Start(Executors{testEndpoint: second.exec})
+	defer r2.Stop()
+
+	b = waitFor(t, store, b.ID, hasStatus(StatusCompleted))
+	if second.count(0) != 0 {
+		t.Errorf("recorded line 0 was executed again")
+	}
+	if second.count(1) != 1 || second.count(2) != 1 {
+		t.Errorf("remaining lines ran %d and %d times", second.count(1), second.count(2))
+	}
+	if out := readFile(t, fs, b.OutputFileID); len(out) != 3 {
+		t.Fatalf("output has %d lines, want 3", len(out))
+	}
+}
+
+func TestRunnerExpiresUnrunLines(t *testing.T) {
+	store, fs := newTestStores(t)
+	r := NewRunner(store, fs, 1)
+	rec := &recorder{}
+	r.Start(Executors{testEndpoint: rec.exec})
+	defer r.Stop()
+
+	b := submit(t, r, lines(2), time.Now().Add(-time.Second).Unix())
+	b = waitFor(t, store, b.ID, hasStatus(StatusExpired))
+	if b.RequestCounts.Failed != 2 || b.RequestCounts.Expired != 2 {
+		t.Errorf("request counts = %+v, want 2 failed of which 2 expired", b.RequestCounts)
+	}
+	if out, _ := json.Marshal(b); gjson.GetBytes(out, "request_counts.expired").Exists() {
+		t.Errorf("expired count leaked into the batch object: %s", out)
+	}
+	if b.OutputFileID != nil {
+		t.Errorf("unexpected output file")
+	}
+	errs := readFile(t, fs, b.ErrorFileID)
+	if len(errs) != 2 {
+		t.Fatalf("error file has %d lines, want 2", len(errs))
+	}
+	for _, line := range errs {
+		if gjson.Get(line, "error.code").String() != CodeExpired {
+			t.Errorf("error line = %s", line)
+		}
+	}
+	if len(rec.calls) != 0 {
+		t.Errorf("expired batch executed requests")
+	}
+}
+
+func TestRunnerRunsBatchesConcurrently(t *testing.T) {
+	store, fs := newTestStores(t)
+	r := NewRunner(store, fs, 2)
+	// The first batch holds a slot until it is cancelled, so the second one
+	// only completes if it does not wait for the first batch to finish.
+	rec := &recorder{block: map[int64]bool{0: true}}
+	r.Start(Executors{testEndpoint: rec.exec})
+	defer r.Stop()
+
+	slow := submit(t, r, lines(1), time.Now().Add(time.Hour).Unix())
+	waitFor(t, store, slow.ID, hasStatus(StatusInProgress))
+	fast := submit(t, r, `{"custom_id":"x","method":"POST","url":"`+testEndpoint+`","body":{"n":5}}`+"\n", time.Now().Add(time.Hour).Unix())
+	waitFor(t, store, fast.ID, hasStatus(StatusCompleted))
+
+	if _, err := r.Cancel(context.Background(), slow.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	waitFor(t, store, slow.ID, hasStatus(StatusCancelled))
+}
+
+func TestRunnerScopesRequestsToSubmitter(t *testing.T) {
+	store, fs := newTestStores(t)
+	r := NewRunner(store, fs, 1)
+	type scopeKey struct{}
+	r.SetScope(func(ctx context.Context, b *Batch) context.Context {
+		return context.WithValue(ctx, scopeKey{}, b.APIKey+"/"+b.AccessProvider+"/"+b.AuthTags)
+	})
+	r.Start(Executors{testEndpoint: func(ctx context.Context, _ string, _ []byte) (int, []byte) {
+		if ctx.Value(scopeKey{}) != "key/virtual-keys/team-a" {
+			return 403, []byte(`{}`)
+		}
+		return 200, []byte(`{}`)
+	}})
+	defer r.Stop()
+
+	ctx := context.Background()
+	f, err := fs.Create(ctx, files.File{APIKey: "key", Filename: "input.jsonl", Purpose: "batch"}, strings.NewReader(lines(2)))
+	if err != nil {
+		t.Fatalf("create input: %v", err)
+	}
+	expires := time.Now().Add(time.Hour).Unix()
+	b := &Batch{
+		ID:             files.NewID("batch_"),
+		Object:         "batch",
+		Endpoint:       testEndpoint,
+		InputFileID:    f.ID,
+		Status:         StatusValidating,
+		CreatedAt:      time.Now().Unix(),
+		ExpiresAt:      &expires,
+		APIKey:         "key",
+		AccessProvider: "virtual-keys",
+		AuthTags:       "team-a",
+	}
+	if err := r.Submit(ctx, b); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	b = waitFor(t, store, b.ID, hasStatus(StatusCompleted))
+	if b.AccessProvider != "virtual-keys" || b.AuthTags != "team-a" {
+		t.Errorf("stored submitter = %q, %q", b.AccessProvider, b.AuthTags)
+	}
+	if b.RequestCounts.Completed != 2 {
+		t.Errorf("request counts = %+v", b.RequestCounts)
+	}
+}
+
+func TestRunnerWaitsForKeyAdmission(t *testing.T) {
+	store, fs := newTestStores(t)
+	r := NewRunner(store, fs, 2)
+	var (
+		mu       sync.Mutex
+		admitted int
+		inFlight int
+		peak     int
+	)
+	// The key admits one request at a time, although the runner has two slots.
+	r.SetAdmit(func(_ context.Context, apiKey string) (func(), time.Duration, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if apiKey != "key" || inFlight > 0 {
+			return nil, time.Millisecond, false
+		}
+		admitted++
+		inFlight++
+		peak = max(peak, inFlight)
+		return func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}, 0, true
+	})
+	rec := &recorder{}
+	r.Start(Executors{testEndpoint: rec.exec})
+	defer r.Stop()
+
+	b := submit(t, r, lines(4), time.Now().Add(time.Hour).Unix())
+	b = waitFor(t, store, b.ID, hasStatus(StatusCompleted))
+	if b.RequestCounts.Completed != 4 {
+		t.Fatalf("request counts = %+v", b.RequestCounts)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if admitted != 4 || peak != 1 || inFlight != 0 {
+		t.Errorf("admitted %d, peak %d, in flight %d; want 4, 1, 0", admitted, peak, inFlight)
+	}
+}
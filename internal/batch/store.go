@@ -0,0 +1,320 @@
+// Package batch emulates the OpenAI Batch API. Uploaded JSONL files are
+// executed line by line in the background at low priority, and the results
+// are written back as output and error files.
+package batch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/sqlstore"
+)
+
+// ErrNotFound is returned when a batch does not exist or belongs to another API key.
+var ErrNotFound = errors.New("batch not found")
+
+// Status is the lifecycle state of a batch.
+type Status string
+
+const (
+	StatusValidating Status = "validating"
+	StatusFailed     Status = "failed"
+	StatusInProgress Status = "in_progress"
+	StatusFinalizing Status = "finalizing"
+	StatusCompleted  Status = "completed"
+	StatusExpired    Status = "expired"
+	StatusCancelling Status = "cancelling"
+	StatusCancelled  Status = "cancelled"
+)
+
+// Terminal reports whether no further work will happen for a batch in this state.
+func (s Status) Terminal() bool {
+	switch s {
+	case StatusFailed, StatusCompleted, StatusExpired, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// RequestCounts tracks per-request progress of a batch. Requests that never
+// ran because the completion window ended count as Failed, as in the OpenAI
+// API; Expired additionally records how many of them there were for the
+// Anthropic view and is stored outside the batch object.
+type RequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Expired   int `json:"-"`
+}
+
+// Errors lists problems that prevented a batch from running.
+type Errors struct {
+	Object string      `json:"object"`
+	Data   []LineError `json:"data"`
+}
+
+// LineError describes a single validation error in the input file.
+type LineError struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Param   *string `json:"param"`
+	Line    *int    `json:"line"`
+}
+
+// Batch is a batch job in the OpenAI batch object format. APIKey and
+// AccessProvider identify the client that submitted it, and AuthTags holds the
+// auth tag header it was submitted with.
+type Batch struct {
+	ID               string            `json:"id"`
+	Object           string            `json:"object"`
+	Endpoint         string            `json:"endpoint"`
+	Errors           *Errors           `json:"errors"`
+	InputFileID      string            `json:"input_file_id"`
+	CompletionWindow string            `json:"completion_window"`
+	Status           Status            `json:"status"`
+	OutputFileID     *string           `json:"output_file_id"`
+	ErrorFileID      *string           `json:"error_file_id"`
+	CreatedAt        int64             `json:"created_at"`
+	InProgressAt     *int64            `json:"in_progress_at"`
+	ExpiresAt        *int64            `json:"expires_at"`
+	FinalizingAt     *int64            `json:"finalizing_at"`
+	CompletedAt      *int64            `json:"completed_at"`
+	FailedAt         *int64            `json:"failed_at"`
+	ExpiredAt        *int64            `json:"expired_at"`
+	CancellingAt     *int64            `json:"cancelling_at"`
+	CancelledAt      *int64            `json:"cancelled_at"`
+	RequestCounts    RequestCounts     `json:"request_counts"`
+	Metadata         map[string]string `json:"metadata"`
+	APIKey           string            `json:"-"`
+	AccessProvider   string            `json:"-"`
+	AuthTags         string            `json:"-"`
+}
+
+// Store persists batches and their per-line results in SQL.
+type Store struct {
+	db *sqlstore.DB
+}
+
+// NewStore creates the batch tables if needed and returns a store backed by db.
+func NewStore(db *sqlstore.DB) (*Store, error) {
+	err := db.Migrate(
+		`CREATE TABLE IF NOT EXISTS batches (
+			id TEXT PRIMARY KEY,
+			api_key TEXT NOT NULL DEFAULT '',
+			input_file_id TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL,
+			data TEXT NOT NULL,
+			expired BIGINT NOT NULL DEFAULT 0,
+			created_at BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_batches_api_key ON batches(api_key, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_batches_status ON batches(status)`,
+		`CREATE INDEX IF NOT EXISTS idx_batches_input_file ON batches(input_file_id)`,
+		`CREATE TABLE IF NOT EXISTS batch_results (
+			batch_id TEXT NOT NULL,
+			line_no BIGINT NOT NULL,
+			ok BOOLEAN NOT NULL,
+			data TEXT NOT NULL,
+			PRIMARY KEY (batch_id, line_no)
+		)`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Put inserts or replaces b.
+func (s *Store) Put(ctx context.Context, b *Batch) error {
+	data, err := json.Marshal(storedBatch{Batch: b, AccessProvider: b.AccessProvider, AuthTags: b.AuthTags})
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, s.db.Rebind(`
+		INSERT INTO batches (id, api_key, input_file_id, status, data, expired, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET status = excluded.status, data = excluded.data, expired = excluded.expired`),
+		b.ID, b.APIKey, b.InputFileID, string(b.Status), string(data), b.RequestCounts.Expired, b.CreatedAt)
+	return err
+}
+
+// Get returns batch id.
+func (s *Store) Get(ctx context.Context, id string) (*Batch, error) {
+	row := s.db.QueryRowContext(ctx, s.db.Rebind(`SELECT api_key, data, expired FROM batches WHERE id = ?`), id)
+	var (
+		apiKey, data string
+		expired      int
+	)
+	if err := row.Scan(&apiKey, &data, &expired); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return decode(apiKey, data, expired)
+}
+
+// ByInputFile returns the id of a batch that reads file id, or ErrNotFound
+// when no batch refers to it.
+func (s *Store) ByInputFile(ctx context.Context, fileID string) (string, error) {
+	var id string
+	err := s.db.QueryRowContext(ctx, s.db.Rebind(`SELECT id FROM batches WHERE input_file_id = ? LIMIT 1`), fileID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return id, err
+}
+
+// ListOptions selects a page of batches for List.
+type ListOptions struct {
+	APIKey string
+	// Prefix restricts the listing to ids with this prefix, which separates
+	// OpenAI batches from Anthropic message batches.
+	Prefix string
+	// After returns batches older than this id; Before returns newer ones.
+	After  string
+	Before string
+	Limit  int
+}
+
+// List returns up to opts.Limit batches, newest first. The boolean reports
+// whether more batches follow in the direction of the page.
+func (s *Store) List(ctx context.Context, opts ListOptions) ([]*Batch, bool, error) {
+	query := `SELECT api_key, data, expired FROM batches WHERE api_key = ? AND id LIKE ?`
+	args := []any{opts.APIKey, opts.Prefix + "%"}
+	order := `DESC`
+	switch {
+	case opts.After != "":
+		query += ` AND (created_at, id) < (SELECT created_at, id FROM batches WHERE id = ?)`
+		args = append(args, opts.After)
+	case opts.Before != "":
+		query += ` AND (created_at, id) > (SELECT created_at, id FROM batches WHERE id = ?)`
+		args = append(args, opts.Before)
+		order = `ASC`
+	}
+	query += ` ORDER BY created_at ` + order + `, id ` + order + ` LIMIT ?`
+	args = append(args, opts.Limit+1)
+
+	out, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(out) > opts.Limit
+	if hasMore {
+		out = out[:opts.Limit]
+	}
+	if order == `ASC` {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+	return out, hasMore, nil
+}
+
+// Delete removes batch id and any results that were not yet finalized.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, s.db.Rebind(`DELETE FROM batches WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return s.DeleteResults(ctx, id)
+}
+
+// Unfinished returns every batch that has not reached a terminal state, oldest first.
+func (s *Store) Unfinished(ctx context.Context) ([]*Batch, error) {
+	return s.query(ctx, `
+		SELECT api_key, data, expired FROM batches WHERE status IN (?, ?, ?, ?)
+		ORDER BY created_at, id`,
+		string(StatusValidating), string(StatusInProgress), string(StatusFinalizing), string(StatusCancelling))
+}
+
+func (s *Store) query(ctx context.Context, query string, args ...any) ([]*Batch, error) {
+	rows, err := s.db.QueryContext(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*Batch
+	for rows.Next() {
+		var (
+			apiKey, data string
+			expired      int
+		)
+		if err := rows.Scan(&apiKey, &data, &expired); err != nil {
+			return nil, err
+		}
+		b, err := decode(apiKey, data, expired)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// PutResult records the output line produced for line lineNo of batch id.
+func (s *Store) PutResult(ctx context.Context, id string, lineNo int, ok bool, data []byte) error {
+	_, err := s.db.ExecContext(ctx, s.db.Rebind(`
+		INSERT INTO batch_results (batch_id, line_no, ok, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (batch_id, line_no) DO UPDATE SET ok = excluded.ok, data = excluded.data`),
+		id, lineNo, ok, string(data))
+	return err
+}
+
+// Results calls fn for every recorded result of batch id in line order.
+func (s *Store) Results(ctx context.Context, id string, fn func(lineNo int, ok bool, data []byte) error) error {
+	rows, err := s.db.QueryContext(ctx, s.db.Rebind(`
+		SELECT line_no, ok, data FROM batch_results WHERE batch_id = ? ORDER BY line_no`), id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			lineNo int
+			ok     bool
+			data   string
+		)
+		if err := rows.Scan(&lineNo, &ok, &data); err != nil {
+			return err
+		}
+		if err := fn(lineNo, ok, []byte(data)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// DeleteResults drops the per-line results of batch id once they have been
+// written to output files.
+func (s *Store) DeleteResults(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.db.Rebind(`DELETE FROM batch_results WHERE batch_id = ?`), id)
+	return err
+}
+
+// storedBatch is the stored form of a batch. It keeps the fields that
+// identify the submitting client, which the batch object does not expose.
+type storedBatch struct {
+	*Batch
+	AccessProvider string `json:"access_provider,omitempty"`
+	AuthTags       string `json:"auth_tags,omitempty"`
+}
+
+func decode(apiKey, data string, expired int) (*Batch, error) {
+	var b Batch
+	stored := storedBatch{Batch: &b}
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, err
+	}
+	b.APIKey = apiKey
+	b.AccessProvider = stored.AccessProvider
+	b.AuthTags = stored.AuthTags
+	b.RequestCounts.Expired = expired
+	return &b, nil
+}
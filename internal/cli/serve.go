@@ -4,10 +4,15 @@ import (
 	"os"
 	"time"
 
+	"github.com/nghyane/llm-mux/internal/batch"
 	"github.com/nghyane/llm-mux/internal/bootstrap"
+	"github.com/nghyane/llm-mux/internal/cli/env"
 	"github.com/nghyane/llm-mux/internal/cmd"
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/files"
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/responses"
+	"github.com/nghyane/llm-mux/internal/store"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/spf13/cobra"
 )
@@ -46,6 +51,14 @@ initializes the token stores, and starts the HTTP server.`,
 		if cfg.Usage.DSN != "" {
 			initUsageBackend(cfg)
 		}
+		if err := responses.Initialize(cfg); err != nil {
+			log.Warnf("Failed to initialize response store: %v", err)
+		}
+		if err := files.Initialize(cfg, store.ParseFromEnv(env.LookupEnv)); err != nil {
+			log.Warnf("Failed to initialize file store: %v", err)
+		} else if err := batch.Initialize(cfg); err != nil {
+			log.Warnf("Failed to initialize batch runner: %v", err)
+		}
 
 		if err := log.ConfigureLogOutput(cfg.LoggingToFile); err != nil {
 			log.Fatalf("Failed to configure log output: %v", err)
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultAdmissionMaxWait is how long a request may queue when MaxWait is unset.
+const DefaultAdmissionMaxWait = 2 * time.Minute
+
+// PriorityHeader lets a client mark its request as background work.
+const PriorityHeader = "X-LLM-Mux-Priority"
+
+// AdmissionConfig queues requests while every credential for their model is
+// cooling down, instead of failing them with 429 straight away.
+type AdmissionConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// MaxWait bounds the time a request spends queued, e.g. "2m". The
+	// client's own deadline is honoured when it is shorter.
+	MaxWait string `yaml:"max-wait,omitempty" json:"max-wait,omitempty"`
+	// MaxDepth caps the queued requests per model; further requests get the
+	// 429 immediately. Zero means no cap.
+	MaxDepth int `yaml:"max-depth,omitempty" json:"max-depth,omitempty"`
+	// BackgroundAPIKeys lists client keys whose requests always queue behind
+	// interactive traffic.
+	BackgroundAPIKeys []string `yaml:"background-api-keys,omitempty" json:"background-api-keys,omitempty"`
+}
+
+// EffectiveMaxWait returns MaxWait, or the default when it is empty or invalid.
+func (a AdmissionConfig) EffectiveMaxWait() time.Duration {
+	if d, err := time.ParseDuration(a.MaxWait); err == nil && d > 0 {
+		return d
+	}
+	return DefaultAdmissionMaxWait
+}
+
+// IsBackground reports whether a request is background work: either its API
+// key is listed in BackgroundAPIKeys or the client sent the priority header
+// with "background" (or "low"). The header cannot raise a background key to
+// interactive.
+func (a AdmissionConfig) IsBackground(apiKey, priorityHeader string) bool {
+	switch strings.ToLower(strings.TrimSpace(priorityHeader)) {
+	case "background", "low":
+		return true
+	}
+	return apiKey != "" && slices.Contains(a.BackgroundAPIKeys, apiKey)
+}
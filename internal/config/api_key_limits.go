@@ -0,0 +1,57 @@
+package config
+
+import "strings"
+
+// DefaultAPIKeyLimit is the api-key-limits entry applied to client keys that
+// have no entry of their own.
+const DefaultAPIKeyLimit = "*"
+
+// APIKeyLimit caps the traffic of one client API key. Zero leaves a limit off.
+type APIKeyLimit struct {
+	// RequestsPerMinute caps the requests started per minute.
+	RequestsPerMinute int `yaml:"requests-per-minute,omitempty" json:"requests-per-minute,omitempty"`
+	// TokensPerDay and TokensPerMonth cap the total tokens used per UTC day
+	// and calendar month, counted from the usage records.
+	TokensPerDay   int64 `yaml:"tokens-per-day,omitempty" json:"tokens-per-day,omitempty"`
+	TokensPerMonth int64 `yaml:"tokens-per-month,omitempty" json:"tokens-per-month,omitempty"`
+	// MaxConcurrent caps the requests, streams included, in flight at once.
+	MaxConcurrent int `yaml:"max-concurrent,omitempty" json:"max-concurrent,omitempty"`
+}
+
+// IsZero reports whether l sets no limit.
+func (l APIKeyLimit) IsZero() bool {
+	return l.RequestsPerMinute <= 0 && l.TokensPerDay <= 0 && l.TokensPerMonth <= 0 && l.MaxConcurrent <= 0
+}
+
+// APIKeyLimitFor returns the limits of apiKey: its own entry, or the "*"
+// entry when it has none.
+func (cfg *Config) APIKeyLimitFor(apiKey string) (APIKeyLimit, bool) {
+	if cfg == nil || len(cfg.APIKeyLimits) == 0 {
+		return APIKeyLimit{}, false
+	}
+	if limit, ok := cfg.APIKeyLimits[apiKey]; ok {
+		return limit, true
+	}
+	limit, ok := cfg.APIKeyLimits[DefaultAPIKeyLimit]
+	return limit, ok
+}
+
+// NormalizeAPIKeyLimits trims keys and drops empty keys and entries without
+// any limit.
+func NormalizeAPIKeyLimits(entries map[string]APIKeyLimit) map[string]APIKeyLimit {
+	if len(entries) == 0 {
+		return nil
+	}
+	out := make(map[string]APIKeyLimit, len(entries))
+	for key, limit := range entries {
+		key = strings.TrimSpace(key)
+		if key == "" || limit.IsZero() {
+			continue
+		}
+		out[key] = limit
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AuthTagHeader lets a client pick the tagged auths that serve its request.
+const AuthTagHeader = "X-LLM-Mux-Auth-Tag"
+
+// AuthTagsFor returns the auth tags bound to a client API key, or nil.
+func (r *RoutingConfig) AuthTagsFor(apiKey string) []string {
+	if r == nil || apiKey == "" {
+		return nil
+	}
+	return r.AuthTags[apiKey]
+}
+
+// ResolveAuthTags returns the auth tags a request is restricted to, given the
+// tags bound to its API key and the value of AuthTagHeader. The header lists
+// tags separated by commas and narrows the binding; asking for a tag outside a
+// non-empty binding is an error. Without a header the binding applies as is.
+func ResolveAuthTags(bound []string, header string) ([]string, error) {
+	requested := splitAuthTags(header)
+	if len(requested) == 0 {
+		return bound, nil
+	}
+	if len(bound) > 0 {
+		for _, tag := range requested {
+			if !slices.Contains(bound, tag) {
+				return nil, fmt.Errorf("auth tag %q is not allowed for this API key", tag)
+			}
+		}
+	}
+	return requested, nil
+}
+
+// NormalizeAuthTags lowercases and trims tags, dropping empty and duplicate ones.
+func NormalizeAuthTags(tags []string) []string {
+	return splitAuthTags(strings.Join(tags, ","))
+}
+
+func splitAuthTags(raw string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, ",") {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
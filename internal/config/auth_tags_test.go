@@ -0,0 +1,27 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolveAuthTags(t *testing.T) {
+	cases := []struct {
+		bound   []string
+		header  string
+		want    []string
+		wantErr bool
+	}{
+		{nil, "", nil, false},
+		{nil, "Work, personal", []string{"work", "personal"}, false},
+		{[]string{"work"}, "", []string{"work"}, false},
+		{[]string{"work", "ci"}, "ci", []string{"ci"}, false},
+		{[]string{"work"}, "personal", nil, true},
+	}
+	for _, tc := range cases {
+		got, err := ResolveAuthTags(tc.bound, tc.header)
+		if (err != nil) != tc.wantErr || !slices.Equal(got, tc.want) {
+			t.Errorf("ResolveAuthTags(%v, %q) = %v, %v; want %v (error %v)", tc.bound, tc.header, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultCacheAffinityTTL matches the default lifetime of Anthropic's
+// ephemeral prompt cache.
+const DefaultCacheAffinityTTL = 5 * time.Minute
+
+// CacheAffinityConfig keeps requests that share a cacheable prompt prefix on
+// the same credential, so the upstream prompt cache is reused instead of being
+// rebuilt on another account.
+type CacheAffinityConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// TTL is how long an unused affinity lasts, e.g. "5m". It should match
+	// the provider's cache TTL.
+	TTL string `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	// ProviderTTL overrides TTL per provider, e.g. "claude": "1h" for the
+	// extended cache. "0" disables affinity for a provider.
+	ProviderTTL map[string]string `yaml:"provider-ttl,omitempty" json:"provider-ttl,omitempty"`
+}
+
+// TTLs returns TTL, or the default when it is empty or invalid, and the valid
+// per-provider overrides keyed by lowercased provider name.
+func (c CacheAffinityConfig) TTLs() (time.Duration, map[string]time.Duration) {
+	ttl := DefaultCacheAffinityTTL
+	if d, err := time.ParseDuration(c.TTL); err == nil && d > 0 {
+		ttl = d
+	}
+	perProvider := make(map[string]time.Duration, len(c.ProviderTTL))
+	for provider, raw := range c.ProviderTTL {
+		raw = strings.TrimSpace(raw)
+		if raw == "0" {
+			perProvider[strings.ToLower(provider)] = 0
+			continue
+		}
+		if d, err := time.ParseDuration(raw); err == nil && d >= 0 {
+			perProvider[strings.ToLower(provider)] = d
+		}
+	}
+	return ttl, perProvider
+}
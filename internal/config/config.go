@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"syscall"
 
@@ -28,6 +29,9 @@ type SDKConfig struct {
 	// APIKeys is a list of keys for authenticating clients to this proxy server.
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 
+	// VirtualKeys are managed client keys with a scope, stored hashed.
+	VirtualKeys []VirtualKey `yaml:"virtual-keys,omitempty" json:"virtual-keys,omitempty"`
+
 	// Access holds request authentication provider configuration.
 	Access AccessConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
 
@@ -108,16 +112,24 @@ type Config struct {
 	Debug            bool             `yaml:"debug" json:"debug"`
 	LoggingToFile    bool             `yaml:"logging-to-file" json:"logging-to-file"`
 
-	Usage            UsageConfig   `yaml:"usage" json:"usage"`
-	DisableCooling   bool          `yaml:"disable-cooling" json:"disable-cooling"`
-	RequestRetry     int           `yaml:"request-retry" json:"request-retry"`
-	MaxRetryInterval int           `yaml:"max-retry-interval" json:"max-retry-interval"`
-	StreamTimeout    int           `yaml:"stream-timeout" json:"stream-timeout"`
-	QuotaWindow      int           `yaml:"quota-window" json:"quota-window"`
-	QuotaExceeded    QuotaExceeded `yaml:"quota-exceeded" json:"quota-exceeded"`
+	Usage            UsageConfig     `yaml:"usage" json:"usage"`
+	Responses        ResponsesConfig `yaml:"responses,omitempty" json:"responses,omitempty"`
+	Files            FilesConfig     `yaml:"files,omitempty" json:"files,omitempty"`
+	Batch            BatchConfig     `yaml:"batch,omitempty" json:"batch,omitempty"`
+	DisableCooling   bool            `yaml:"disable-cooling" json:"disable-cooling"`
+	RequestRetry     int             `yaml:"request-retry" json:"request-retry"`
+	MaxRetryInterval int             `yaml:"max-retry-interval" json:"max-retry-interval"`
+	StreamTimeout    int             `yaml:"stream-timeout" json:"stream-timeout"`
+	QuotaWindow      int             `yaml:"quota-window" json:"quota-window"`
+	QuotaExceeded    QuotaExceeded   `yaml:"quota-exceeded" json:"quota-exceeded"`
+	// Quota overrides the quota model of providers and individual auths.
+	Quota QuotaConfig `yaml:"quota,omitempty" json:"quota,omitempty"`
 
 	WebsocketAuth bool `yaml:"ws-auth" json:"ws-auth"`
 	DisableAuth   bool `yaml:"disable-auth" json:"disable-auth"`
+	// OllamaAuth requires API keys on the Ollama routes (/api, /ollama/api),
+	// which are open by default. It has no effect while disable-auth is set.
+	OllamaAuth bool `yaml:"ollama-auth" json:"ollama-auth"`
 
 	// Providers is the unified provider configuration.
 	Providers []Provider `yaml:"providers,omitempty" json:"providers,omitempty"`
@@ -129,6 +141,11 @@ type Config struct {
 	OAuthExcludedModels map[string][]string `yaml:"oauth-excluded-models,omitempty" json:"oauth-excluded-models,omitempty"`
 	Payload             PayloadConfig       `yaml:"payload" json:"payload"`
 	Routing             RoutingConfig       `yaml:"routing,omitempty" json:"routing,omitempty"`
+	Pricing             PricingConfig       `yaml:"pricing,omitempty" json:"pricing,omitempty"`
+
+	// APIKeyLimits caps requests, tokens and concurrency per client API key.
+	// The "*" entry applies to keys without their own.
+	APIKeyLimits map[string]APIKeyLimit `yaml:"api-key-limits,omitempty" json:"api-key-limits,omitempty"`
 
 	// UseCanonicalTranslator enables the unified IR translator architecture (default: true).
 	UseCanonicalTranslator bool `yaml:"use-canonical-translator" json:"use-canonical-translator" default:"true"`
@@ -180,6 +197,51 @@ type UsageConfig struct {
 	RetentionDays int `yaml:"retention-days" json:"retention-days"`
 }
 
+// ResponsesConfig controls storage of Responses API results used for
+// previous_response_id chaining and the GET/DELETE /v1/responses endpoints.
+type ResponsesConfig struct {
+	// Store selects the backend: "memory", "database" (reuses usage.dsn) or "none".
+	// Empty picks "database" when usage.dsn is set and "memory" otherwise.
+	Store string `yaml:"store,omitempty" json:"store,omitempty"`
+
+	// TTL defines how long stored responses are kept. Accepts duration string. Default: "720h".
+	TTL string `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// MaxEntries caps the number of responses held by the memory store. Default: 10000.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+}
+
+// FilesConfig controls storage of files uploaded through /v1/files. When an
+// object store is configured (LLM_MUX_OBJECTSTORE_*), contents are kept there
+// instead of in Dir.
+type FilesConfig struct {
+	// Dir is the directory holding file contents. Default: "files" under the config directory.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+	// MaxSizeMB caps the size of a single upload in megabytes. Default: 200.
+	MaxSizeMB int `yaml:"max-size-mb,omitempty" json:"max-size-mb,omitempty"`
+
+	// TTL is the default lifetime of uploads that do not set expires_after,
+	// e.g. "720h". Empty keeps them until they are deleted.
+	TTL string `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+}
+
+// MaxSizeBytes returns the upload limit in bytes, applying the default.
+func (f FilesConfig) MaxSizeBytes() int64 {
+	if f.MaxSizeMB <= 0 {
+		return 200 << 20
+	}
+	return int64(f.MaxSizeMB) << 20
+}
+
+// BatchConfig controls the job runner behind /v1/batches.
+// Jobs are persisted in usage.dsn, or in a SQLite database under the config
+// directory when usage.dsn is empty.
+type BatchConfig struct {
+	// Concurrency bounds the number of batch requests in flight at once. Default: 4.
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+}
+
 // AmpModelMapping defines a model name mapping for Amp CLI requests.
 // When Amp requests a model that isn't available locally, this mapping
 // allows routing to an alternative model that IS available.
@@ -220,6 +282,11 @@ type PayloadModelRule struct {
 
 // RoutingConfig defines provider routing and priority settings.
 type RoutingConfig struct {
+	// Strategy selects how providers and auths are ordered: empty for
+	// performance-based selection, "cost" to prefer subscription accounts
+	// and the cheapest paid capacity (see Pricing).
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
 	// ProviderPriority maps provider names to their routing priority.
 	// Lower values have higher priority (1 = highest).
 	// Provider names must match executor identifiers exactly:
@@ -237,6 +304,29 @@ type RoutingConfig struct {
 	// Example: "claude-opus-4-5" -> ["claude-sonnet-4-5", "gpt-4o"]
 	Fallbacks map[string][]string `yaml:"fallbacks,omitempty" json:"fallbacks,omitempty"`
 
+	// VirtualModels publishes model names served by weighted splits, canaries
+	// or ordered pools of other models.
+	// Example: "team-default" -> 80% claude-sonnet-4-5, 20% gemini-2.5-pro
+	VirtualModels map[string]*VirtualModel `yaml:"virtual-models,omitempty" json:"virtual-models,omitempty"`
+
+	// Hedging re-sends slow streaming requests to a second provider or auth.
+	Hedging HedgingConfig `yaml:"hedging,omitempty" json:"hedging,omitempty"`
+
+	// Admission queues requests while every credential for a model is
+	// cooling down.
+	Admission AdmissionConfig `yaml:"admission,omitempty" json:"admission,omitempty"`
+
+	// CacheAffinity keeps requests sharing a prompt prefix on one credential.
+	CacheAffinity CacheAffinityConfig `yaml:"cache-affinity,omitempty" json:"cache-affinity,omitempty"`
+
+	// AuthTags binds client API keys to auth tags: their requests are only
+	// served by auths carrying one of the tags.
+	// Example: "sk-work-client" -> ["work"]
+	AuthTags map[string][]string `yaml:"auth-tags,omitempty" json:"auth-tags,omitempty"`
+
+	// Hints decides which client API keys may send routing hint headers.
+	Hints RoutingHintsConfig `yaml:"hints,omitempty" json:"hints,omitempty"`
+
 	hasAliases   bool
 	hasFallbacks bool
 	hasPriority  bool
@@ -249,6 +339,8 @@ func (r *RoutingConfig) Init() {
 	r.hasAliases = len(r.Aliases) > 0
 	r.hasFallbacks = len(r.Fallbacks) > 0
 	r.hasPriority = len(r.ProviderPriority) > 0
+	r.Strategy = strings.ToLower(strings.TrimSpace(r.Strategy))
+	r.VirtualModels = normalizeVirtualModels(r.VirtualModels)
 }
 
 // ResolveModelAlias returns the canonical model name for the given input.
@@ -272,6 +364,27 @@ func (r *RoutingConfig) GetFallbackChain(model string) []string {
 	return r.Fallbacks[model]
 }
 
+// VirtualModel returns the virtual model published as name, or nil.
+func (r *RoutingConfig) VirtualModel(name string) *VirtualModel {
+	if r == nil {
+		return nil
+	}
+	return r.VirtualModels[name]
+}
+
+// VirtualModelNames returns the names of all virtual models, sorted.
+func (r *RoutingConfig) VirtualModelNames() []string {
+	if r == nil || len(r.VirtualModels) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(r.VirtualModels))
+	for name := range r.VirtualModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // HasProviderPriority returns true if provider priority is configured.
 func (r *RoutingConfig) HasProviderPriority() bool {
 	return r != nil && r.hasPriority
@@ -371,6 +484,17 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 
 	// Normalize OAuth provider model exclusion map.
 	cfg.OAuthExcludedModels = NormalizeOAuthExcludedModels(cfg.OAuthExcludedModels)
+	cfg.APIKeyLimits = NormalizeAPIKeyLimits(cfg.APIKeyLimits)
+	cfg.VirtualKeys = normalizeVirtualKeys(cfg.VirtualKeys)
+	if cfg.Quota, err = cfg.Quota.Normalize(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	for key, tags := range cfg.Routing.AuthTags {
+		cfg.Routing.AuthTags[key] = NormalizeAuthTags(tags)
+	}
 
 	cfg.Routing.Init()
 
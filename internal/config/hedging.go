@@ -0,0 +1,45 @@
+package config
+
+import "time"
+
+// Hedging defaults.
+const (
+	DefaultHedgePercentile = 95
+	DefaultHedgeMinDelay   = 500 * time.Millisecond
+	DefaultHedgeMaxDelay   = 10 * time.Second
+)
+
+// HedgingConfig enables hedged streaming requests. When the first chunk of a
+// stream has not arrived within Percentile of the recent time-to-first-token
+// for the model, the request is also sent to the next provider or auth and
+// whichever answers first is kept.
+type HedgingConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Percentile of recent time-to-first-token to wait for (1-99, default 95).
+	Percentile float64 `yaml:"percentile,omitempty" json:"percentile,omitempty"`
+	// MinDelay and MaxDelay bound the wait, e.g. "500ms" and "10s". MaxDelay
+	// is also used while there are too few samples for a percentile.
+	MinDelay string `yaml:"min-delay,omitempty" json:"min-delay,omitempty"`
+	MaxDelay string `yaml:"max-delay,omitempty" json:"max-delay,omitempty"`
+}
+
+// Delays returns MinDelay and MaxDelay, substituting defaults for empty or
+// invalid values.
+func (h HedgingConfig) Delays() (minDelay, maxDelay time.Duration) {
+	minDelay, maxDelay = DefaultHedgeMinDelay, DefaultHedgeMaxDelay
+	if d, err := time.ParseDuration(h.MinDelay); err == nil && d > 0 {
+		minDelay = d
+	}
+	if d, err := time.ParseDuration(h.MaxDelay); err == nil && d > 0 {
+		maxDelay = d
+	}
+	return minDelay, max(minDelay, maxDelay)
+}
+
+// EffectivePercentile returns Percentile, or the default when it is out of range.
+func (h HedgingConfig) EffectivePercentile() float64 {
+	if h.Percentile <= 0 || h.Percentile >= 100 {
+		return DefaultHedgePercentile
+	}
+	return h.Percentile
+}
@@ -0,0 +1,44 @@
+package config
+
+import "strings"
+
+// RoutingStrategyCost selects the cheapest healthy provider and auth for a
+// model: subscription accounts first, paid API keys only while those are
+// cooling down.
+const RoutingStrategyCost = "cost"
+
+// PricingConfig is the per-model price catalog used by cost-aware routing.
+type PricingConfig struct {
+	// Models maps model IDs to their prices. A provider-specific model ID is
+	// looked up first, then the canonical one.
+	Models map[string]ModelPrice `yaml:"models,omitempty" json:"models,omitempty"`
+}
+
+// ModelPrice holds token prices in USD per million tokens.
+type ModelPrice struct {
+	Input       float64 `yaml:"input" json:"input"`
+	Output      float64 `yaml:"output" json:"output"`
+	CachedInput float64 `yaml:"cached-input,omitempty" json:"cached-input,omitempty"`
+}
+
+// Blended returns a single comparable price per million tokens, assuming the
+// 3:1 input to output mix typical of chat traffic.
+func (p ModelPrice) Blended() float64 {
+	return (3*p.Input + p.Output) / 4
+}
+
+// Price returns the price of model, matching case-insensitively.
+func (p PricingConfig) Price(model string) (ModelPrice, bool) {
+	if len(p.Models) == 0 {
+		return ModelPrice{}, false
+	}
+	if price, ok := p.Models[model]; ok {
+		return price, true
+	}
+	for name, price := range p.Models {
+		if strings.EqualFold(name, model) {
+			return price, true
+		}
+	}
+	return ModelPrice{}, false
+}
@@ -60,6 +60,14 @@ type Provider struct {
 
 	// ExcludedModels lists model names to exclude from this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Subscription marks a flat-rate plan. Cost-aware routing treats its keys
+	// like OAuth subscription accounts, which cost nothing per request.
+	Subscription bool `yaml:"subscription,omitempty" json:"subscription,omitempty"`
+
+	// Tags label this provider's keys for tag-based routing, like the "tags"
+	// field of an auth file.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
 }
 
 // ProviderAPIKey represents an API key with optional per-key settings.
@@ -79,6 +87,10 @@ type ProviderModel struct {
 	// Alias is an optional alternative name for this model.
 	// If set, both Name and Alias can be used to reference this model.
 	Alias string `yaml:"alias,omitempty" json:"alias,omitempty"`
+
+	// Type optionally marks the model kind. Set to "embedding" for models that
+	// serve /v1/embeddings instead of chat completions.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
 }
 
 // IsEnabled returns true if the provider is enabled (default: true).
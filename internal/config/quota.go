@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Quota types accepted by QuotaOverride.Type.
+const (
+	QuotaTypeRequests = "requests"
+	QuotaTypeTokens   = "tokens"
+)
+
+// QuotaConfig overrides the built-in quota model used to spread load across
+// the auths of a provider. Auth entries apply on top of their provider's.
+type QuotaConfig struct {
+	// Providers overrides the quota model of a provider, keyed by provider.
+	Providers map[string]QuotaOverride `yaml:"providers,omitempty" json:"providers,omitempty"`
+	// Auths overrides the quota model of one auth, keyed by auth ID.
+	Auths map[string]QuotaOverride `yaml:"auths,omitempty" json:"auths,omitempty"`
+}
+
+// QuotaOverride replaces fields of a quota model; empty fields keep the value
+// they override.
+type QuotaOverride struct {
+	// Window is the period the limit applies to, e.g. "5h" or "24h".
+	Window string `yaml:"window,omitempty" json:"window,omitempty"`
+	// Type is what the limit counts: "requests" or "tokens".
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Limit is the estimated requests or tokens per window, used until the
+	// real limit has been learned from a quota error.
+	Limit int64 `yaml:"limit,omitempty" json:"limit,omitempty"`
+	// StaggerBucket spreads the windows of the auths apart, e.g. "30m".
+	StaggerBucket string `yaml:"stagger-bucket,omitempty" json:"stagger-bucket,omitempty"`
+	// Sticky keeps a model on one auth until it hits its quota.
+	Sticky *bool `yaml:"sticky,omitempty" json:"sticky,omitempty"`
+	// RefuseOverage refuses premium models on GitHub Copilot accounts whose
+	// monthly premium requests are used up, instead of paying for overage.
+	RefuseOverage *bool `yaml:"refuse-overage,omitempty" json:"refuse-overage,omitempty"`
+}
+
+// IsZero reports whether o overrides nothing.
+func (o QuotaOverride) IsZero() bool {
+	return o.Window == "" && o.Type == "" && o.Limit == 0 && o.StaggerBucket == "" && o.Sticky == nil &&
+		o.RefuseOverage == nil
+}
+
+// Validate reports a field that cannot be parsed.
+func (o QuotaOverride) Validate() error {
+	for name, value := range map[string]string{"window": o.Window, "stagger-bucket": o.StaggerBucket} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("invalid %s %q", name, value)
+		}
+	}
+	if o.Type != "" && o.Type != QuotaTypeRequests && o.Type != QuotaTypeTokens {
+		return fmt.Errorf("invalid type %q, want %s or %s", o.Type, QuotaTypeRequests, QuotaTypeTokens)
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("invalid limit %d", o.Limit)
+	}
+	return nil
+}
+
+// WindowDuration returns the parsed Window, or 0 when it is unset.
+func (o QuotaOverride) WindowDuration() time.Duration {
+	d, _ := time.ParseDuration(o.Window)
+	return d
+}
+
+// StaggerDuration returns the parsed StaggerBucket, or 0 when it is unset.
+func (o QuotaOverride) StaggerDuration() time.Duration {
+	d, _ := time.ParseDuration(o.StaggerBucket)
+	return d
+}
+
+// Normalize lowercases provider names and the quota type, trims auth IDs and
+// drops entries that override nothing. It fails on the first entry that does
+// not validate.
+func (q QuotaConfig) Normalize() (QuotaConfig, error) {
+	providers, err := normalizeQuotaOverrides(q.Providers, true)
+	if err != nil {
+		return QuotaConfig{}, fmt.Errorf("quota provider %w", err)
+	}
+	auths, err := normalizeQuotaOverrides(q.Auths, false)
+	if err != nil {
+		return QuotaConfig{}, fmt.Errorf("quota auth %w", err)
+	}
+	return QuotaConfig{Providers: providers, Auths: auths}, nil
+}
+
+func normalizeQuotaOverrides(entries map[string]QuotaOverride, lower bool) (map[string]QuotaOverride, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]QuotaOverride, len(entries))
+	for key, override := range entries {
+		key = strings.TrimSpace(key)
+		if lower {
+			key = strings.ToLower(key)
+		}
+		override.Window = strings.TrimSpace(override.Window)
+		override.StaggerBucket = strings.TrimSpace(override.StaggerBucket)
+		override.Type = strings.ToLower(strings.TrimSpace(override.Type))
+		if key == "" || override.IsZero() {
+			continue
+		}
+		if err := override.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		out[key] = override
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
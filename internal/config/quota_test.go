@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestQuotaConfigNormalize(t *testing.T) {
+	refuse := true
+	quota, err := QuotaConfig{
+		Providers: map[string]QuotaOverride{" Claude ": {Limit: 2_000_000}, "qwen": {}, "github-copilot": {RefuseOverage: &refuse}},
+		Auths:     map[string]QuotaOverride{"kiro-a.json": {Window: "24h", Type: "Requests", Limit: 1000}},
+	}.Normalize()
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if len(quota.Providers) != 2 || quota.Providers["claude"].Limit != 2_000_000 || quota.Providers["github-copilot"].RefuseOverage == nil {
+		t.Errorf("providers = %v, want claude and github-copilot", quota.Providers)
+	}
+	if auth := quota.Auths["kiro-a.json"]; auth.Type != QuotaTypeRequests || auth.WindowDuration().Hours() != 24 {
+		t.Errorf("auth override = %+v", auth)
+	}
+
+	for _, bad := range []QuotaOverride{{Window: "daily"}, {Type: "credits"}, {Limit: -1}, {StaggerBucket: "-5m"}} {
+		if _, err := (QuotaConfig{Providers: map[string]QuotaOverride{"claude": bad}}).Normalize(); err == nil {
+			t.Errorf("Normalize(%+v) succeeded, want an error", bad)
+		}
+	}
+}
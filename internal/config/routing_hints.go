@@ -0,0 +1,69 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Routing hint headers let a client override routing for one request.
+const (
+	ProviderHintHeader   = "X-LLM-Mux-Provider"
+	AuthHintHeader       = "X-LLM-Mux-Auth"
+	NoFallbackHintHeader = "X-LLM-Mux-No-Fallback"
+	TimeoutHintHeader    = "X-LLM-Mux-Timeout"
+)
+
+// Routing hint names, as listed in RoutingHintsConfig.
+const (
+	HintProvider   = "provider"
+	HintAuth       = "auth"
+	HintNoFallback = "no-fallback"
+	HintTimeout    = "timeout"
+)
+
+// RoutingHintsConfig decides which client API keys may send routing hint
+// headers. A request carrying a hint its key may not use is rejected with 403,
+// so hints never apply silently to some keys and not others.
+type RoutingHintsConfig struct {
+	// Allow lists the hints every key may use; "*" allows all of them.
+	Allow []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+	// APIKeys lists the hints of individual keys, replacing Allow for them.
+	// An empty list denies every hint. Virtual keys are listed by ID.
+	APIKeys map[string][]string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+	// MaxTimeout caps the timeout hint, e.g. "10m". Empty means no cap.
+	MaxTimeout string `yaml:"max-timeout,omitempty" json:"max-timeout,omitempty"`
+}
+
+// Allows reports whether apiKey may use hint.
+func (r RoutingHintsConfig) Allows(apiKey, hint string) bool {
+	allowed, ok := r.APIKeys[apiKey]
+	if !ok || apiKey == "" {
+		allowed = r.Allow
+	}
+	for _, h := range allowed {
+		if h = strings.ToLower(strings.TrimSpace(h)); h == "*" || h == hint {
+			return true
+		}
+	}
+	return false
+}
+
+// ClampTimeout returns d capped at MaxTimeout.
+func (r RoutingHintsConfig) ClampTimeout(d time.Duration) time.Duration {
+	if limit, err := time.ParseDuration(r.MaxTimeout); err == nil && limit > 0 {
+		return min(d, limit)
+	}
+	return d
+}
+
+// ParseHintList splits a comma-separated hint header into lowercased values.
+func ParseHintList(header string) []string {
+	var values []string
+	for _, v := range strings.Split(header, ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" && !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
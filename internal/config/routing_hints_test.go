@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoutingHintsAllows(t *testing.T) {
+	hints := RoutingHintsConfig{
+		Allow:   []string{HintTimeout},
+		APIKeys: map[string][]string{"sk-ops": {"*"}, "vk-batch": {HintNoFallback}, "sk-none": {}},
+	}
+	cases := []struct {
+		key, hint string
+		want      bool
+	}{
+		{"sk-any", HintTimeout, true},
+		{"sk-any", HintProvider, false},
+		{"sk-ops", HintAuth, true},
+		{"vk-batch", HintNoFallback, true},
+		{"vk-batch", HintTimeout, false},
+		{"sk-none", HintTimeout, false},
+	}
+	for _, tc := range cases {
+		if got := hints.Allows(tc.key, tc.hint); got != tc.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tc.key, tc.hint, got, tc.want)
+		}
+	}
+
+	hints.MaxTimeout = "1m"
+	if got := hints.ClampTimeout(5 * time.Minute); got != time.Minute {
+		t.Errorf("ClampTimeout = %v, want 1m", got)
+	}
+}
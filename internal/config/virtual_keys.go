@@ -0,0 +1,108 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const (
+	// AccessProviderTypeVirtualKey is the built-in provider validating virtual keys.
+	AccessProviderTypeVirtualKey = "virtual-key"
+
+	// VirtualKeyAccessProviderName names the virtual key provider instance.
+	VirtualKeyAccessProviderName = "virtual-keys"
+
+	// VirtualKeyPrefix starts every generated virtual key.
+	VirtualKeyPrefix = "sk-mux-"
+)
+
+// VirtualKey is a managed client API key with a scope. Only the SHA-256 hash of
+// the key is stored. Requests made with it are recorded under ID, which is
+// also the name to use for it in api-key-limits.
+type VirtualKey struct {
+	ID    string `yaml:"id" json:"id"`
+	Name  string `yaml:"name,omitempty" json:"name,omitempty"`
+	Owner string `yaml:"owner,omitempty" json:"owner,omitempty"`
+	// KeyHash is the hex SHA-256 of the key.
+	KeyHash string `yaml:"key-hash" json:"-"`
+	// KeyHint is the end of the key, to help tell keys apart.
+	KeyHint string `yaml:"key-hint,omitempty" json:"key-hint,omitempty"`
+	// Models lists the model name patterns the key may request ("*" wildcards).
+	// Empty allows every model.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+	// Providers and AuthLabels restrict the credentials serving the key's
+	// requests. Empty allows all.
+	Providers  []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+	AuthLabels []string `yaml:"auth-labels,omitempty" json:"auth-labels,omitempty"`
+	// AuthTags restricts the key to auths carrying one of the tags.
+	AuthTags []string `yaml:"auth-tags,omitempty" json:"auth-tags,omitempty"`
+	// Metadata is attached to the usage records of the key's requests.
+	Metadata  map[string]string `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	CreatedAt time.Time         `yaml:"created-at" json:"created-at"`
+	ExpiresAt *time.Time        `yaml:"expires-at,omitempty" json:"expires-at,omitempty"`
+	RevokedAt *time.Time        `yaml:"revoked-at,omitempty" json:"revoked-at,omitempty"`
+}
+
+// HashVirtualKey returns the stored form of a virtual key.
+func HashVirtualKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Active reports whether the key may be used at now: it is neither revoked
+// nor expired.
+func (k *VirtualKey) Active(now time.Time) bool {
+	if k == nil || k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// VirtualKey returns the virtual key with the given ID, or nil.
+func (c *SDKConfig) VirtualKey(id string) *VirtualKey {
+	if c == nil || id == "" {
+		return nil
+	}
+	for i := range c.VirtualKeys {
+		if c.VirtualKeys[i].ID == id {
+			return &c.VirtualKeys[i]
+		}
+	}
+	return nil
+}
+
+// MakeVirtualKeyProvider constructs the access provider configuration that
+// validates keys. It returns nil when there are none.
+func MakeVirtualKeyProvider(keys []VirtualKey) *AccessProvider {
+	if len(keys) == 0 {
+		return nil
+	}
+	return &AccessProvider{
+		Name: VirtualKeyAccessProviderName,
+		Type: AccessProviderTypeVirtualKey,
+	}
+}
+
+// normalizeVirtualKeys trims IDs, hashes and provider names, and drops keys
+// without an ID or hash.
+func normalizeVirtualKeys(keys []VirtualKey) []VirtualKey {
+	out := keys[:0]
+	for _, k := range keys {
+		k.ID = strings.TrimSpace(k.ID)
+		k.KeyHash = strings.ToLower(strings.TrimSpace(k.KeyHash))
+		if k.ID == "" || k.KeyHash == "" {
+			continue
+		}
+		for i, p := range k.Providers {
+			k.Providers[i] = strings.ToLower(strings.TrimSpace(p))
+		}
+		k.AuthTags = NormalizeAuthTags(k.AuthTags)
+		out = append(out, k)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+)
+
+// Virtual model strategies.
+const (
+	// VirtualStrategyWeighted splits traffic across targets by weight.
+	VirtualStrategyWeighted = "weighted"
+	// VirtualStrategyOrdered always tries targets in the listed order.
+	VirtualStrategyOrdered = "ordered"
+)
+
+// VirtualModel publishes a model name that is served by other models.
+//
+// With the weighted strategy each request picks one target in proportion to
+// the weights; with the ordered strategy the targets form a pool tried in
+// order. Either way the remaining targets act as fallbacks when the chosen one
+// fails. A canary, when set, receives Percent of the requests ahead of the
+// regular targets.
+type VirtualModel struct {
+	// Strategy is "weighted" (default) or "ordered".
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+	// Targets lists the models serving this virtual model.
+	Targets []VirtualModelTarget `yaml:"targets" json:"targets"`
+	// Canary optionally diverts a percentage of requests to another model.
+	Canary *VirtualModelCanary `yaml:"canary,omitempty" json:"canary,omitempty"`
+	// Sticky keeps a client on the same target: "api-key" or "header:<Name>".
+	// Empty picks independently for every request.
+	Sticky string `yaml:"sticky,omitempty" json:"sticky,omitempty"`
+}
+
+// VirtualModelTarget is one model behind a virtual model.
+type VirtualModelTarget struct {
+	Model string `yaml:"model" json:"model"`
+	// Weight is the share of traffic under the weighted strategy. Targets
+	// without a weight count as 1.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+	// Provider pins the target to a single provider, e.g. "claude".
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+}
+
+// VirtualModelCanary routes Percent (0-100) of a virtual model's requests to
+// a target before the regular selection.
+type VirtualModelCanary struct {
+	VirtualModelTarget `yaml:",inline" json:",inline"`
+	Percent            float64 `yaml:"percent" json:"percent"`
+}
+
+// StickyHeader returns the request header named by Sticky, if any.
+func (v *VirtualModel) StickyHeader() string {
+	if name, ok := strings.CutPrefix(v.Sticky, "header:"); ok {
+		return strings.TrimSpace(name)
+	}
+	return ""
+}
+
+// Plan returns the targets to try for one request of the virtual model name,
+// in order. A non-empty stickyKey makes the choice deterministic for that key;
+// raising a canary's percentage only moves further keys onto it.
+func (v *VirtualModel) Plan(name, stickyKey string) []VirtualModelTarget {
+	if v == nil || len(v.Targets) == 0 {
+		return nil
+	}
+	var roll uint64
+	if stickyKey != "" {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(name))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(stickyKey))
+		roll = h.Sum64()
+	} else {
+		roll = rand.Uint64()
+	}
+
+	plan := make([]VirtualModelTarget, 0, len(v.Targets)+1)
+	if c := v.Canary; c != nil && c.Model != "" && float64(roll%10000) < c.Percent*100 {
+		plan = append(plan, c.VirtualModelTarget)
+	}
+	roll /= 10000
+
+	first := 0
+	if v.Strategy != VirtualStrategyOrdered {
+		total := 0
+		for _, t := range v.Targets {
+			total += t.effectiveWeight()
+		}
+		pick := int(roll % uint64(total))
+		for i, t := range v.Targets {
+			if pick < t.effectiveWeight() {
+				first = i
+				break
+			}
+			pick -= t.effectiveWeight()
+		}
+	}
+	plan = append(plan, v.Targets[first])
+	for i, t := range v.Targets {
+		if i != first {
+			plan = append(plan, t)
+		}
+	}
+	return plan
+}
+
+func (t VirtualModelTarget) effectiveWeight() int {
+	if t.Weight <= 0 {
+		return 1
+	}
+	return t.Weight
+}
+
+// normalizeVirtualModels trims names and drops targets without a model and
+// virtual models without targets.
+func normalizeVirtualModels(models map[string]*VirtualModel) map[string]*VirtualModel {
+	if len(models) == 0 {
+		return nil
+	}
+	out := make(map[string]*VirtualModel, len(models))
+	for name, vm := range models {
+		name = strings.TrimSpace(name)
+		if name == "" || vm == nil {
+			continue
+		}
+		targets := make([]VirtualModelTarget, 0, len(vm.Targets))
+		for _, t := range vm.Targets {
+			t.Model = strings.TrimSpace(t.Model)
+			t.Provider = strings.ToLower(strings.TrimSpace(t.Provider))
+			if t.Model != "" {
+				targets = append(targets, t)
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+		vm.Targets = targets
+		vm.Strategy = strings.ToLower(strings.TrimSpace(vm.Strategy))
+		if vm.Strategy != VirtualStrategyOrdered {
+			vm.Strategy = VirtualStrategyWeighted
+		}
+		vm.Sticky = strings.TrimSpace(vm.Sticky)
+		if c := vm.Canary; c != nil {
+			c.Model = strings.TrimSpace(c.Model)
+			c.Provider = strings.ToLower(strings.TrimSpace(c.Provider))
+			c.Percent = min(max(c.Percent, 0), 100)
+		}
+		out[name] = vm
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestVirtualModelPlan(t *testing.T) {
+	var r RoutingConfig
+	err := yaml.Unmarshal([]byte(`
+virtual-models:
+  team-default:
+    sticky: api-key
+    targets:
+      - model: claude-sonnet-4-5
+        weight: 80
+        provider: Claude
+      - model: gemini-2.5-pro
+        weight: 20
+    canary:
+      model: claude-opus-4-5
+      percent: 10
+  pool:
+    strategy: ordered
+    targets:
+      - model: a
+      - model: ""
+      - model: b
+`), &r)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	r.Init()
+
+	if names := r.VirtualModelNames(); len(names) != 2 || names[0] != "pool" {
+		t.Fatalf("VirtualModelNames = %v", names)
+	}
+
+	pool := r.VirtualModel("pool")
+	for range 20 {
+		plan := pool.Plan("pool", "")
+		if len(plan) != 2 || plan[0].Model != "a" || plan[1].Model != "b" {
+			t.Fatalf("ordered plan = %+v", plan)
+		}
+	}
+
+	vm := r.VirtualModel("team-default")
+	counts := map[string]int{}
+	for i := range 10000 {
+		key := fmt.Sprintf("key-%d", i)
+		plan := vm.Plan("team-default", key)
+		again := vm.Plan("team-default", key)
+		if plan[0] != again[0] {
+			t.Fatalf("sticky plan for %s changed: %+v then %+v", key, plan[0], again[0])
+		}
+		counts[plan[0].Model]++
+		if plan[0].Model == "claude-sonnet-4-5" && plan[0].Provider != "claude" {
+			t.Fatalf("provider pin not kept: %+v", plan[0])
+		}
+	}
+	within := func(model string, want int) {
+		if got := counts[model]; got < want-300 || got > want+300 {
+			t.Errorf("%s chosen %d times, want about %d", model, got, want)
+		}
+	}
+	within("claude-opus-4-5", 1000)
+	within("claude-sonnet-4-5", 7200)
+	within("gemini-2.5-pro", 1800)
+
+	// Raising the canary share only moves more keys onto the canary.
+	for i := range 1000 {
+		key := fmt.Sprintf("key-%d", i)
+		vm.Canary.Percent = 10
+		before := vm.Plan("team-default", key)[0].Model
+		vm.Canary.Percent = 30
+		if before == "claude-opus-4-5" && vm.Plan("team-default", key)[0].Model != before {
+			t.Fatalf("key %s left the canary when its share grew", key)
+		}
+	}
+}
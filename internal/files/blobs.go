@@ -0,0 +1,150 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/nghyane/llm-mux/internal/store"
+)
+
+// Blobs holds file contents keyed by their SHA-256 digest. Files with the
+// same contents share one blob.
+type Blobs interface {
+	// Put stores the size bytes of r under key unless key already exists.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Open returns the contents stored under key, or ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// diskBlobs stores blobs as files in a local directory.
+type diskBlobs struct {
+	dir string
+}
+
+// NewDiskBlobs returns blob storage under dir.
+func NewDiskBlobs(dir string) (Blobs, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create files directory: %w", err)
+	}
+	return &diskBlobs{dir: dir}, nil
+}
+
+func (d *diskBlobs) path(key string) string {
+	return filepath.Join(d.dir, filepath.Base(key))
+}
+
+func (d *diskBlobs) Put(_ context.Context, key string, r io.Reader, _ int64) error {
+	if _, err := os.Stat(d.path(key)); err == nil {
+		return nil
+	}
+	tmp, err := os.CreateTemp(d.dir, ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, err = io.Copy(tmp, r)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(key))
+}
+
+func (d *diskBlobs) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (d *diskBlobs) Delete(_ context.Context, key string) error {
+	if err := os.Remove(d.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// objectBlobs stores blobs in the S3-compatible bucket of the object token store.
+type objectBlobs struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewObjectBlobs returns blob storage under "files/" in the bucket of cfg.
+func NewObjectBlobs(cfg store.ObjectStoreConfig) (Blobs, error) {
+	options := &minio.Options{
+		Creds:  credentials.NewStaticV4(strings.TrimSpace(cfg.AccessKey), strings.TrimSpace(cfg.SecretKey), ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	}
+	if cfg.PathStyle {
+		options.BucketLookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(strings.TrimSpace(cfg.Endpoint), options)
+	if err != nil {
+		return nil, fmt.Errorf("files: create object store client: %w", err)
+	}
+	prefix := "files/"
+	if p := strings.Trim(cfg.Prefix, "/"); p != "" {
+		prefix = p + "/" + prefix
+	}
+	return &objectBlobs{client: client, bucket: strings.TrimSpace(cfg.Bucket), prefix: prefix}, nil
+}
+
+func (o *objectBlobs) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if _, err := o.client.StatObject(ctx, o.bucket, o.prefix+key, minio.StatObjectOptions{}); err == nil {
+		return nil
+	}
+	_, err := o.client.PutObject(ctx, o.bucket, o.prefix+key, r, size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	if err != nil {
+		return fmt.Errorf("files: put object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (o *objectBlobs) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	// GetObject is lazy; Stat surfaces a missing object before the caller reads.
+	obj, err := o.client.GetObject(ctx, o.bucket, o.prefix+key, minio.GetObjectOptions{})
+	if err == nil {
+		_, err = obj.Stat()
+	}
+	if err != nil {
+		if obj != nil {
+			_ = obj.Close()
+		}
+		if isObjectNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (o *objectBlobs) Delete(ctx context.Context, key string) error {
+	err := o.client.RemoveObject(ctx, o.bucket, o.prefix+key, minio.RemoveObjectOptions{})
+	if err != nil && !isObjectNotFound(err) {
+		return fmt.Errorf("files: delete object %s: %w", key, err)
+	}
+	return nil
+}
+
+func isObjectNotFound(err error) bool {
+	resp := minio.ToErrorResponse(err)
+	return resp.StatusCode == http.StatusNotFound || resp.Code == "NoSuchKey" || resp.Code == "NotFound"
+}
@@ -0,0 +1,99 @@
+package files
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/sqlstore"
+	"github.com/nghyane/llm-mux/internal/store"
+	"github.com/nghyane/llm-mux/internal/translator/preprocess"
+)
+
+const cleanupInterval = time.Hour
+
+var (
+	defaultMu    sync.RWMutex
+	defaultStore *Store
+	stopCleanup  chan struct{}
+)
+
+// Default returns the process-wide file store, or nil when it is not initialized.
+func Default() *Store {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultStore
+}
+
+// Initialize opens the process-wide file store from cfg, registers it to
+// resolve file references in model requests and starts expiry cleanup. File
+// contents go to the object store when storeCfg configures one, and to
+// files.dir otherwise.
+func Initialize(cfg *config.Config, storeCfg store.StoreConfig) error {
+	db, err := sqlstore.Open(sqlstore.StateDSN(cfg))
+	if err != nil || db == nil {
+		return err
+	}
+	var blobs Blobs
+	if storeCfg.Type == store.TypeObject {
+		blobs, err = NewObjectBlobs(storeCfg.Object)
+	} else {
+		dir := cfg.Files.Dir
+		if dir == "" {
+			dir = filepath.Join(config.CredentialsDir(), "files")
+		}
+		blobs, err = NewDiskBlobs(dir)
+	}
+	if err != nil {
+		return err
+	}
+	var ttl time.Duration
+	if d, errParse := time.ParseDuration(cfg.Files.TTL); errParse == nil && d > 0 {
+		ttl = d
+	}
+	s, err := NewStore(db, blobs, cfg.Files.MaxSizeBytes(), ttl)
+	if err != nil {
+		return err
+	}
+
+	Stop()
+	defaultMu.Lock()
+	defaultStore = s
+	stopCleanup = make(chan struct{})
+	go cleanupLoop(s, stopCleanup)
+	defaultMu.Unlock()
+	preprocess.SetFileResolver(s.Resolve)
+	return nil
+}
+
+// Stop halts the background cleanup loop.
+func Stop() {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if stopCleanup != nil {
+		close(stopCleanup)
+		stopCleanup = nil
+	}
+}
+
+func cleanupLoop(s *Store, stop <-chan struct{}) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if n, err := s.Cleanup(ctx, time.Now()); err != nil {
+				log.Warnf("files: cleanup failed: %v", err)
+			} else if n > 0 {
+				log.Debugf("files: removed %d expired files", n)
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package files
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// maxInlineBytes caps the size of a stored file that is inlined into a model
+// request; larger references are passed on unchanged.
+const maxInlineBytes = 32 << 20
+
+// Resolve loads file id so that a model request made by owner can carry it
+// inline. ok is false when id does not name an unexpired, non-internal file
+// of owner, which leaves provider-native file ids untouched.
+func (s *Store) Resolve(owner, id string) (data []byte, mimeType, filename string, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	f, err := s.Get(ctx, id)
+	if err != nil || f.APIKey != owner || f.Internal() || f.Bytes > maxInlineBytes {
+		return nil, "", "", false
+	}
+	r, err := s.blobs.Open(ctx, f.SHA256)
+	if err != nil {
+		return nil, "", "", false
+	}
+	defer r.Close()
+	data, err = io.ReadAll(r)
+	if err != nil {
+		return nil, "", "", false
+	}
+	return data, DetectMimeType(f.MimeType, f.Filename, data), f.Filename, true
+}
+
+// DetectMimeType returns declared unless it is empty or generic, then falls
+// back to the extension of filename and finally to sniffing data.
+func DetectMimeType(declared, filename string, data []byte) string {
+	if declared != "" && declared != "application/octet-stream" {
+		return declared
+	}
+	if t := mime.TypeByExtension(filepath.Ext(filename)); t != "" {
+		if mt, _, err := mime.ParseMediaType(t); err == nil {
+			return mt
+		}
+	}
+	if len(data) > 0 {
+		if mt, _, err := mime.ParseMediaType(http.DetectContentType(data)); err == nil {
+			return mt
+		}
+	}
+	return "application/octet-stream"
+}
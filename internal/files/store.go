@@ -0,0 +1,332 @@
+// Package files stores files uploaded through the /v1/files API. Metadata is
+// kept in the state database; contents are content-addressed by SHA-256 on
+// local disk or in the configured object store, so identical uploads share
+// storage.
+package files
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/sqlstore"
+)
+
+var (
+	// ErrNotFound is returned when a file does not exist, has expired or
+	// belongs to another API key.
+	ErrNotFound = errors.New("file not found")
+	// ErrTooLarge is returned when an upload exceeds the configured size limit.
+	ErrTooLarge = errors.New("file exceeds maximum size")
+)
+
+// PurposeMessageBatch is the purpose of the input files generated for
+// Anthropic message batches. They belong to their batch and are not exposed
+// through the /v1/files API.
+const PurposeMessageBatch = "message_batch_input"
+
+// PurposeImageGeneration is the purpose of images generated by /v1/images
+// requests with response_format "url". Their content is served without
+// authentication, so uploads cannot claim it.
+const PurposeImageGeneration = "image_generation"
+
+// File describes a stored file in the OpenAI file object format.
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	MimeType  string `json:"-"`
+	SHA256    string `json:"-"`
+	APIKey    string `json:"-"`
+}
+
+// Internal reports whether f is managed by the proxy and hidden from the
+// /v1/files API.
+func (f *File) Internal() bool {
+	return f.Purpose == PurposeMessageBatch
+}
+
+func (f *File) expired(now time.Time) bool {
+	return f.ExpiresAt != nil && now.Unix() >= *f.ExpiresAt
+}
+
+// Store persists file metadata in SQL and file contents in blobs.
+type Store struct {
+	db      *sqlstore.DB
+	blobs   Blobs
+	maxSize int64
+	ttl     time.Duration
+	// mu orders blob writes against deletions so that a blob shared by a new
+	// file is not removed with the last old file that referenced it.
+	mu sync.Mutex
+}
+
+// NewStore creates the files table if needed and returns a store that keeps
+// contents in blobs. maxSize <= 0 disables the size limit; ttl > 0 is the
+// default lifetime of uploads that do not set their own expiry.
+func NewStore(db *sqlstore.DB, blobs Blobs, maxSize int64, ttl time.Duration) (*Store, error) {
+	err := db.Migrate(
+		`CREATE TABLE IF NOT EXISTS files (
+			id TEXT PRIMARY KEY,
+			api_key TEXT NOT NULL DEFAULT '',
+			filename TEXT NOT NULL DEFAULT '',
+			purpose TEXT NOT NULL DEFAULT '',
+			mime_type TEXT NOT NULL DEFAULT '',
+			sha256 TEXT NOT NULL DEFAULT '',
+			bytes BIGINT NOT NULL,
+			created_at BIGINT NOT NULL,
+			expires_at BIGINT
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_files_api_key ON files(api_key, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_files_sha256 ON files(sha256)`,
+		`CREATE INDEX IF NOT EXISTS idx_files_expires_at ON files(expires_at)`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db, blobs: blobs, maxSize: maxSize, ttl: ttl}, nil
+}
+
+// NewID returns a random identifier with the given prefix, e.g. "file-3f2a...".
+func NewID(prefix string) string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return prefix + hex.EncodeToString(b[:])
+}
+
+// Create writes the contents of r to a new file described by meta, enforcing
+// the upload size limit. APIKey, Filename, Purpose, MimeType and ExpiresAt are
+// taken from meta, with the store's default lifetime applied when ExpiresAt is
+// nil; the stored file is returned.
+func (s *Store) Create(ctx context.Context, meta File, r io.Reader) (*File, error) {
+	if meta.ExpiresAt == nil && s.ttl > 0 {
+		exp := time.Now().Add(s.ttl).Unix()
+		meta.ExpiresAt = &exp
+	}
+	return s.create(ctx, meta, r, s.maxSize)
+}
+
+// CreateGenerated is like Create for files produced by the proxy itself, such
+// as batch output, which are not subject to the upload size limit.
+func (s *Store) CreateGenerated(ctx context.Context, meta File, r io.Reader) (*File, error) {
+	return s.create(ctx, meta, r, 0)
+}
+
+func (s *Store) create(ctx context.Context, meta File, r io.Reader, maxSize int64) (*File, error) {
+	// Contents are staged in a temporary file to learn their digest before
+	// they are stored under it.
+	tmp, err := os.CreateTemp("", "llm-mux-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	src := r
+	if maxSize > 0 {
+		src = io.LimitReader(r, maxSize+1)
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), src)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && n > maxSize {
+		return nil, ErrTooLarge
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	f := meta
+	f.ID = NewID("file-")
+	f.Object = "file"
+	f.Bytes = n
+	f.CreatedAt = time.Now().Unix()
+	f.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.blobs.Put(ctx, f.SHA256, tmp, n); err != nil {
+		return nil, err
+	}
+	_, err = s.db.ExecContext(ctx, s.db.Rebind(`
+		INSERT INTO files (id, api_key, filename, purpose, mime_type, sha256, bytes, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		f.ID, f.APIKey, f.Filename, f.Purpose, f.MimeType, f.SHA256, f.Bytes, f.CreatedAt, f.ExpiresAt)
+	if err != nil {
+		_ = s.releaseBlob(ctx, f.SHA256)
+		return nil, err
+	}
+	return &f, nil
+}
+
+const fileColumns = `id, api_key, filename, purpose, mime_type, sha256, bytes, created_at, expires_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFile(row scanner) (*File, error) {
+	f := &File{Object: "file"}
+	var expiresAt sql.NullInt64
+	if err := row.Scan(&f.ID, &f.APIKey, &f.Filename, &f.Purpose, &f.MimeType, &f.SHA256, &f.Bytes, &f.CreatedAt, &expiresAt); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		f.ExpiresAt = &expiresAt.Int64
+	}
+	return f, nil
+}
+
+// Get returns the metadata of file id.
+func (s *Store) Get(ctx context.Context, id string) (*File, error) {
+	row := s.db.QueryRowContext(ctx, s.db.Rebind(`SELECT `+fileColumns+` FROM files WHERE id = ?`), id)
+	f, err := scanFile(row)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && f.expired(time.Now())) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Open returns a reader for the contents of file id.
+func (s *Store) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	f, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.blobs.Open(ctx, f.SHA256)
+}
+
+// Delete removes file id, and its contents unless another file shares them.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var digest string
+	err := s.db.QueryRowContext(ctx, s.db.Rebind(`SELECT sha256 FROM files WHERE id = ?`), id).Scan(&digest)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, s.db.Rebind(`DELETE FROM files WHERE id = ?`), id); err != nil {
+		return err
+	}
+	return s.releaseBlob(ctx, digest)
+}
+
+// releaseBlob deletes blob digest once no file refers to it. s.mu must be held.
+func (s *Store) releaseBlob(ctx context.Context, digest string) error {
+	var refs int
+	err := s.db.QueryRowContext(ctx, s.db.Rebind(`SELECT COUNT(*) FROM files WHERE sha256 = ?`), digest).Scan(&refs)
+	if err != nil || refs > 0 {
+		return err
+	}
+	return s.blobs.Delete(ctx, digest)
+}
+
+// Cleanup deletes files that expired before now and returns how many were removed.
+func (s *Store) Cleanup(ctx context.Context, now time.Time) (int64, error) {
+	rows, err := s.db.QueryContext(ctx, s.db.Rebind(`SELECT id FROM files WHERE expires_at IS NOT NULL AND expires_at <= ?`), now.Unix())
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	var n int64
+	for _, id := range ids {
+		if err := s.Delete(ctx, id); err != nil && !errors.Is(err, ErrNotFound) {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// ListOptions selects a page of files for List.
+type ListOptions struct {
+	APIKey string
+	// Purpose restricts the listing to one purpose; empty matches every file.
+	Purpose string
+	// After returns files older than this id; Before returns newer ones.
+	After  string
+	Before string
+	Limit  int
+}
+
+// List returns up to opts.Limit unexpired files, newest first. Internal files
+// are never listed. The boolean reports whether more files follow in the
+// direction of the page.
+func (s *Store) List(ctx context.Context, opts ListOptions) ([]*File, bool, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE api_key = ? AND purpose <> ? AND (expires_at IS NULL OR expires_at > ?)`
+	args := []any{opts.APIKey, PurposeMessageBatch, time.Now().Unix()}
+	if opts.Purpose != "" {
+		query += ` AND purpose = ?`
+		args = append(args, opts.Purpose)
+	}
+	order := `DESC`
+	switch {
+	case opts.After != "":
+		query += ` AND (created_at, id) < (SELECT created_at, id FROM files WHERE id = ?)`
+		args = append(args, opts.After)
+	case opts.Before != "":
+		query += ` AND (created_at, id) > (SELECT created_at, id FROM files WHERE id = ?)`
+		args = append(args, opts.Before)
+		order = `ASC`
+	}
+	query += ` ORDER BY created_at ` + order + `, id ` + order + ` LIMIT ?`
+	args = append(args, opts.Limit+1)
+
+	rows, err := s.db.QueryContext(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var out []*File
+	for rows.Next() {
+		f, err := scanFile(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		out = append(out, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	hasMore := len(out) > opts.Limit
+	if hasMore {
+		out = out[:opts.Limit]
+	}
+	if order == `ASC` {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+	return out, hasMore, nil
+}
@@ -0,0 +1,240 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/sqlstore"
+)
+
+func newTestStore(t *testing.T, maxSize int64) (*Store, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := sqlstore.OpenSQLite(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	blobDir := filepath.Join(dir, "files")
+	blobs, err := NewDiskBlobs(blobDir)
+	if err != nil {
+		t.Fatalf("NewDiskBlobs: %v", err)
+	}
+	s, err := NewStore(db, blobs, maxSize, 0)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	return s, blobDir
+}
+
+func countBlobs(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read blob dir: %v", err)
+	}
+	return len(entries)
+}
+
+func TestStoreCreateOpenDelete(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestStore(t, 0)
+
+	f, err := s.Create(ctx, File{APIKey: "key-a", Filename: "a.jsonl", Purpose: "batch"}, strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if f.Bytes != 5 || f.APIKey != "key-a" || !strings.HasPrefix(f.ID, "file-") || f.ExpiresAt != nil {
+		t.Fatalf("unexpected file %+v", f)
+	}
+	r, err := s.Open(ctx, f.ID)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(data) != "hello" {
+		t.Fatalf("contents = %q", data)
+	}
+
+	if err := s.Delete(ctx, f.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, f.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after delete: %v", err)
+	}
+	if _, err := s.Open(ctx, f.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after delete: %v", err)
+	}
+}
+
+func TestStoreSharesIdenticalContents(t *testing.T) {
+	ctx := context.Background()
+	s, blobDir := newTestStore(t, 0)
+
+	a, err := s.Create(ctx, File{APIKey: "key-a", Filename: "a.txt"}, strings.NewReader("same"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	b, err := s.Create(ctx, File{APIKey: "key-b", Filename: "b.txt"}, strings.NewReader("same"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if a.ID == b.ID || a.SHA256 != b.SHA256 {
+		t.Fatalf("files %+v and %+v should be distinct with one digest", a, b)
+	}
+	if n := countBlobs(t, blobDir); n != 1 {
+		t.Fatalf("%d blobs stored, want 1", n)
+	}
+
+	if err := s.Delete(ctx, a.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if r, err := s.Open(ctx, b.ID); err != nil {
+		t.Fatalf("shared contents removed with the first file: %v", err)
+	} else {
+		_ = r.Close()
+	}
+	if err := s.Delete(ctx, b.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if n := countBlobs(t, blobDir); n != 0 {
+		t.Errorf("%d blobs left after deleting every file", n)
+	}
+}
+
+func TestStoreExpiresFiles(t *testing.T) {
+	ctx := context.Background()
+	s, blobDir := newTestStore(t, 0)
+
+	past := time.Now().Add(-time.Minute).Unix()
+	f, err := s.Create(ctx, File{APIKey: "key-a", Filename: "old.txt", ExpiresAt: &past}, strings.NewReader("old"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := s.Get(ctx, f.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of expired file: %v", err)
+	}
+	if list, _, _ := s.List(ctx, ListOptions{APIKey: "key-a", Limit: 10}); len(list) != 0 {
+		t.Errorf("expired file listed")
+	}
+	if n, err := s.Cleanup(ctx, time.Now()); err != nil || n != 1 {
+		t.Fatalf("Cleanup removed %d files, err %v", n, err)
+	}
+	if n := countBlobs(t, blobDir); n != 0 {
+		t.Errorf("%d blobs left after cleanup", n)
+	}
+}
+
+func TestStoreAppliesDefaultTTLToUploads(t *testing.T) {
+	s, _ := newTestStore(t, 0)
+	s.ttl = time.Hour
+	f, err := s.Create(context.Background(), File{APIKey: "key-a"}, strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if f.ExpiresAt == nil || *f.ExpiresAt < time.Now().Add(59*time.Minute).Unix() {
+		t.Errorf("expires_at = %v, want about an hour from now", f.ExpiresAt)
+	}
+	g, err := s.CreateGenerated(context.Background(), File{APIKey: "key-a"}, strings.NewReader("y"))
+	if err != nil {
+		t.Fatalf("CreateGenerated: %v", err)
+	}
+	if g.ExpiresAt != nil {
+		t.Errorf("generated file got expires_at %d", *g.ExpiresAt)
+	}
+}
+
+func TestStoreEnforcesMaxSize(t *testing.T) {
+	s, _ := newTestStore(t, 4)
+	if _, err := s.Create(context.Background(), File{Filename: "big", Purpose: "batch"}, strings.NewReader("12345")); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if _, err := s.CreateGenerated(context.Background(), File{Filename: "big", Purpose: "batch_output"}, strings.NewReader("12345")); err != nil {
+		t.Fatalf("generated files are not size limited: %v", err)
+	}
+}
+
+func TestStoreListIsScopedToOwnerAndPages(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestStore(t, 0)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		f, err := s.Create(ctx, File{APIKey: "key-a", Filename: "f", Purpose: "batch"}, strings.NewReader("x"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		ids = append(ids, f.ID)
+	}
+	if _, err := s.Create(ctx, File{APIKey: "key-b", Filename: "other", Purpose: "batch"}, strings.NewReader("x")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	page, hasMore, err := s.List(ctx, ListOptions{APIKey: "key-a", Limit: 2})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(page) != 2 || !hasMore {
+		t.Fatalf("first page has %d files, hasMore=%v", len(page), hasMore)
+	}
+	rest, hasMore, err := s.List(ctx, ListOptions{APIKey: "key-a", After: page[1].ID, Limit: 2})
+	if err != nil {
+		t.Fatalf("List after: %v", err)
+	}
+	if len(rest) != 1 || hasMore {
+		t.Fatalf("second page has %d files, hasMore=%v", len(rest), hasMore)
+	}
+	back, _, err := s.List(ctx, ListOptions{APIKey: "key-a", Before: rest[0].ID, Limit: 10})
+	if err != nil || len(back) != 2 || back[0].ID != page[0].ID {
+		t.Fatalf("List before = %v, err %v", back, err)
+	}
+
+	seen := map[string]bool{}
+	for _, f := range append(page, rest...) {
+		if f.APIKey != "key-a" {
+			t.Errorf("listed file of another key: %+v", f)
+		}
+		seen[f.ID] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			t.Errorf("file %s missing from pages", id)
+		}
+	}
+
+	if _, err := s.CreateGenerated(ctx, File{APIKey: "key-a", Filename: "in", Purpose: PurposeMessageBatch}, strings.NewReader("x")); err != nil {
+		t.Fatalf("CreateGenerated: %v", err)
+	}
+	if all, _, _ := s.List(ctx, ListOptions{APIKey: "key-a", Limit: 10}); len(all) != 3 {
+		t.Errorf("listing has %d files, want 3 without internal files", len(all))
+	}
+	if list, _, _ := s.List(ctx, ListOptions{APIKey: "key-a", Purpose: "batch_output", Limit: 10}); len(list) != 0 {
+		t.Errorf("purpose filter returned %d files", len(list))
+	}
+}
+
+func TestStoreResolveChecksOwner(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestStore(t, 0)
+	f, err := s.Create(ctx, File{APIKey: "key-a", Filename: "doc.pdf"}, strings.NewReader("%PDF-1.4"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	data, mimeType, filename, ok := s.Resolve("key-a", f.ID)
+	if !ok || string(data) != "%PDF-1.4" || mimeType != "application/pdf" || filename != "doc.pdf" {
+		t.Errorf("Resolve = %q %q %q %v", data, mimeType, filename, ok)
+	}
+	if _, _, _, ok := s.Resolve("key-b", f.ID); ok {
+		t.Errorf("resolved a file of another key")
+	}
+	if _, _, _, ok := s.Resolve("key-a", "file_011native"); ok {
+		t.Errorf("resolved an unknown id")
+	}
+}
@@ -0,0 +1,312 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// admissionReleaseGap spaces out releases from one queue so a released request
+// can take the freed auth before the next waiter checks availability again.
+const admissionReleaseGap = 50 * time.Millisecond
+
+// admissionQueueIdleTTL is how long an empty queue, and its stats, is kept
+// after its last waiter left.
+const admissionQueueIdleTTL = 10 * time.Minute
+
+// AdmissionPolicy configures the admission queue. Requests rejected because
+// every auth for their model is cooling down wait up to MaxWait for one to
+// recover; at most MaxDepth requests (0: unlimited) wait per model.
+type AdmissionPolicy struct {
+	MaxWait  time.Duration
+	MaxDepth int
+}
+
+// AdmissionQueueStats describes one admission queue.
+type AdmissionQueueStats struct {
+	Model       string   `json:"model"`
+	Providers   []string `json:"providers"`
+	Waiting     int      `json:"waiting"`
+	Interactive int      `json:"waiting_interactive"`
+	Background  int      `json:"waiting_background"`
+	Admitted    int64    `json:"admitted"`
+	TimedOut    int64    `json:"timed_out"`
+	Rejected    int64    `json:"rejected"`
+	AvgWaitMs   int64    `json:"avg_wait_ms"`
+	MaxWaitMs   int64    `json:"max_wait_ms"`
+}
+
+// SetAdmission enables the admission queue with policy; nil disables it and
+// requests fail as soon as every auth is cooling down.
+func (m *Manager) SetAdmission(policy *AdmissionPolicy) {
+	if m == nil {
+		return
+	}
+	m.admissionPolicy.Store(policy)
+}
+
+// AdmissionStats returns the state of every admission queue used in the last
+// admissionQueueIdleTTL, sorted by model.
+func (m *Manager) AdmissionStats() []AdmissionQueueStats {
+	m.admission.mu.Lock()
+	defer m.admission.mu.Unlock()
+	m.admission.dropIdle(time.Now())
+	stats := make([]AdmissionQueueStats, 0, len(m.admission.queues))
+	for _, q := range m.admission.queues {
+		s := AdmissionQueueStats{
+			Model:       q.model,
+			Providers:   q.providers,
+			Interactive: q.classes[PriorityNormal].len(),
+			Background:  q.classes[PriorityLow].len(),
+			Admitted:    q.admitted,
+			TimedOut:    q.timedOut,
+			Rejected:    q.rejected,
+			MaxWaitMs:   q.maxWait.Milliseconds(),
+		}
+		s.Waiting = s.Interactive + s.Background
+		if q.admitted > 0 {
+			s.AvgWaitMs = (q.totalWait / time.Duration(q.admitted)).Milliseconds()
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Model != stats[j].Model {
+			return stats[i].Model < stats[j].Model
+		}
+		return strings.Join(stats[i].Providers, ",") < strings.Join(stats[j].Providers, ",")
+	})
+	return stats
+}
+
+// admissionQueues holds one queue per model and provider set.
+type admissionQueues struct {
+	mu     sync.Mutex
+	queues map[string]*admissionQueue
+}
+
+// admissionQueue orders the waiters of one model. Interactive waiters are
+// released before background ones; within a class, clients take turns and each
+// client's requests are released in arrival order.
+type admissionQueue struct {
+	model     string
+	providers []string
+	classes   [2]admissionClass // indexed by Priority
+	running   bool              // a dispatcher goroutine is active
+	idleSince time.Time         // when the dispatcher last exited
+
+	admitted, timedOut, rejected int64
+	totalWait, maxWait           time.Duration
+}
+
+// dropIdle removes the queues that have been empty for admissionQueueIdleTTL,
+// so that a queue per model name a client ever sent is not kept. a.mu must be
+// held.
+func (a *admissionQueues) dropIdle(now time.Time) {
+	for key, q := range a.queues {
+		if !q.running && !q.idleSince.IsZero() && now.Sub(q.idleSince) >= admissionQueueIdleTTL {
+			delete(a.queues, key)
+		}
+	}
+}
+
+type admissionClass struct {
+	clients map[string][]*admissionWaiter
+	order   []string // clients with waiters, in turn order
+}
+
+type admissionWaiter struct {
+	ready    chan struct{}
+	enqueued time.Time
+}
+
+func (c *admissionClass) len() int {
+	n := 0
+	for _, waiters := range c.clients {
+		n += len(waiters)
+	}
+	return n
+}
+
+func (c *admissionClass) push(client string, w *admissionWaiter) {
+	if c.clients == nil {
+		c.clients = make(map[string][]*admissionWaiter)
+	}
+	if len(c.clients[client]) == 0 {
+		c.order = append(c.order, client)
+	}
+	c.clients[client] = append(c.clients[client], w)
+}
+
+// pop removes the oldest waiter of the client whose turn it is and moves that
+// client to the back of the order.
+func (c *admissionClass) pop() *admissionWaiter {
+	if len(c.order) == 0 {
+		return nil
+	}
+	client := c.order[0]
+	c.order = c.order[1:]
+	waiters := c.clients[client]
+	w := waiters[0]
+	if len(waiters) == 1 {
+		delete(c.clients, client)
+	} else {
+		c.clients[client] = waiters[1:]
+		c.order = append(c.order, client)
+	}
+	return w
+}
+
+// remove drops w if it is still queued and reports whether it was.
+func (c *admissionClass) remove(client string, w *admissionWaiter) bool {
+	waiters := c.clients[client]
+	for i, queued := range waiters {
+		if queued != w {
+			continue
+		}
+		waiters = append(waiters[:i:i], waiters[i+1:]...)
+		if len(waiters) == 0 {
+			delete(c.clients, client)
+			for j, name := range c.order {
+				if name == client {
+					c.order = append(c.order[:j:j], c.order[j+1:]...)
+					break
+				}
+			}
+		} else {
+			c.clients[client] = waiters
+		}
+		return true
+	}
+	return false
+}
+
+// isCooldownRejection reports whether err means every auth for the model is
+// cooling down.
+func isCooldownRejection(err error) bool {
+	var cooldown *modelCooldownError
+	if errors.As(err, &cooldown) || errors.Is(err, errCooldownTimeout) {
+		return true
+	}
+	return statusCodeFromError(err) == http.StatusTooManyRequests
+}
+
+// awaitAdmission queues a request that failed with err while every auth for
+// model is cooling down. It returns true once the request should be tried
+// again and false when err should be returned: the queue is disabled or full,
+// err is of another kind, or the wait, counted from *queuedAt, ran out.
+func (m *Manager) awaitAdmission(ctx context.Context, providers []string, model string, err error, queuedAt *time.Time) bool {
+	policy := m.admissionPolicy.Load()
+	if policy == nil || singleAttempt(ctx) || !isCooldownRejection(err) {
+		return false
+	}
+	providers = m.normalizeProviders(providers)
+	if len(providers) == 0 || m.hasAvailableAuth(providers, model) {
+		return false
+	}
+
+	now := time.Now()
+	if queuedAt.IsZero() {
+		*queuedAt = now
+	}
+	deadline := queuedAt.Add(policy.MaxWait)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if !now.Before(deadline) {
+		return false
+	}
+
+	key := strings.Join(providers, ",") + "|" + model
+	class := PriorityFromContext(ctx)
+	if class != PriorityLow {
+		class = PriorityNormal
+	}
+	client := usage.APIKeyFromContext(ctx)
+	w := &admissionWaiter{ready: make(chan struct{}), enqueued: now}
+
+	a := &m.admission
+	a.mu.Lock()
+	if a.queues == nil {
+		a.queues = make(map[string]*admissionQueue)
+	}
+	a.dropIdle(now)
+	q := a.queues[key]
+	if q == nil {
+		q = &admissionQueue{model: model, providers: providers}
+		a.queues[key] = q
+	}
+	if policy.MaxDepth > 0 && q.classes[PriorityNormal].len()+q.classes[PriorityLow].len() >= policy.MaxDepth {
+		q.rejected++
+		a.mu.Unlock()
+		return false
+	}
+	q.classes[class].push(client, w)
+	if !q.running {
+		q.running = true
+		go m.dispatchAdmission(q)
+	}
+	a.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-w.ready:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !q.classes[class].remove(client, w) {
+		// Released while giving up; take the slot.
+		return ctx.Err() == nil
+	}
+	if ctx.Err() == nil {
+		q.timedOut++
+	}
+	return false
+}
+
+// dispatchAdmission releases the waiters of q one at a time whenever an auth is
+// available, and exits once q is empty.
+func (m *Manager) dispatchAdmission(q *admissionQueue) {
+	a := &m.admission
+	for {
+		available := m.hasAvailableAuth(q.providers, q.model)
+
+		a.mu.Lock()
+		if q.classes[PriorityNormal].len()+q.classes[PriorityLow].len() == 0 {
+			q.running = false
+			q.idleSince = time.Now()
+			a.mu.Unlock()
+			return
+		}
+		if available {
+			w := q.classes[PriorityNormal].pop()
+			if w == nil {
+				w = q.classes[PriorityLow].pop()
+			}
+			wait := time.Since(w.enqueued)
+			q.admitted++
+			q.totalWait += wait
+			if wait > q.maxWait {
+				q.maxWait = wait
+			}
+			close(w.ready)
+		}
+		a.mu.Unlock()
+
+		if available {
+			time.Sleep(admissionReleaseGap)
+		} else {
+			time.Sleep(cooldownPollInterval)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestAdmissionClassTakesTurnsBetweenClients(t *testing.T) {
+	var c admissionClass
+	waiters := map[*admissionWaiter]string{}
+	for _, client := range []string{"a", "a", "a", "b", "c"} {
+		w := &admissionWaiter{}
+		waiters[w] = client
+		c.push(client, w)
+	}
+	var got string
+	for w := c.pop(); w != nil; w = c.pop() {
+		got += waiters[w]
+	}
+	if got != "abcaa" {
+		t.Errorf("release order = %q, want %q", got, "abcaa")
+	}
+}
+
+// admissionTestExecutor answers every request.
+type admissionTestExecutor struct{}
+
+func (admissionTestExecutor) Identifier() string { return "admission-test" }
+
+func (admissionTestExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{Payload: []byte("ok")}, nil
+}
+
+func (admissionTestExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+
+func (admissionTestExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (admissionTestExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func TestExecuteWaitsForCoolingDownAuth(t *testing.T) {
+	const model = "admission-test-model"
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+	m.RegisterExecutor(admissionTestExecutor{})
+
+	register := func(id, model string, cooldown time.Duration) {
+		until := time.Now().Add(cooldown)
+		state := &ModelState{Status: StatusError, Unavailable: true, NextRetryAfter: until}
+		state.Quota.Exceeded = true
+		state.Quota.NextRecoverAt = until
+		_, _ = m.Register(ctx, &Auth{ID: id, Provider: "admission-test", Status: StatusActive, ModelStates: map[string]*ModelState{model: state}})
+		registry.GetGlobalRegistry().RegisterClient(id, "admission-test", []*registry.ModelInfo{{ID: model}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	register("admission-a", model, 600*time.Millisecond)
+
+	if _, err := m.Execute(ctx, []string{"admission-test"}, Request{Model: model}, Options{}); err == nil {
+		t.Fatal("expected a cooldown error without an admission queue")
+	}
+
+	m.SetAdmission(&AdmissionPolicy{MaxWait: 5 * time.Second})
+	resp, err := m.Execute(ctx, []string{"admission-test"}, Request{Model: model}, Options{})
+	if err != nil || string(resp.Payload) != "ok" {
+		t.Fatalf("Execute = %q, %v; want the request admitted once the cooldown ends", resp.Payload, err)
+	}
+	stats := m.AdmissionStats()
+	if len(stats) != 1 || stats[0].Admitted != 1 || stats[0].Waiting != 0 {
+		t.Errorf("admission stats = %+v", stats)
+	}
+
+	// The client's deadline cuts the wait short.
+	register("admission-b", "admission-slow-model", time.Hour)
+	shortCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = m.Execute(shortCtx, []string{"admission-test"}, Request{Model: "admission-slow-model"}, Options{})
+	if !isCooldownRejection(err) || time.Since(start) > 2*time.Second {
+		t.Errorf("Execute = %v after %v; want an error by the client deadline", err, time.Since(start))
+	}
+}
+
+func TestAdmissionQueuesDropIdle(t *testing.T) {
+	now := time.Now()
+	a := admissionQueues{queues: map[string]*admissionQueue{
+		"stale":   {idleSince: now.Add(-admissionQueueIdleTTL)},
+		"recent":  {idleSince: now.Add(-time.Second)},
+		"running": {running: true, idleSince: now.Add(-time.Hour)},
+	}}
+	a.dropIdle(now)
+	if _, ok := a.queues["stale"]; ok || len(a.queues) != 2 {
+		t.Errorf("queues after dropIdle = %v, want recent and running only", a.queues)
+	}
+}
@@ -350,6 +350,7 @@ func (q *QuotaFields) Snapshot() *AuthQuotaStateSnapshot {
 		LastExhaustedAt: q.GetLastExhaustedAt(),
 		LearnedLimit:    q.LearnedLimit.Load(),
 		LearnedCooldown: q.GetLearnedCooldown(),
+		RealQuota:       q.RealQuota.Load(),
 	}
 }
 
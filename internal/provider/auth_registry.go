@@ -369,7 +369,12 @@ func (r *AuthRegistry) Load(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	r.loadAuths(items)
+	return nil
+}
 
+// loadAuths adds entries for auths listed from the store.
+func (r *AuthRegistry) loadAuths(items []*Auth) {
 	for _, auth := range items {
 		if auth == nil || auth.ID == "" {
 			continue
@@ -384,8 +389,6 @@ func (r *AuthRegistry) Load(ctx context.Context) error {
 
 		r.scheduleRefreshIfNeeded(entry)
 	}
-
-	return nil
 }
 
 func (r *AuthRegistry) scheduleRefreshIfNeeded(entry *AuthEntry) {
@@ -920,12 +923,20 @@ func (r *AuthRegistry) Pick(ctx context.Context, provider, model string, opts Op
 	}
 
 	var selected *AuthEntry
-	minActive := int64(1<<63 - 1)
-	for _, entry := range available {
-		active := entry.Quota.ActiveRequests.Load()
-		if active < minActive {
-			minActive = active
-			selected = entry
+	if entriesWeighted(available) {
+		// Weighted auths share traffic in proportion to their weight, less
+		// the requests each already has in flight.
+		selected = available[pickWeighted(len(available), func(i int) float64 {
+			return float64(AuthWeight(entryMetadataMap(available[i]))) / float64(available[i].Quota.ActiveRequests.Load()+1)
+		})]
+	} else {
+		minActive := int64(1<<63 - 1)
+		for _, entry := range available {
+			active := entry.Quota.ActiveRequests.Load()
+			if active < minActive {
+				minActive = active
+				selected = entry
+			}
 		}
 	}
 
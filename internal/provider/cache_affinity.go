@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// CacheAffinityPolicy keeps requests that share a cacheable prompt prefix on
+// the auth that served it last, so the upstream prompt cache is reused. An
+// affinity lasts TTL after its last use, or the provider's entry in
+// ProviderTTL, and is only broken while its auth cannot serve the model.
+type CacheAffinityPolicy struct {
+	TTL         time.Duration
+	ProviderTTL map[string]time.Duration
+}
+
+func (p *CacheAffinityPolicy) ttl(provider string) time.Duration {
+	if ttl, ok := p.ProviderTTL[provider]; ok {
+		return ttl
+	}
+	return p.TTL
+}
+
+// SetCacheAffinity enables prompt-cache affinity with policy; nil disables it.
+func (m *Manager) SetCacheAffinity(policy *CacheAffinityPolicy) {
+	if m == nil {
+		return
+	}
+	m.cacheAffinityPolicy.Store(policy)
+}
+
+// pickByAffinity picks the auth that last served a prefix of the request's
+// prompt, if it is among entries and can serve model, and otherwise picks by
+// tier. The cacheable prefix of the request is then bound to the picked auth.
+func (m *Manager) pickByAffinity(ctx context.Context, provider, model string, opts Options, entries []*AuthEntry) (*AuthEntry, error) {
+	policy := m.cacheAffinityPolicy.Load()
+	if policy == nil {
+		return m.pickByTier(ctx, provider, model, opts, entries)
+	}
+	ttl := policy.ttl(provider)
+	if ttl <= 0 {
+		return m.pickByTier(ctx, provider, model, opts, entries)
+	}
+	lookup, bind := cachePrefixHashes(opts.OriginalRequest)
+	if bind == "" {
+		return m.pickByTier(ctx, provider, model, opts, entries)
+	}
+	keyPrefix := provider + "|" + model + "|"
+
+	selected, elsewhere := m.affineEntry(keyPrefix, model, lookup, entries)
+	if selected != nil {
+		selected.IncrementActiveRequests()
+	} else {
+		var err error
+		if selected, err = m.pickByTier(ctx, provider, model, opts, entries); err != nil {
+			return nil, err
+		}
+		if elsewhere {
+			// The bound auth was already tried or is serving a hedge of
+			// this request; keep the binding.
+			return selected, nil
+		}
+	}
+	m.cacheAffinity.SetWithTTL(keyPrefix+bind, selected.ID(), ttl)
+	return selected, nil
+}
+
+// affineEntry returns the entry bound to the longest prefix in lookup, or nil
+// when there is none or it cannot serve model right now. elsewhere reports a
+// binding to a usable auth that is not among entries.
+func (m *Manager) affineEntry(keyPrefix, model string, lookup []string, entries []*AuthEntry) (selected *AuthEntry, elsewhere bool) {
+	now := time.Now()
+	for _, h := range lookup {
+		authID, ok := m.cacheAffinity.Get(keyPrefix + h)
+		if !ok {
+			continue
+		}
+		bound := m.registry.GetEntry(authID)
+		if bound == nil || bound.IsDisabled() || bound.IsInCooldown(now) {
+			return nil, false
+		}
+		if blocked, _, _ := bound.IsBlockedForModel(model, now); blocked {
+			return nil, false
+		}
+		if qm, ok := m.selector.(*QuotaManager); ok && !qm.KeepsBinding(bound.Provider(), model, bound.ToAuth()) {
+			return nil, false
+		}
+		for _, entry := range entries {
+			if entry.ID() == authID {
+				return entry, false
+			}
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// cacheControlPattern matches a cache_control field and the comma that
+// separates it from its neighbours.
+var cacheControlPattern = regexp.MustCompile(`,\s*"cache_control"\s*:\s*\{[^{}]*\}|"cache_control"\s*:\s*\{[^{}]*\}\s*,?`)
+
+// maxCachePrefixLookups bounds the prefixes of one request looked up, newest
+// first; the previous turn of a conversation is a few messages back.
+const maxCachePrefixLookups = 32
+
+// cachePrefixHashes hashes the prompt prefixes of a request payload, in the
+// order providers cache them: tools, system prompt, then messages.
+// cache_control fields are left out, since clients move them between turns.
+//
+// bind is the cacheable prefix: up to the last cache_control breakpoint, or
+// the whole prompt when there is none. lookup holds the prefixes ending after
+// the system prompt and after each message, longest first, so that the next
+// turn of a conversation finds the prefix bound by this one.
+func cachePrefixHashes(payload []byte) (lookup []string, bind string) {
+	if len(payload) == 0 || !gjson.ValidBytes(payload) {
+		return nil, ""
+	}
+	root := gjson.ParseBytes(payload)
+	var segments []gjson.Result
+	appendSegments := func(v gjson.Result) {
+		if v.IsArray() {
+			segments = append(segments, v.Array()...)
+		} else if v.Exists() {
+			segments = append(segments, v)
+		}
+	}
+	appendSegments(root.Get("tools"))
+	appendSegments(firstField(root, "system", "systemInstruction", "system_instruction", "instructions"))
+	head := len(segments)
+	messages := firstField(root, "messages", "contents", "input")
+	if messages.IsArray() {
+		segments = append(segments, messages.Array()...)
+	}
+	if len(segments) == 0 {
+		return nil, ""
+	}
+
+	breakpoints := bytes.Contains(payload, []byte(`"cache_control"`))
+	h := sha256.New()
+	for i, segment := range segments {
+		writeSegment(h, segment.Raw)
+		boundary := i+1 >= head
+		marked := breakpoints && strings.Contains(segment.Raw, `"cache_control"`)
+		if !boundary && !marked {
+			continue
+		}
+		sum := hex.EncodeToString(h.Sum(nil)[:16])
+		if boundary {
+			lookup = append(lookup, sum)
+		}
+		if marked || !breakpoints {
+			bind = sum
+		}
+	}
+	slices.Reverse(lookup)
+	if len(lookup) > maxCachePrefixLookups {
+		lookup = lookup[:maxCachePrefixLookups]
+	}
+	return lookup, bind
+}
+
+func firstField(root gjson.Result, names ...string) gjson.Result {
+	for _, name := range names {
+		if v := root.Get(name); v.Exists() {
+			return v
+		}
+	}
+	return gjson.Result{}
+}
+
+func writeSegment(h hash.Hash, raw string) {
+	if strings.Contains(raw, `"cache_control"`) {
+		raw = cacheControlPattern.ReplaceAllString(raw, "")
+	}
+	h.Write([]byte(raw))
+	h.Write([]byte{0})
+}
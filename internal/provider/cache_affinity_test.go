@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+const (
+	claudeTurn1 = `{"system":[{"type":"text","text":"You are a coding agent.","cache_control":{"type":"ephemeral"}}],
+		"messages":[{"role":"user","content":[{"type":"text","text":"fix the bug","cache_control":{"type":"ephemeral"}}]}]}`
+	claudeTurn2 = `{"system":[{"type":"text","text":"You are a coding agent.","cache_control":{"type":"ephemeral"}}],
+		"messages":[{"role":"user","content":[{"type":"text","text":"fix the bug"}]},
+		{"role":"assistant","content":[{"type":"text","text":"done"}]},
+		{"role":"user","content":[{"cache_control":{"type":"ephemeral"},"type":"text","text":"thanks"}]}]}`
+)
+
+func TestCachePrefixHashesFollowMovingBreakpoints(t *testing.T) {
+	lookup1, bind1 := cachePrefixHashes([]byte(claudeTurn1))
+	lookup2, bind2 := cachePrefixHashes([]byte(claudeTurn2))
+	if bind1 != lookup1[0] || bind2 != lookup2[0] {
+		t.Fatalf("bind is not the prefix up to the last breakpoint")
+	}
+	if !slices.Contains(lookup2, bind1) {
+		t.Errorf("turn 2 lookup %v misses the prefix bound by turn 1 %s", lookup2, bind1)
+	}
+
+	openAITurn1 := `{"messages":[{"role":"system","content":"s"},{"role":"user","content":"a"}]}`
+	openAITurn2 := `{"messages":[{"role":"system","content":"s"},{"role":"user","content":"a"},{"role":"assistant","content":"b"},{"role":"user","content":"c"}]}`
+	_, bind1 = cachePrefixHashes([]byte(openAITurn1))
+	lookup2, _ = cachePrefixHashes([]byte(openAITurn2))
+	if !slices.Contains(lookup2, bind1) {
+		t.Errorf("turn 2 lookup %v misses the prompt of turn 1 %s", lookup2, bind1)
+	}
+	if _, bind := cachePrefixHashes([]byte(`{"model":"x"}`)); bind != "" {
+		t.Errorf("payload without a prompt binds %q", bind)
+	}
+}
+
+func TestPickByAffinityKeepsPromptPrefixOnOneAuth(t *testing.T) {
+	const model = "affinity-test-model"
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+	m.SetCacheAffinity(&CacheAffinityPolicy{TTL: time.Minute})
+
+	for _, id := range []string{"affinity-a", "affinity-b"} {
+		_, _ = m.registry.Register(ctx, &Auth{ID: id, Provider: "claude", Status: StatusActive})
+	}
+	entries := m.registry.ListByProvider("claude")
+
+	first, err := m.pickByAffinity(ctx, "claude", model, Options{OriginalRequest: []byte(claudeTurn1)}, entries)
+	if err != nil {
+		t.Fatalf("pickByAffinity: %v", err)
+	}
+	// first keeps its request in flight, so least-active selection alone
+	// would move the next turn to the other auth.
+	for i := 0; i < 3; i++ {
+		next, err := m.pickByAffinity(ctx, "claude", model, Options{OriginalRequest: []byte(claudeTurn2)}, entries)
+		if err != nil || next.ID() != first.ID() {
+			t.Fatalf("turn 2 pick %d = %v, %v; want %s", i, next, err, first.ID())
+		}
+	}
+
+	first.SetCooldown(time.Now().Add(time.Hour))
+	moved, err := m.pickByAffinity(ctx, "claude", model, Options{OriginalRequest: []byte(claudeTurn2)}, entries)
+	if err != nil || moved.ID() == first.ID() {
+		t.Fatalf("pick while %s cools down = %v, %v; want the other auth", first.ID(), moved, err)
+	}
+	first.ClearCooldown()
+	again, err := m.pickByAffinity(ctx, "claude", model, Options{OriginalRequest: []byte(claudeTurn2)}, entries)
+	if err != nil || again.ID() != moved.ID() {
+		t.Errorf("pick after cooldown = %v, %v; want the rebound auth %s", again, err, moved.ID())
+	}
+}
+
+func TestPickByAffinityLeavesAuthWithoutPremiumAllowance(t *testing.T) {
+	const model = "affinity-premium-model"
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+	m.SetCacheAffinity(&CacheAffinityPolicy{TTL: time.Minute})
+	qm := m.GetQuotaManager()
+	refuse := true
+	qm.SetQuotaOverrides(&QuotaOverrides{Providers: map[string]QuotaOverride{"github-copilot": {RefuseOverage: &refuse}}})
+	copilot := qm.getStrategy("github-copilot").(*CopilotStrategy)
+
+	reg := registry.GetGlobalRegistry()
+	for _, id := range []string{"affinity-copilot-a", "affinity-copilot-b"} {
+		_, _ = m.registry.Register(ctx, &Auth{ID: id, Provider: "github-copilot", Status: StatusActive})
+		reg.RegisterClient(id, "github-copilot", []*registry.ModelInfo{{ID: model, PremiumMultiplier: 1}})
+		t.Cleanup(func() { reg.UnregisterClient(id) })
+		copilot.premium.Store(id, &copilotPremium{Entitlement: 300, Remaining: 100})
+	}
+	entries := m.registry.ListByProvider("github-copilot")
+
+	first, err := m.pickByAffinity(ctx, "github-copilot", model, Options{OriginalRequest: []byte(claudeTurn1)}, entries)
+	if err != nil {
+		t.Fatalf("pickByAffinity: %v", err)
+	}
+	copilot.premium.Store(first.ID(), &copilotPremium{Entitlement: 300, Remaining: 0})
+	moved, err := m.pickByAffinity(ctx, "github-copilot", model, Options{OriginalRequest: []byte(claudeTurn2)}, entries)
+	if err != nil || moved.ID() == first.ID() {
+		t.Errorf("pick once %s has no premium requests left = %v, %v; want the other auth", first.ID(), moved, err)
+	}
+}
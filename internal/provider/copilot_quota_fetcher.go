@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/transport"
+	"github.com/tidwall/gjson"
+)
+
+const copilotUserEndpoint = "https://api.github.com/copilot_internal/user"
+
+// copilotPremium is the premium request allowance of a Copilot account for
+// the current month. It is replaced, never modified, once stored.
+type copilotPremium struct {
+	Entitlement      float64
+	Remaining        float64
+	Unlimited        bool
+	OveragePermitted bool
+	ResetAt          time.Time
+}
+
+func fetchCopilotPremium(ctx context.Context, accessToken string) *copilotPremium {
+	if accessToken == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, quotaFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, copilotUserEndpoint, nil)
+	if err != nil {
+		return nil
+	}
+
+	req.Header.Set("Authorization", "token "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := transport.SharedClient.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil
+	}
+	return parseCopilotPremium(body)
+}
+
+// parseCopilotPremium reads the premium_interactions quota of a
+// copilot_internal/user response, or returns nil when it has none.
+func parseCopilotPremium(data []byte) *copilotPremium {
+	quota := gjson.GetBytes(data, "quota_snapshots.premium_interactions")
+	if !quota.Exists() {
+		return nil
+	}
+
+	premium := &copilotPremium{
+		Entitlement:      quota.Get("entitlement").Float(),
+		Remaining:        quota.Get("remaining").Float(),
+		Unlimited:        quota.Get("unlimited").Bool(),
+		OveragePermitted: quota.Get("overage_permitted").Bool(),
+	}
+	if !quota.Get("remaining").Exists() && premium.Entitlement > 0 {
+		premium.Remaining = premium.Entitlement * quota.Get("percent_remaining").Float() / 100
+	}
+	if t, err := time.Parse("2006-01-02", gjson.GetBytes(data, "quota_reset_date").String()); err == nil {
+		premium.ResetAt = t
+	}
+	return premium
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// ModelPriceFunc returns the blended price per million tokens of a model ID.
+type ModelPriceFunc func(model string) (float64, bool)
+
+// costPolicy holds the settings of cost-aware selection.
+type costPolicy struct {
+	price ModelPriceFunc
+}
+
+// SetCostRouting enables or disables cost-aware selection. When enabled,
+// providers with an available subscription auth come first, then paid
+// providers from cheapest to most expensive according to price; within a
+// provider, paid auths are only picked while every subscription auth is
+// cooling down or blocked.
+func (m *Manager) SetCostRouting(enabled bool, price ModelPriceFunc) {
+	if m == nil {
+		return
+	}
+	if !enabled {
+		m.costPolicy.Store(nil)
+		return
+	}
+	if price == nil {
+		price = func(string) (float64, bool) { return 0, false }
+	}
+	m.costPolicy.Store(&costPolicy{price: price})
+}
+
+// isPaidAuth reports whether requests on an auth are billed per token. API
+// keys and Vertex service accounts are, unless the key belongs to a provider
+// marked as a subscription; OAuth accounts are not.
+func isPaidAuth(provider string, attrs map[string]string) bool {
+	if attrs["subscription"] == "true" {
+		return false
+	}
+	return attrs["api_key"] != "" || provider == "vertex"
+}
+
+func entryIsPaid(entry *AuthEntry) bool {
+	var attrs map[string]string
+	if meta := entry.Metadata(); meta != nil {
+		attrs = meta.Attributes
+	}
+	return isPaidAuth(entry.Provider(), attrs)
+}
+
+// pickByCost picks among the subscription entries first and falls back to
+// the paid ones only when none of those can serve the request.
+func (m *Manager) pickByCost(ctx context.Context, provider, model string, opts Options, entries []*AuthEntry) (*AuthEntry, error) {
+	if m.costPolicy.Load() == nil {
+		return m.registry.Pick(ctx, provider, model, opts, entries)
+	}
+	free := make([]*AuthEntry, 0, len(entries))
+	paid := make([]*AuthEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entryIsPaid(entry) {
+			paid = append(paid, entry)
+		} else {
+			free = append(free, entry)
+		}
+	}
+	if len(free) > 0 {
+		selected, err := m.registry.Pick(ctx, provider, model, opts, free)
+		if err == nil || len(paid) == 0 {
+			return selected, err
+		}
+	}
+	return m.registry.Pick(ctx, provider, model, opts, paid)
+}
+
+// sortByCost orders providers by marginal cost for model: providers with an
+// available subscription auth first, then paid providers by price, unknown
+// prices last. The incoming order breaks ties.
+func (m *Manager) sortByCost(providers []string, model string, policy *costPolicy) []string {
+	costs := make(map[string]float64, len(providers))
+	for _, p := range providers {
+		costs[p] = m.providerCost(p, model, policy)
+	}
+	sorted := append([]string(nil), providers...)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && costs[sorted[j]] < costs[sorted[j-1]]; j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+	return sorted
+}
+
+// providerCost returns zero when provider has an available subscription auth
+// for model and otherwise the model's price, or +Inf when it is unknown.
+func (m *Manager) providerCost(provider, model string, policy *costPolicy) float64 {
+	now := time.Now()
+	models := registry.GetGlobalRegistry()
+	if m.registry != nil {
+		for _, entry := range m.registry.ListByProvider(provider) {
+			if entry.IsDisabled() || entryIsPaid(entry) || entry.IsInCooldown(now) {
+				continue
+			}
+			if !models.ClientSupportsModel(entry.ID(), model) {
+				continue
+			}
+			if blocked, _, _ := entry.IsBlockedForModel(model, now); !blocked {
+				return 0
+			}
+		}
+	}
+	if price, ok := policy.price(models.GetModelIDForProvider(model, provider)); ok {
+		return price
+	}
+	if price, ok := policy.price(model); ok {
+		return price
+	}
+	return math.Inf(1)
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestIsPaidAuth(t *testing.T) {
+	cases := []struct {
+		provider string
+		attrs    map[string]string
+		want     bool
+	}{
+		{"claude", nil, false},
+		{"claude", map[string]string{"api_key": "sk"}, true},
+		{"openai", map[string]string{"api_key": "sk", "subscription": "true"}, false},
+		{"vertex", nil, true},
+	}
+	for _, tc := range cases {
+		if got := isPaidAuth(tc.provider, tc.attrs); got != tc.want {
+			t.Errorf("isPaidAuth(%q, %v) = %v, want %v", tc.provider, tc.attrs, got, tc.want)
+		}
+	}
+}
+
+func TestCostRoutingPrefersSubscriptionAuths(t *testing.T) {
+	const model = "cost-test-model"
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+
+	reg := registry.GetGlobalRegistry()
+	register := func(auth *Auth) *AuthEntry {
+		auth.Status = StatusActive
+		_, _ = m.registry.Register(ctx, auth)
+		reg.RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: model}})
+		t.Cleanup(func() { reg.UnregisterClient(auth.ID) })
+		return m.registry.GetEntry(auth.ID)
+	}
+	oauth := register(&Auth{ID: "cost-oauth", Provider: "claude"})
+	register(&Auth{ID: "cost-key", Provider: "claude", Attributes: map[string]string{"api_key": "sk"}})
+	register(&Auth{ID: "cost-gemini", Provider: "gemini", Attributes: map[string]string{"api_key": "k"}})
+	register(&Auth{ID: "cost-unpriced", Provider: "openai", Attributes: map[string]string{"api_key": "k"}})
+
+	m.SetCostRouting(true, func(id string) (float64, bool) { return 2, id == model })
+	policy := m.costPolicy.Load()
+	if got := m.sortByCost([]string{"gemini", "claude"}, model, policy); got[0] != "claude" {
+		t.Fatalf("sortByCost = %v, want the provider with a subscription first", got)
+	}
+	if c := m.providerCost("openai", "unpriced-model", policy); !math.IsInf(c, 1) {
+		t.Errorf("unpriced model cost = %v, want +Inf", c)
+	}
+
+	entries := m.registry.ListByProvider("claude")
+	selected, err := m.pickByCost(ctx, "claude", model, Options{}, entries)
+	if err != nil || selected.ID() != "cost-oauth" {
+		t.Fatalf("picked %v, %v; want the subscription auth", selected, err)
+	}
+
+	oauth.SetCooldown(time.Now().Add(time.Hour))
+	selected, err = m.pickByCost(ctx, "claude", model, Options{}, entries)
+	if err != nil || selected.ID() != "cost-key" {
+		t.Fatalf("picked %v, %v; want the paid auth while the subscription cools down", selected, err)
+	}
+	if got := m.sortByCost([]string{"gemini", "claude"}, model, policy); got[0] != "gemini" {
+		t.Errorf("sortByCost = %v, want the incoming order once both are paid", got)
+	}
+}
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"errors"
+	"net/http"
 	"time"
 
 	"github.com/nghyane/llm-mux/internal/registry"
@@ -44,10 +45,7 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 		}
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-		}
+		execCtx := m.attemptContext(ctx, auth)
 
 		authCopy := auth
 		reqCopy := req
@@ -78,6 +76,9 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 			}
 			m.MarkResult(execCtx, markResult)
 			lastErr = errBreaker
+			if singleAttempt(ctx) {
+				return Response{}, lastErr
+			}
 			continue
 		}
 
@@ -87,9 +88,51 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 	}
 }
 
+// unaryCall invokes a single non-streaming executor method for one auth.
+type unaryCall func(ProviderExecutor, context.Context, *Auth, Request, Options) (Response, error)
+
 // ExecuteCountWithProvider handles token counting for a single provider, attempting
 // multiple auth candidates until one succeeds or all are exhausted.
 func (m *Manager) executeCountWithProvider(ctx context.Context, provider string, req Request, opts Options) (Response, error) {
+	return m.executeUnaryWithProvider(ctx, provider, req, opts, ProviderExecutor.CountTokens)
+}
+
+// ExecuteEmbedWithProvider handles embedding requests for a single provider. Providers
+// whose executor does not implement EmbeddingExecutor are rejected before any auth is
+// picked so that they are not penalised for an unsupported operation.
+func (m *Manager) executeEmbedWithProvider(ctx context.Context, provider string, req Request, opts Options) (Response, error) {
+	if executor := m.executorFor(provider); executor != nil {
+		if _, ok := executor.(EmbeddingExecutor); !ok {
+			return Response{}, &Error{Code: "not_supported", Message: "provider " + provider + " does not support embeddings", HTTPStatus: http.StatusBadRequest, ErrCategory: CategoryUserError}
+		}
+	}
+	return m.executeUnaryWithProvider(ctx, provider, req, opts, func(e ProviderExecutor, ctx context.Context, auth *Auth, req Request, opts Options) (Response, error) {
+		return e.(EmbeddingExecutor).Embed(ctx, auth, req, opts)
+	})
+}
+
+// ExecuteLiveWithProvider opens a realtime session for a single provider and stores
+// it in conn. Like embeddings, providers without a LiveExecutor are rejected before
+// any auth is picked.
+func (m *Manager) executeLiveWithProvider(ctx context.Context, provider string, req Request, opts Options, conn *LiveConn) (Response, error) {
+	if executor := m.executorFor(provider); executor != nil {
+		if _, ok := executor.(LiveExecutor); !ok {
+			return Response{}, &Error{Code: "not_supported", Message: "provider " + provider + " does not support live sessions", HTTPStatus: http.StatusBadRequest, ErrCategory: CategoryUserError}
+		}
+	}
+	return m.executeUnaryWithProvider(ctx, provider, req, opts, func(e ProviderExecutor, ctx context.Context, auth *Auth, req Request, opts Options) (Response, error) {
+		c, err := e.(LiveExecutor).DialLive(ctx, auth, req, opts)
+		if err != nil {
+			return Response{}, err
+		}
+		*conn = c
+		return Response{}, nil
+	})
+}
+
+// executeUnaryWithProvider runs call against auth candidates of a single provider
+// until one succeeds or all are exhausted.
+func (m *Manager) executeUnaryWithProvider(ctx context.Context, provider string, req Request, opts Options, call unaryCall) (Response, error) {
 	if provider == "" {
 		return Response{}, &Error{Code: "provider_not_found", Message: "provider identifier is empty"}
 	}
@@ -113,15 +156,12 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 		}
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-		}
+		execCtx := m.attemptContext(ctx, auth)
 
 		authCopy := auth
 		reqCopy := req
 		result, errBreaker := breaker.Execute(func() (any, error) {
-			return executor.CountTokens(execCtx, authCopy, reqCopy, opts)
+			return call(executor, execCtx, authCopy, reqCopy, opts)
 		})
 
 		if errBreaker != nil {
@@ -146,6 +186,9 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 			}
 			m.MarkResult(execCtx, markResult)
 			lastErr = errBreaker
+			if singleAttempt(ctx) {
+				return Response{}, lastErr
+			}
 			continue
 		}
 
@@ -184,14 +227,13 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		}
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-		}
+		execCtx := m.attemptContext(ctx, auth)
+		requestStart := time.Now()
 		chunks, errStream := executor.ExecuteStream(execCtx, auth, req, opts)
 		if errStream != nil {
 			if errors.Is(errStream, context.Canceled) || errors.Is(errStream, context.DeadlineExceeded) {
-				done(false)
+				// Losing a hedge race says nothing about the provider's health.
+				done(HedgeLost(ctx))
 				return nil, errStream
 			}
 
@@ -210,6 +252,9 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 			result.RetryAfter = retryAfterFromError(errStream)
 			m.MarkResult(execCtx, result)
 			lastErr = errStream
+			if singleAttempt(ctx) {
+				return nil, lastErr
+			}
 			continue
 		}
 
@@ -219,7 +264,7 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamModel string, streamChunks <-chan StreamChunk, cbDone func(bool)) {
 			defer close(out)
-			var failed bool
+			var failed, started bool
 
 			for {
 				select {
@@ -240,6 +285,11 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 						return
 					}
 
+					if !started && chunk.Err == nil {
+						started = true
+						m.providerStats.RecordTTFT(streamProvider, streamModel, time.Since(requestStart))
+					}
+
 					// Check for errors in chunk
 					if chunk.Err != nil && !failed {
 						if errors.Is(chunk.Err, context.Canceled) || errors.Is(chunk.Err, context.DeadlineExceeded) {
@@ -287,6 +337,9 @@ func (m *Manager) executeProvidersOnce(ctx context.Context, providers []string,
 			return resp, nil
 		}
 		lastErr = errExec
+		if singleAttempt(ctx) {
+			break
+		}
 	}
 	if lastErr != nil {
 		return Response{}, lastErr
@@ -307,6 +360,9 @@ func (m *Manager) executeStreamProvidersOnce(ctx context.Context, providers []st
 			return chunks, nil
 		}
 		lastErr = errExec
+		if singleAttempt(ctx) {
+			break
+		}
 	}
 	if lastErr != nil {
 		return nil, lastErr
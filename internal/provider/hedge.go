@@ -0,0 +1,226 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/sony/gobreaker"
+)
+
+// ErrHedgeLost is the cancellation cause of a hedged attempt whose sibling
+// delivered its first chunk first.
+var ErrHedgeLost = errors.New("hedged request: another attempt answered first")
+
+// HedgeLost reports whether ctx belongs to a hedged attempt that was cancelled
+// because another attempt won. Such an attempt has not failed, and its usage is
+// still recorded.
+func HedgeLost(ctx context.Context) bool {
+	return ctx != nil && errors.Is(context.Cause(ctx), ErrHedgeLost)
+}
+
+// HedgePolicy configures hedged streaming. A stream whose first chunk has not
+// arrived after the Percentile of recent time-to-first-token, clamped to
+// [MinDelay, MaxDelay], is raced against a second attempt.
+type HedgePolicy struct {
+	Percentile float64
+	MinDelay   time.Duration
+	MaxDelay   time.Duration
+}
+
+// SetHedging enables hedged streaming with policy; nil disables it.
+func (m *Manager) SetHedging(policy *HedgePolicy) {
+	if m == nil {
+		return
+	}
+	m.hedgePolicy.Store(policy)
+}
+
+type hedgeContextKey struct{}
+
+// hedgeGroup records the auths claimed by the attempts of one hedged request so
+// that every attempt runs on a different auth.
+type hedgeGroup struct {
+	mu    sync.Mutex
+	auths map[string]struct{}
+}
+
+func hedgeFromContext(ctx context.Context) *hedgeGroup {
+	if ctx == nil {
+		return nil
+	}
+	g, _ := ctx.Value(hedgeContextKey{}).(*hedgeGroup)
+	return g
+}
+
+func (g *hedgeGroup) claim(authID string) {
+	g.mu.Lock()
+	g.auths[authID] = struct{}{}
+	g.mu.Unlock()
+}
+
+func (g *hedgeGroup) claimed(authID string) bool {
+	g.mu.Lock()
+	_, ok := g.auths[authID]
+	g.mu.Unlock()
+	return ok
+}
+
+// hedgeAttempt is the outcome of one attempt up to its first chunk.
+type hedgeAttempt struct {
+	chunks <-chan StreamChunk
+	first  StreamChunk
+	ok     bool // false when the stream ended without any chunk
+	err    error
+	index  int
+	cancel context.CancelCauseFunc
+}
+
+// answered reports whether the attempt produced a usable stream.
+func (a hedgeAttempt) answered() bool {
+	return a.err == nil && (!a.ok || a.first.Err == nil)
+}
+
+// executeStreamHedged runs fn over providers like executeStreamProvidersOnce,
+// but starts a second attempt on another auth when the first chunk is late.
+// The stream that delivers a chunk first is returned and the other attempt is
+// cancelled with ErrHedgeLost.
+func (m *Manager) executeStreamHedged(ctx context.Context, providers []string, model string, policy *HedgePolicy, fn func(context.Context, string) (<-chan StreamChunk, error)) (<-chan StreamChunk, error) {
+	if len(providers) == 0 {
+		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
+	}
+	group := &hedgeGroup{auths: make(map[string]struct{})}
+	ctx = context.WithValue(ctx, hedgeContextKey{}, group)
+
+	results := make(chan hedgeAttempt, 2)
+	var cancels []context.CancelCauseFunc
+	start := func(candidates []string) {
+		attemptCtx, cancel := context.WithCancelCause(ctx)
+		a := hedgeAttempt{index: len(cancels), cancel: cancel}
+		cancels = append(cancels, cancel)
+		go func() {
+			a.chunks, a.err = m.executeStreamProvidersOnce(attemptCtx, candidates, fn)
+			if a.err == nil {
+				select {
+				case a.first, a.ok = <-a.chunks:
+				case <-attemptCtx.Done():
+					a.err = context.Cause(attemptCtx)
+				}
+			}
+			results <- a
+		}()
+	}
+
+	start(providers)
+	timer := time.NewTimer(m.hedgeDelay(providers[0], model, policy))
+	defer timer.Stop()
+
+	pending := 1
+	var lastErr error
+	for pending > 0 {
+		select {
+		case a := <-results:
+			pending--
+			// An attempt that starts with an error chunk only wins when there
+			// is nothing else left to wait for.
+			if a.answered() || a.err == nil && pending == 0 {
+				for i, cancel := range cancels {
+					if i != a.index {
+						cancel(ErrHedgeLost)
+					}
+				}
+				return forwardHedged(ctx, a), nil
+			}
+			if a.err != nil {
+				lastErr = a.err
+			} else {
+				a.cancel(a.first.Err)
+				lastErr = a.first.Err
+			}
+		case <-timer.C:
+			if candidates := m.hedgeCandidates(providers, model, group); len(candidates) > 0 {
+				pending++
+				start(candidates)
+			}
+		case <-ctx.Done():
+			for _, cancel := range cancels {
+				cancel(context.Cause(ctx))
+			}
+			return nil, ctx.Err()
+		}
+	}
+	return nil, lastErr
+}
+
+// forwardHedged returns a stream of a's first chunk followed by the rest of its
+// chunks, releasing the attempt's context when the stream ends.
+func forwardHedged(ctx context.Context, a hedgeAttempt) <-chan StreamChunk {
+	out := make(chan StreamChunk, 128)
+	go func() {
+		defer close(out)
+		defer a.cancel(nil)
+		if !a.ok {
+			return
+		}
+		select {
+		case out <- a.first:
+		case <-ctx.Done():
+			return
+		}
+		for chunk := range a.chunks {
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// hedgeDelay returns how long to wait for the first chunk from provider before
+// hedging.
+func (m *Manager) hedgeDelay(provider, model string, policy *HedgePolicy) time.Duration {
+	model = registry.GetGlobalRegistry().GetModelIDForProvider(model, provider)
+	ttft, ok := m.providerStats.TTFTPercentile(provider, model, policy.Percentile)
+	if !ok || ttft > policy.MaxDelay {
+		return policy.MaxDelay
+	}
+	if ttft < policy.MinDelay {
+		return policy.MinDelay
+	}
+	return ttft
+}
+
+// hedgeCandidates returns the providers that still have an available auth for
+// model that no attempt of the request has claimed.
+func (m *Manager) hedgeCandidates(providers []string, model string, group *hedgeGroup) []string {
+	if m.registry == nil {
+		return nil
+	}
+	now := time.Now()
+	models := registry.GetGlobalRegistry()
+	var candidates []string
+	for _, p := range providers {
+		if m.getOrCreateStreamingBreaker(p).State() == gobreaker.StateOpen {
+			continue
+		}
+		providerModel := models.GetModelIDForProvider(model, p)
+		for _, entry := range m.registry.ListByProvider(p) {
+			if entry.IsDisabled() || group.claimed(entry.ID()) || entry.IsInCooldown(now) {
+				continue
+			}
+			if !models.ClientSupportsModel(entry.ID(), providerModel) {
+				continue
+			}
+			if blocked, _, _ := entry.IsBlockedForModel(providerModel, now); blocked {
+				continue
+			}
+			candidates = append(candidates, p)
+			break
+		}
+	}
+	return candidates
+}
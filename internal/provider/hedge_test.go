@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// hedgeTestExecutor stalls its first stream until cancelled and answers every
+// later one immediately.
+type hedgeTestExecutor struct {
+	calls     atomic.Int32
+	mu        sync.Mutex
+	authIDs   []string
+	cancelled chan error
+}
+
+func (e *hedgeTestExecutor) Identifier() string { return "hedge-test" }
+
+func (e *hedgeTestExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (e *hedgeTestExecutor) ExecuteStream(ctx context.Context, auth *Auth, _ Request, _ Options) (<-chan StreamChunk, error) {
+	e.mu.Lock()
+	e.authIDs = append(e.authIDs, auth.ID)
+	e.mu.Unlock()
+	out := make(chan StreamChunk, 1)
+	if e.calls.Add(1) == 1 {
+		go func() {
+			defer close(out)
+			<-ctx.Done()
+			e.cancelled <- context.Cause(ctx)
+		}()
+		return out, nil
+	}
+	out <- StreamChunk{Payload: []byte(auth.ID)}
+	close(out)
+	return out, nil
+}
+
+func (e *hedgeTestExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *hedgeTestExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func TestExecuteStreamHedgesSlowFirstChunk(t *testing.T) {
+	const model = "hedge-test-model"
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+
+	executor := &hedgeTestExecutor{cancelled: make(chan error, 1)}
+	m.RegisterExecutor(executor)
+	reg := registry.GetGlobalRegistry()
+	for _, id := range []string{"hedge-a", "hedge-b"} {
+		_, _ = m.Register(ctx, &Auth{ID: id, Provider: "hedge-test", Status: StatusActive})
+		reg.RegisterClient(id, "hedge-test", []*registry.ModelInfo{{ID: model}})
+		defer reg.UnregisterClient(id)
+	}
+	m.SetHedging(&HedgePolicy{Percentile: 95, MinDelay: 20 * time.Millisecond, MaxDelay: 20 * time.Millisecond})
+
+	chunks, err := m.ExecuteStream(ctx, []string{"hedge-test"}, Request{Model: model}, Options{})
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+	var got []string
+	for chunk := range chunks {
+		got = append(got, string(chunk.Payload))
+	}
+
+	select {
+	case cause := <-executor.cancelled:
+		if cause != ErrHedgeLost {
+			t.Errorf("slow attempt cancelled with %v, want ErrHedgeLost", cause)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow attempt was not cancelled")
+	}
+	executor.mu.Lock()
+	defer executor.mu.Unlock()
+	if len(executor.authIDs) != 2 || executor.authIDs[0] == executor.authIDs[1] {
+		t.Fatalf("attempts ran on %v, want two different auths", executor.authIDs)
+	}
+	if len(got) != 1 || got[0] != executor.authIDs[1] {
+		t.Errorf("stream = %v, want the hedged attempt's chunk from %s", got, executor.authIDs[1])
+	}
+}
+
+func TestProviderStatsTTFTPercentile(t *testing.T) {
+	ps := NewProviderStats()
+	if _, ok := ps.TTFTPercentile("p", "m", 95); ok {
+		t.Fatal("percentile reported without samples")
+	}
+	for i := 1; i <= 100; i++ {
+		ps.RecordTTFT("p", "m", time.Duration(i)*time.Millisecond)
+	}
+	// Only the latest ttftWindow samples (37ms-100ms) are kept.
+	if got, _ := ps.TTFTPercentile("p", "m", 0); got != 37*time.Millisecond {
+		t.Errorf("p0 = %v, want 37ms", got)
+	}
+	if got, _ := ps.TTFTPercentile("p", "m", 100); got != 100*time.Millisecond {
+		t.Errorf("p100 = %v, want 100ms", got)
+	}
+}
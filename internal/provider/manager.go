@@ -35,6 +35,26 @@ type ProviderExecutor interface {
 	CountTokens(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error)
 }
 
+// EmbeddingExecutor is implemented by executors whose upstream offers an embeddings API.
+type EmbeddingExecutor interface {
+	Embed(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error)
+}
+
+// LiveConn is a bidirectional message stream to an upstream realtime session.
+// Message types follow gorilla/websocket (TextMessage, BinaryMessage, ...).
+type LiveConn interface {
+	ReadMessage() (messageType int, data []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
+// LiveExecutor is implemented by executors whose upstream offers a realtime
+// (WebSocket) session API. DialLive only opens the session; the caller owns the
+// returned connection and must close it.
+type LiveExecutor interface {
+	DialLive(ctx context.Context, auth *Auth, req Request, opts Options) (LiveConn, error)
+}
+
 // RefreshEvaluator allows runtime state to override refresh decisions.
 type RefreshEvaluator interface {
 	ShouldRefresh(now time.Time, auth *Auth) bool
@@ -109,6 +129,19 @@ type Manager struct {
 	retryBudget *resilience.RetryBudget
 
 	registry *AuthRegistry
+
+	costPolicy  atomic.Pointer[costPolicy]
+	hedgePolicy atomic.Pointer[HedgePolicy]
+
+	cacheAffinityPolicy atomic.Pointer[CacheAffinityPolicy]
+	cacheAffinity       *StickyStore
+
+	admissionPolicy atomic.Pointer[AdmissionPolicy]
+	admission       admissionQueues
+
+	// lastQuotaState is the last quota state saved, to skip unchanged saves.
+	quotaStateMu   sync.Mutex
+	lastQuotaState []byte
 }
 
 // NewManager constructs a manager with optional custom selector and hook.
@@ -131,7 +164,9 @@ func NewManager(store Store, selector Selector, hook Hook) *Manager {
 		streamingBreakers: make(map[string]*resilience.StreamingCircuitBreaker),
 		retryBudget:       resilience.NewRetryBudget(100),
 		refreshSem:        newRefreshSemaphore(),
+		cacheAffinity:     NewStickyStore(),
 	}
+	m.cacheAffinity.Start()
 	m.registry = NewAuthRegistry(store, hook)
 	m.registry.SetExecutorProvider(m.executorFor)
 	m.registry.Start()
@@ -149,6 +184,7 @@ func (m *Manager) Stop() {
 	if m.registry != nil {
 		m.registry.Stop()
 	}
+	m.cacheAffinity.Stop()
 	m.mu.RLock()
 	selector := m.selector
 	m.mu.RUnlock()
@@ -216,6 +252,11 @@ func (m *Manager) Register(ctx context.Context, auth *Auth) (*Auth, error) {
 	if auth.ID == "" {
 		auth.ID = uuid.NewString()
 	}
+	if qm := m.GetQuotaManager(); qm != nil {
+		// The quota refresh outlives the request that registered the auth;
+		// it stops with UnregisterAuth or QuotaManager.Stop.
+		qm.RegisterAuth(context.WithoutCancel(ctx), auth)
+	}
 	m.mu.Lock()
 	m.auths[auth.ID] = auth.Clone()
 	m.mu.Unlock()
@@ -241,6 +282,9 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 	auth.EnsureIndex()
 	m.auths[auth.ID] = auth.Clone()
 	m.mu.Unlock()
+	if qm := m.GetQuotaManager(); qm != nil && auth.Disabled {
+		qm.UnregisterAuth(auth.ID)
+	}
 	if m.registry != nil {
 		_, _ = m.registry.Update(ctx, auth)
 	}
@@ -261,30 +305,46 @@ func (m *Manager) Load(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	qm, _ := m.selector.(*QuotaManager)
+	restoreQuotaState(ctx, m.store, qm)
 	m.auths = make(map[string]*Auth, len(items))
 	for _, auth := range items {
 		if auth == nil || auth.ID == "" {
 			continue
 		}
 		auth.EnsureIndex()
+		if qm != nil && !auth.Disabled {
+			qm.RegisterAuth(ctx, auth)
+		}
 		m.auths[auth.ID] = auth.Clone()
 	}
 	if m.registry != nil {
-		_ = m.registry.Load(ctx)
+		m.registry.loadAuths(items)
 	}
 	return nil
 }
 
 // Execute performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model with weighted selection based on performance.
+// When every auth is cooling down, the request waits in the admission queue if one is enabled.
 func (m *Manager) Execute(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
+	var queuedAt time.Time
+	for {
+		resp, err := m.execute(ctx, providers, req, opts)
+		if err == nil || !m.awaitAdmission(ctx, providers, req.Model, err, &queuedAt) {
+			return resp, err
+		}
+	}
+}
+
+func (m *Manager) execute(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
 	selected := m.selectProviders(req.Model, normalized)
 
-	retryTimes, maxWait := m.retrySettings()
+	retryTimes, maxWait := m.retrySettings(ctx)
 	attempts := retryTimes + 1
 	if attempts < 1 {
 		attempts = 1
@@ -341,13 +401,55 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req Request,
 // ExecuteCount performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model with weighted selection based on performance.
 func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
+	return m.executeUnary(ctx, providers, req, func(execCtx context.Context, provider string) (Response, error) {
+		return m.executeCountWithProvider(execCtx, provider, req, opts)
+	})
+}
+
+// ExecuteEmbed performs an embedding request using the configured selector and executor.
+// Providers whose executor does not implement EmbeddingExecutor are skipped with a
+// not_supported error.
+func (m *Manager) ExecuteEmbed(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
+	return m.executeUnary(ctx, providers, req, func(execCtx context.Context, provider string) (Response, error) {
+		return m.executeEmbedWithProvider(execCtx, provider, req, opts)
+	})
+}
+
+// ExecuteLive opens a realtime session using the configured selector and executor.
+// Opening the session counts as the request for auth selection and cooldowns;
+// providers whose executor does not implement LiveExecutor are skipped with a
+// not_supported error.
+func (m *Manager) ExecuteLive(ctx context.Context, providers []string, req Request, opts Options) (LiveConn, error) {
+	var conn LiveConn
+	_, err := m.executeUnary(ctx, providers, req, func(execCtx context.Context, provider string) (Response, error) {
+		return m.executeLiveWithProvider(execCtx, provider, req, opts, &conn)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// executeUnary runs fn across the selected providers with the manager's retry policy
+// and admission queue.
+func (m *Manager) executeUnary(ctx context.Context, providers []string, req Request, fn func(context.Context, string) (Response, error)) (Response, error) {
+	var queuedAt time.Time
+	for {
+		resp, err := m.executeUnaryOnce(ctx, providers, req, fn)
+		if err == nil || !m.awaitAdmission(ctx, providers, req.Model, err, &queuedAt) {
+			return resp, err
+		}
+	}
+}
+
+func (m *Manager) executeUnaryOnce(ctx context.Context, providers []string, req Request, fn func(context.Context, string) (Response, error)) (Response, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
 	selected := m.selectProviders(req.Model, normalized)
 
-	retryTimes, maxWait := m.retrySettings()
+	retryTimes, maxWait := m.retrySettings(ctx)
 	attempts := retryTimes + 1
 	if attempts < 1 {
 		attempts = 1
@@ -367,7 +469,7 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Requ
 		start := time.Now()
 		resp, errExec := m.executeProvidersOnce(ctx, selected, func(execCtx context.Context, provider string) (Response, error) {
 			lastProvider = provider
-			return m.executeCountWithProvider(execCtx, provider, req, opts)
+			return fn(execCtx, provider)
 		})
 		latency := time.Since(start)
 
@@ -401,15 +503,28 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Requ
 
 // ExecuteStream performs a streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model with weighted selection based on performance.
+// With hedging enabled, a stream whose first chunk is late is raced against a second
+// provider or auth; batch (PriorityLow) requests are never hedged. Like Execute, it
+// waits in the admission queue when every auth is cooling down.
 // Stats tracking is now consolidated in executeStreamWithProvider to reduce wrapper overhead.
 func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req Request, opts Options) (<-chan StreamChunk, error) {
+	var queuedAt time.Time
+	for {
+		chunks, err := m.executeStream(ctx, providers, req, opts)
+		if err == nil || !m.awaitAdmission(ctx, providers, req.Model, err, &queuedAt) {
+			return chunks, err
+		}
+	}
+}
+
+func (m *Manager) executeStream(ctx context.Context, providers []string, req Request, opts Options) (<-chan StreamChunk, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
 	selected := m.selectProviders(req.Model, normalized)
 
-	retryTimes, maxWait := m.retrySettings()
+	retryTimes, maxWait := m.retrySettings(ctx)
 	attempts := retryTimes + 1
 	if attempts < 1 {
 		attempts = 1
@@ -426,9 +541,16 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req Req
 		}
 
 		// Stats are now tracked inside executeStreamWithProvider - no need for wrapStreamForStats
-		chunks, errStream := m.executeStreamProvidersOnce(ctx, selected, func(execCtx context.Context, provider string) (<-chan StreamChunk, error) {
+		execFn := func(execCtx context.Context, provider string) (<-chan StreamChunk, error) {
 			return m.executeStreamWithProvider(execCtx, provider, req, opts)
-		})
+		}
+		var chunks <-chan StreamChunk
+		var errStream error
+		if policy := m.hedgePolicy.Load(); policy != nil && PriorityFromContext(ctx) == PriorityNormal && !singleAttempt(ctx) {
+			chunks, errStream = m.executeStreamHedged(ctx, selected, req.Model, policy, execFn)
+		} else {
+			chunks, errStream = m.executeStreamProvidersOnce(ctx, selected, execFn)
+		}
 
 		if errStream == nil {
 			if acquiredBudget {
@@ -695,10 +817,19 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts Opt
 	// Collect candidate pointers under lock (cheap - no cloning yet)
 	candidatePtrs := make([]*Auth, 0, len(m.auths))
 	registryRef := registry.GetGlobalRegistry()
+	scope := AuthScopeFromContext(ctx)
+	tagged := false
 	for _, candidate := range m.auths {
 		if candidate.Provider != provider || candidate.Disabled {
 			continue
 		}
+		if scope != nil && !scope.allows(provider, candidate.ID, candidate.Label) {
+			continue
+		}
+		if !scope.allowsTags(candidate.Attributes, candidate.Metadata) {
+			continue
+		}
+		tagged = true
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
@@ -709,6 +840,9 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts Opt
 	}
 	if len(candidatePtrs) == 0 {
 		m.mu.RUnlock()
+		if !tagged && scope != nil && len(scope.Tags) > 0 {
+			return nil, nil, scope.tagMismatchError(provider)
+		}
 		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
 
@@ -761,13 +895,26 @@ func (m *Manager) pickNextFromRegistry(ctx context.Context, provider, model stri
 
 	var entries []*AuthEntry
 	registryRef := registry.GetGlobalRegistry()
+	hedge := hedgeFromContext(ctx)
+	scope := AuthScopeFromContext(ctx)
+	tagged := false
 	for _, entry := range m.registry.ListByProvider(provider) {
 		if entry.IsDisabled() {
 			continue
 		}
+		if scope != nil {
+			meta := entry.Metadata()
+			if !scope.allows(provider, entry.ID(), meta.Label) || !scope.allowsTags(meta.Attributes, meta.Metadata) {
+				continue
+			}
+		}
+		tagged = true
 		if _, used := tried[entry.ID()]; used {
 			continue
 		}
+		if hedge != nil && hedge.claimed(entry.ID()) {
+			continue
+		}
 		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(entry.ID(), modelKey) {
 			continue
 		}
@@ -775,16 +922,25 @@ func (m *Manager) pickNextFromRegistry(ctx context.Context, provider, model stri
 	}
 
 	if len(entries) == 0 {
+		if !tagged && scope != nil && len(scope.Tags) > 0 {
+			return nil, nil, scope.tagMismatchError(provider)
+		}
 		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
+	if PriorityFromContext(ctx) == PriorityLow {
+		entries = idleEntries(entries)
+	}
 
-	selected, errPick := m.registry.Pick(ctx, provider, model, opts, entries)
+	selected, errPick := m.pickByAffinity(ctx, provider, model, opts, entries)
 	if errPick != nil {
 		return nil, nil, errPick
 	}
 	if selected == nil {
 		return nil, nil, &Error{Code: "auth_not_found", Message: "selector returned no auth"}
 	}
+	if hedge != nil {
+		hedge.claim(selected.ID())
+	}
 
 	return selected.ToAuth(), executor, nil
 }
@@ -895,6 +1051,17 @@ func (m *Manager) roundTripperFor(auth *Auth) http.RoundTripper {
 	return p.RoundTripperFor(auth)
 }
 
+// attemptContext returns the context an executor runs one attempt with auth in.
+func (m *Manager) attemptContext(ctx context.Context, auth *Auth) context.Context {
+	if rt := m.roundTripperFor(auth); rt != nil {
+		ctx = context.WithValue(ctx, roundTripperContextKey{}, rt)
+	}
+	if qm := m.GetQuotaManager(); qm != nil {
+		ctx = context.WithValue(ctx, quotaReporterContextKey{}, qm)
+	}
+	return ctx
+}
+
 // RoundTripperProvider defines a minimal provider of per-auth HTTP transports.
 type RoundTripperProvider interface {
 	RoundTripperFor(auth *Auth) http.RoundTripper
@@ -1012,6 +1179,14 @@ func (m *Manager) RetryBudgetStats() (available, max int64) {
 	return m.retryBudget.Available(), m.retryBudget.MaxCapacity()
 }
 
+// SetQuotaOverrides overrides the quota configs of the QuotaManager, if it is
+// the configured selector.
+func (m *Manager) SetQuotaOverrides(overrides *QuotaOverrides) {
+	if qm := m.GetQuotaManager(); qm != nil {
+		qm.SetQuotaOverrides(overrides)
+	}
+}
+
 // GetQuotaManager returns the QuotaManager if it is the configured selector.
 func (m *Manager) GetQuotaManager() *QuotaManager {
 	if m == nil {
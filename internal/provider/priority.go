@@ -0,0 +1,57 @@
+package provider
+
+import "context"
+
+// Priority classifies a request for scheduling. Interactive traffic runs at
+// PriorityNormal; background work such as batch jobs runs at PriorityLow.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityLow
+)
+
+// String returns the configuration name of the priority class.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+type priorityContextKey struct{}
+
+// WithPriority returns a context that carries the scheduling priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey{}, p)
+}
+
+// PriorityFromContext returns the scheduling priority carried by ctx,
+// defaulting to PriorityNormal.
+func PriorityFromContext(ctx context.Context) Priority {
+	if ctx == nil {
+		return PriorityNormal
+	}
+	if p, ok := ctx.Value(priorityContextKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// idleEntries returns the entries with no in-flight requests, or all entries
+// when every one of them is busy. Low-priority requests use it so they only
+// compete with interactive traffic for an auth when nothing else is free.
+func idleEntries(entries []*AuthEntry) []*AuthEntry {
+	idle := make([]*AuthEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Quota.ActiveRequests.Load() == 0 {
+			idle = append(idle, entry)
+		}
+	}
+	if len(idle) == 0 {
+		return entries
+	}
+	return idle
+}
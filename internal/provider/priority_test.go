@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPriorityFromContext(t *testing.T) {
+	if got := PriorityFromContext(context.Background()); got != PriorityNormal {
+		t.Errorf("default priority = %v, want normal", got)
+	}
+	ctx := WithPriority(context.Background(), PriorityLow)
+	if got := PriorityFromContext(ctx); got != PriorityLow {
+		t.Errorf("priority = %v, want low", got)
+	}
+}
+
+func TestIdleEntriesPrefersIdleAuths(t *testing.T) {
+	busy := NewAuthEntry(&Auth{ID: "busy", Provider: "gemini"})
+	busy.IncrementActiveRequests()
+	idle := NewAuthEntry(&Auth{ID: "idle", Provider: "gemini"})
+
+	got := idleEntries([]*AuthEntry{busy, idle})
+	if len(got) != 1 || got[0].ID() != "idle" {
+		t.Fatalf("expected only the idle entry, got %d entries", len(got))
+	}
+
+	got = idleEntries([]*AuthEntry{busy})
+	if len(got) != 1 || got[0].ID() != "busy" {
+		t.Fatalf("expected busy entry when nothing is idle, got %d entries", len(got))
+	}
+}
@@ -30,7 +30,8 @@ func (m *Manager) normalizeProviders(providers []string) []string {
 
 // selectProviders returns providers ordered for execution.
 // It filters out providers with open circuit breakers (unavailable) and applies
-// performance-based scoring to the remaining candidates.
+// performance-based scoring to the remaining candidates; cost-aware routing,
+// when enabled, then orders them by marginal cost.
 // If all breakers are open, returns original list to allow fallback probes.
 func (m *Manager) selectProviders(model string, providers []string) []string {
 	if len(providers) <= 1 {
@@ -51,7 +52,11 @@ func (m *Manager) selectProviders(model string, providers []string) []string {
 		return providers
 	}
 
-	return m.providerStats.SortByScore(available, model)
+	sorted := m.providerStats.SortByScore(available, model)
+	if policy := m.costPolicy.Load(); policy != nil {
+		sorted = m.sortByCost(sorted, model, policy)
+	}
+	return sorted
 }
 
 // recordProviderResult records success/failure for weighted selection.
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,8 +20,21 @@ type providerMetrics struct {
 	totalLatencyNs atomic.Int64 // cumulative latency in nanoseconds
 	lastUsed       atomic.Int64 // unix nano timestamp
 	lastSuccess    atomic.Int64 // unix nano timestamp
+
+	ttftMu   sync.Mutex
+	ttft     [ttftWindow]time.Duration // recent time-to-first-token samples, used as a ring
+	ttftLen  int
+	ttftNext int
 }
 
+const (
+	// ttftWindow is the number of recent time-to-first-token samples kept.
+	ttftWindow = 64
+	// ttftMinSamples is the number of samples needed before TTFTPercentile
+	// reports a value.
+	ttftMinSamples = 5
+)
+
 // NewProviderStats creates a new stats tracker.
 func NewProviderStats() *ProviderStats {
 	return &ProviderStats{
@@ -67,6 +81,41 @@ func (ps *ProviderStats) RecordFailure(provider, model string) {
 	m.lastUsed.Store(time.Now().UnixNano())
 }
 
+// RecordTTFT records the time it took a stream to deliver its first chunk.
+func (ps *ProviderStats) RecordTTFT(provider, model string, ttft time.Duration) {
+	m := ps.getOrCreate(provider + ":" + model)
+	m.ttftMu.Lock()
+	m.ttft[m.ttftNext] = ttft
+	m.ttftNext = (m.ttftNext + 1) % ttftWindow
+	m.ttftLen = min(m.ttftLen+1, ttftWindow)
+	m.ttftMu.Unlock()
+}
+
+// TTFTPercentile returns the p-th percentile (0-100) of recent time-to-first-token
+// samples for a provider:model. ok is false until enough samples are recorded.
+func (ps *ProviderStats) TTFTPercentile(provider, model string, p float64) (ttft time.Duration, ok bool) {
+	key := provider + ":" + model
+	ps.mu.RLock()
+	m := ps.stats[key]
+	ps.mu.RUnlock()
+	if m == nil {
+		return 0, false
+	}
+
+	m.ttftMu.Lock()
+	samples := slices.Clone(m.ttft[:m.ttftLen])
+	m.ttftMu.Unlock()
+	if len(samples) < ttftMinSamples {
+		return 0, false
+	}
+	slices.Sort(samples)
+	idx := min(int(float64(len(samples)-1)*p/100), len(samples)-1)
+	if idx < 0 {
+		idx = 0
+	}
+	return samples[idx], true
+}
+
 // GetScore returns a weighted score for provider selection.
 // Higher score = better provider. Range: 0.0 to 1.0
 func (ps *ProviderStats) GetScore(provider, model string) float64 {
@@ -2,7 +2,10 @@ package provider
 
 import (
 	"context"
+	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 // ProviderStrategy defines provider-specific selection logic.
@@ -29,12 +32,89 @@ type BackgroundRefresher interface {
 	StartRefresh(ctx context.Context, auth *Auth, state *AuthQuotaState) <-chan *RealQuotaSnapshot
 }
 
+// ModelAwareStrategy is optionally implemented by strategies whose accounts
+// limit some models apart from the account as a whole.
+type ModelAwareStrategy interface {
+	ProviderStrategy
+	// AllowsModel reports whether auth may serve model.
+	AllowsModel(auth *Auth, model string, config *ProviderQuotaConfig) bool
+	// ScoreModel returns the priority added to Score for a request for model,
+	// at least modelQuotaExhaustedPriority when auth has no quota left for it.
+	ScoreModel(auth *Auth, model string) int64
+	// RecordModelUsage tracks a successful request for model made with authID
+	// of provider.
+	RecordModelUsage(authID, provider, model string)
+}
+
+const modelQuotaExhaustedPriority = 5000
+
 // RealQuotaSnapshot holds data from real provider APIs.
 type RealQuotaSnapshot struct {
-	RemainingFraction float64   // 0.0-1.0 (from Antigravity API)
-	RemainingTokens   int64     // Absolute remaining
-	WindowResetAt     time.Time // When quota window resets
-	FetchedAt         time.Time // When this was fetched
+	RemainingFraction float64       // 0.0-1.0 (from Antigravity API)
+	RemainingTokens   int64         // Absolute remaining
+	WindowResetAt     time.Time     // When quota window resets
+	FetchedAt         time.Time     // When this was fetched
+	Windows           []QuotaWindow // Per-window usage, for providers reporting several
+}
+
+// QuotaWindow is the usage of one of the windows a provider limits an
+// account by.
+type QuotaWindow struct {
+	Name         string        // e.g. "5h" or "primary"
+	UsedFraction float64       // 0.0-1.0
+	Duration     time.Duration // Length of the window, when reported
+	ResetAt      time.Time     // When the window resets, when reported
+}
+
+// snapshotFromWindows builds a RealQuotaSnapshot in which the most used window
+// decides the remaining fraction and the reset time.
+func snapshotFromWindows(windows []QuotaWindow, now time.Time) *RealQuotaSnapshot {
+	if len(windows) == 0 {
+		return nil
+	}
+	snapshot := &RealQuotaSnapshot{RemainingFraction: 1, Windows: windows, FetchedAt: now}
+	for _, w := range windows {
+		remaining := 1 - w.UsedFraction
+		if remaining < snapshot.RemainingFraction ||
+			(remaining == snapshot.RemainingFraction && w.ResetAt.After(snapshot.WindowResetAt)) {
+			snapshot.RemainingFraction = remaining
+			snapshot.WindowResetAt = w.ResetAt
+		}
+	}
+	return snapshot
+}
+
+// clampFraction limits f to 0.0-1.0.
+func clampFraction(f float64) float64 {
+	if f < 0 {
+		return 0
+	}
+	return min(f, 1)
+}
+
+// windowLimiter spreads limit requests evenly over window. It remembers both
+// so that it is rebuilt when the quota config changes.
+type windowLimiter struct {
+	*rate.Limiter
+	limit  int64
+	window time.Duration
+}
+
+// loadWindowLimiter returns the limiter of authID in limiters, replacing it
+// when it was built for another limit or window.
+func loadWindowLimiter(limiters *sync.Map, authID string, limit int64, window time.Duration, burst int) *rate.Limiter {
+	if v, ok := limiters.Load(authID); ok {
+		if l := v.(*windowLimiter); l.limit == limit && l.window == window {
+			return l.Limiter
+		}
+	}
+	l := &windowLimiter{
+		Limiter: rate.NewLimiter(rate.Every(window/time.Duration(limit)), burst),
+		limit:   limit,
+		window:  window,
+	}
+	limiters.Store(authID, l)
+	return l.Limiter
 }
 
 // DefaultStrategy is used for providers without specific strategy.
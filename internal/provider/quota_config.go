@@ -1,6 +1,9 @@
 package provider
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 type QuotaType int
 
@@ -9,6 +12,18 @@ const (
 	QuotaTypeTokens
 )
 
+// ParseQuotaType parses the String form of a QuotaType.
+func ParseQuotaType(s string) (QuotaType, bool) {
+	switch s {
+	case "requests":
+		return QuotaTypeRequests, true
+	case "tokens":
+		return QuotaTypeTokens, true
+	default:
+		return 0, false
+	}
+}
+
 func (q QuotaType) String() string {
 	switch q {
 	case QuotaTypeRequests:
@@ -28,6 +43,9 @@ type ProviderQuotaConfig struct {
 	GroupResolver  QuotaGroupResolver
 	StaggerBucket  time.Duration
 	StickyEnabled  bool
+	// RefuseOverage refuses premium models on accounts whose premium request
+	// allowance is used up, even where the account permits overage.
+	RefuseOverage bool
 }
 
 var defaultProviderQuotaConfigs = map[string]*ProviderQuotaConfig{
@@ -49,6 +67,15 @@ var defaultProviderQuotaConfigs = map[string]*ProviderQuotaConfig{
 		StaggerBucket:  30 * time.Minute,
 		StickyEnabled:  true,
 	},
+	"codex": {
+		Provider:       "codex",
+		WindowDuration: 5 * time.Hour,
+		QuotaType:      QuotaTypeTokens,
+		EstimatedLimit: 1_000_000,
+		GroupResolver:  nil,
+		StaggerBucket:  30 * time.Minute,
+		StickyEnabled:  true,
+	},
 	"copilot": {
 		Provider:       "copilot",
 		WindowDuration: 24 * time.Hour,
@@ -58,6 +85,15 @@ var defaultProviderQuotaConfigs = map[string]*ProviderQuotaConfig{
 		StaggerBucket:  2 * time.Hour,
 		StickyEnabled:  true,
 	},
+	"github-copilot": {
+		Provider:       "github-copilot",
+		WindowDuration: 24 * time.Hour,
+		QuotaType:      QuotaTypeRequests,
+		EstimatedLimit: 10_000,
+		GroupResolver:  nil,
+		StaggerBucket:  2 * time.Hour,
+		StickyEnabled:  true,
+	},
 	"gemini": {
 		Provider:       "gemini",
 		WindowDuration: 1 * time.Minute,
@@ -85,3 +121,91 @@ func GetProviderQuotaConfig(provider string) *ProviderQuotaConfig {
 	fallback.Provider = provider
 	return &fallback
 }
+
+// QuotaOverride replaces fields of a ProviderQuotaConfig. Zero fields and nil
+// pointers keep the value they override.
+type QuotaOverride struct {
+	WindowDuration time.Duration
+	QuotaType      *QuotaType
+	EstimatedLimit int64
+	StaggerBucket  time.Duration
+	StickyEnabled  *bool
+	RefuseOverage  *bool
+}
+
+func (o QuotaOverride) apply(cfg *ProviderQuotaConfig) *ProviderQuotaConfig {
+	out := *cfg
+	if o.WindowDuration > 0 {
+		out.WindowDuration = o.WindowDuration
+	}
+	if o.QuotaType != nil {
+		out.QuotaType = *o.QuotaType
+	}
+	if o.EstimatedLimit > 0 {
+		out.EstimatedLimit = o.EstimatedLimit
+	}
+	if o.StaggerBucket > 0 {
+		out.StaggerBucket = o.StaggerBucket
+	}
+	if o.StickyEnabled != nil {
+		out.StickyEnabled = *o.StickyEnabled
+	}
+	if o.RefuseOverage != nil {
+		out.RefuseOverage = *o.RefuseOverage
+	}
+	return &out
+}
+
+// QuotaOverrides overrides the built-in quota configs of providers, keyed by
+// provider, and of single auths, keyed by auth ID. An auth override applies
+// on top of its provider's config.
+type QuotaOverrides struct {
+	Providers map[string]QuotaOverride
+	Auths     map[string]QuotaOverride
+}
+
+// quotaConfigs is the resolved form of QuotaOverrides.
+type quotaConfigs struct {
+	providers map[string]*ProviderQuotaConfig
+	auths     map[string]QuotaOverride
+}
+
+func resolveQuotaConfigs(overrides *QuotaOverrides) *quotaConfigs {
+	resolved := &quotaConfigs{providers: make(map[string]*ProviderQuotaConfig)}
+	if overrides == nil {
+		return resolved
+	}
+	for provider, override := range overrides.Providers {
+		resolved.providers[provider] = override.apply(GetProviderQuotaConfig(provider))
+	}
+	resolved.auths = overrides.Auths
+	return resolved
+}
+
+func (c *quotaConfigs) provider(provider string) *ProviderQuotaConfig {
+	if c != nil {
+		if cfg, ok := c.providers[provider]; ok {
+			return cfg
+		}
+	}
+	return GetProviderQuotaConfig(provider)
+}
+
+func (c *quotaConfigs) auth(provider *ProviderQuotaConfig, authID string) *ProviderQuotaConfig {
+	if c != nil {
+		if override, ok := c.auths[authID]; ok {
+			return override.apply(provider)
+		}
+	}
+	return provider
+}
+
+// QuotaProviders returns the providers with a built-in quota config, sorted.
+func QuotaProviders() []string {
+	providers := make([]string, 0, len(defaultProviderQuotaConfigs))
+	for provider := range defaultProviderQuotaConfigs {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	return providers
+}
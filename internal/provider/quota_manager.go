@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
-	"math/rand/v2"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -98,12 +97,14 @@ func (s *AuthQuotaState) SetLearnedCooldown(d time.Duration) {
 // AuthQuotaStateSnapshot is a point-in-time copy of AuthQuotaState for external use.
 // All fields are regular values (not atomics) for easy consumption.
 type AuthQuotaStateSnapshot struct {
-	CooldownUntil   time.Time
-	ActiveRequests  int64
-	TotalTokensUsed int64
-	LastExhaustedAt time.Time
-	LearnedLimit    int64
-	LearnedCooldown time.Duration
+	CooldownUntil   time.Time     `json:"cooldown_until"`
+	ActiveRequests  int64         `json:"active_requests"`
+	TotalTokensUsed int64         `json:"total_tokens_used"`
+	LastExhaustedAt time.Time     `json:"last_exhausted_at"`
+	LearnedLimit    int64         `json:"learned_limit"`
+	LearnedCooldown time.Duration `json:"learned_cooldown"`
+	// RealQuota is not saved: it is stale by the time it could be restored.
+	RealQuota *RealQuotaSnapshot `json:"-"`
 }
 
 // Snapshot creates a point-in-time snapshot of the state.
@@ -115,6 +116,7 @@ func (s *AuthQuotaState) Snapshot() *AuthQuotaStateSnapshot {
 		LastExhaustedAt: s.GetLastExhaustedAt(),
 		LearnedLimit:    s.LearnedLimit.Load(),
 		LearnedCooldown: s.GetLearnedCooldown(),
+		RealQuota:       s.GetRealQuota(),
 	}
 }
 
@@ -132,6 +134,7 @@ type QuotaManager struct {
 	shards     [numQuotaShards]*quotaShard
 	sticky     *StickyStore
 	strategies map[string]ProviderStrategy
+	configs    atomic.Pointer[quotaConfigs]
 
 	stopChan chan struct{}
 	stopOnce sync.Once
@@ -139,6 +142,10 @@ type QuotaManager struct {
 
 	refreshMu      sync.Mutex
 	refreshCancels map[string]context.CancelFunc
+
+	// restored holds saved states not yet applied by RegisterAuth.
+	restoreMu sync.Mutex
+	restored  map[string]*savedQuotaState
 }
 
 var quotaHasherPool = sync.Pool{
@@ -169,7 +176,10 @@ func NewQuotaManager() *QuotaManager {
 
 	m.strategies["antigravity"] = &AntigravityStrategy{}
 	m.strategies["claude"] = &ClaudeStrategy{}
-	m.strategies["copilot"] = &CopilotStrategy{}
+	m.strategies["codex"] = &CodexStrategy{}
+	copilot := &CopilotStrategy{}
+	m.strategies["copilot"] = copilot
+	m.strategies["github-copilot"] = copilot
 	m.strategies["gemini"] = &GeminiStrategy{}
 
 	return m
@@ -182,6 +192,24 @@ func (m *QuotaManager) getStrategy(provider string) ProviderStrategy {
 	return &DefaultStrategy{}
 }
 
+// SetQuotaOverrides replaces the overrides applied to the built-in provider
+// quota configs; nil restores the built-in configs.
+func (m *QuotaManager) SetQuotaOverrides(overrides *QuotaOverrides) {
+	m.configs.Store(resolveQuotaConfigs(overrides))
+}
+
+// ProviderConfig returns the quota config of provider, overrides applied.
+func (m *QuotaManager) ProviderConfig(provider string) *ProviderQuotaConfig {
+	return m.configs.Load().provider(provider)
+}
+
+// AuthConfig returns the quota config of the auth authID of provider,
+// overrides applied.
+func (m *QuotaManager) AuthConfig(provider, authID string) *ProviderQuotaConfig {
+	configs := m.configs.Load()
+	return configs.auth(configs.provider(provider), authID)
+}
+
 func (m *QuotaManager) getShard(authID string) *quotaShard {
 	return m.shards[quotaHashKey(authID)%numQuotaShards]
 }
@@ -219,13 +247,17 @@ func (m *QuotaManager) Pick(
 	}
 
 	now := time.Now()
-	config := GetProviderQuotaConfig(provider)
+	configs := m.configs.Load()
+	config := configs.provider(provider)
 	strategy := m.getStrategy(provider)
 
-	available := m.filterAvailable(auths, model, now)
+	available := m.filterAvailable(auths, model, now, strategy, func(auth *Auth) *ProviderQuotaConfig {
+		return configs.auth(config, auth.ID)
+	})
 	if len(available) == 0 {
 		return nil, m.buildRetryError(auths, now)
 	}
+	available = primaryAuths(available)
 
 	if len(available) == 1 {
 		m.incrementActive(available[0].ID)
@@ -236,7 +268,7 @@ func (m *QuotaManager) Pick(
 		key := provider + ":" + model
 		if authID, ok := m.sticky.Get(key); ok {
 			for _, auth := range available {
-				if auth.ID == authID {
+				if auth.ID == authID && !overModelQuota(strategy, auth, model) {
 					m.incrementActive(auth.ID)
 					return auth, nil
 				}
@@ -244,7 +276,9 @@ func (m *QuotaManager) Pick(
 		}
 	}
 
-	selected := m.selectWithStrategy(available, config, strategy)
+	selected := m.selectWithStrategy(available, model, func(auth *Auth) *ProviderQuotaConfig {
+		return configs.auth(config, auth.ID)
+	}, strategy)
 
 	if config.StickyEnabled {
 		m.sticky.Set(provider+":"+model, selected.ID)
@@ -254,17 +288,22 @@ func (m *QuotaManager) Pick(
 	return selected, nil
 }
 
-func (m *QuotaManager) selectWithStrategy(auths []*Auth, config *ProviderQuotaConfig, strategy ProviderStrategy) *Auth {
+func (m *QuotaManager) selectWithStrategy(auths []*Auth, model string, config func(*Auth) *ProviderQuotaConfig, strategy ProviderStrategy) *Auth {
 	type scored struct {
 		auth     *Auth
 		priority int64
 	}
 
 	candidates := make([]scored, 0, len(auths))
+	modelAware, _ := strategy.(ModelAwareStrategy)
 
 	for _, auth := range auths {
 		state := m.getState(auth.ID)
-		priority := strategy.Score(auth, state, config)
+		priority := strategy.Score(auth, state, config(auth))
+		if modelAware != nil {
+			priority += modelAware.ScoreModel(auth, model)
+		}
+		priority /= int64(AuthWeight(auth.Metadata))
 		candidates = append(candidates, scored{auth: auth, priority: priority})
 	}
 
@@ -286,32 +325,53 @@ func (m *QuotaManager) selectWithStrategy(auths []*Auth, config *ProviderQuotaCo
 	}
 
 	if similarCount > 1 {
-		return candidates[rand.N(similarCount)].auth
+		return candidates[pickWeighted(similarCount, func(i int) float64 {
+			return float64(AuthWeight(candidates[i].auth.Metadata))
+		})].auth
 	}
 
 	return candidates[0].auth
 }
 
-func (m *QuotaManager) filterAvailable(auths []*Auth, model string, now time.Time) []*Auth {
+func (m *QuotaManager) filterAvailable(auths []*Auth, model string, now time.Time, strategy ProviderStrategy, config func(*Auth) *ProviderQuotaConfig) []*Auth {
 	available := make([]*Auth, 0, len(auths))
-
 	for _, auth := range auths {
-		if auth.Disabled {
-			continue
-		}
-
-		state := m.getState(auth.ID)
-
-		switch m.checkAvailability(state, auth, model, now) {
-		case availabilityBlocked:
-			continue
-		case availabilityAvailable, availabilityUnknown:
+		if m.usable(auth, model, now, strategy, config) {
 			available = append(available, auth)
 		}
 	}
 	return available
 }
 
+func (m *QuotaManager) usable(auth *Auth, model string, now time.Time, strategy ProviderStrategy, config func(*Auth) *ProviderQuotaConfig) bool {
+	if auth.Disabled {
+		return false
+	}
+	if modelAware, ok := strategy.(ModelAwareStrategy); ok && !modelAware.AllowsModel(auth, model, config(auth)) {
+		return false
+	}
+	return m.checkAvailability(m.getState(auth.ID), auth, model, now) != availabilityBlocked
+}
+
+// KeepsBinding reports whether a request for model may stay on auth, to which
+// an earlier request was bound: Pick could select it, and it has the quota
+// left for model.
+func (m *QuotaManager) KeepsBinding(provider, model string, auth *Auth) bool {
+	configs := m.configs.Load()
+	config := configs.provider(provider)
+	strategy := m.getStrategy(provider)
+	return m.usable(auth, model, time.Now(), strategy, func(auth *Auth) *ProviderQuotaConfig {
+		return configs.auth(config, auth.ID)
+	}) && !overModelQuota(strategy, auth, model)
+}
+
+// overModelQuota reports whether auth has no quota left for model, in which
+// case a sticky binding to it gives way to the other auths.
+func overModelQuota(strategy ProviderStrategy, auth *Auth, model string) bool {
+	modelAware, ok := strategy.(ModelAwareStrategy)
+	return ok && modelAware.ScoreModel(auth, model) >= modelQuotaExhaustedPriority
+}
+
 type availabilityStatus int
 
 const (
@@ -412,6 +472,10 @@ func (m *QuotaManager) RecordRequestEnd(authID, provider string, tokens int64, f
 		// the quota has been restored.
 		state.SetCooldownUntil(time.Time{})
 
+		if m.AuthConfig(provider, authID).QuotaType == QuotaTypeRequests {
+			// The usage counted against a request quota is the request.
+			tokens = 1
+		}
 		if tokens > 0 {
 			strategy := m.getStrategy(provider)
 			strategy.RecordUsage(state, tokens)
@@ -419,6 +483,14 @@ func (m *QuotaManager) RecordRequestEnd(authID, provider string, tokens int64, f
 	}
 }
 
+// RecordModelUsage tracks a successful request for model against the
+// per-model quota of authID, for strategies that keep one.
+func (m *QuotaManager) RecordModelUsage(authID, provider, model string) {
+	if modelAware, ok := m.getStrategy(provider).(ModelAwareStrategy); ok {
+		modelAware.RecordModelUsage(authID, provider, model)
+	}
+}
+
 func (m *QuotaManager) RecordQuotaHit(authID, provider, model string, cooldown *time.Duration) {
 	state := m.getOrCreateState(authID)
 	strategy := m.getStrategy(provider)
@@ -512,10 +584,13 @@ func (m *QuotaManager) cleanup() {
 
 var _ Selector = (*QuotaManager)(nil)
 
+// RegisterAuth restores the saved quota state of auth, if any, and starts the
+// background quota refresh of providers that support it.
 func (m *QuotaManager) RegisterAuth(ctx context.Context, auth *Auth) {
 	if auth == nil {
 		return
 	}
+	m.restoreAuth(auth)
 	strategy := m.getStrategy(auth.Provider)
 
 	refresher, ok := strategy.(BackgroundRefresher)
@@ -592,6 +667,29 @@ func (m *QuotaManager) handleQuotaSnapshotUpdate(state *AuthQuotaState, snapshot
 	}
 }
 
+// quotaReporterContextKey carries the QuotaManager of an execution attempt.
+type quotaReporterContextKey struct{}
+
+// ReportRealQuota records the quota an upstream reported on a response to a
+// request made with authID. It does nothing unless ctx is the context of an
+// attempt run by a Manager selecting with a QuotaManager.
+func ReportRealQuota(ctx context.Context, authID string, snapshot *RealQuotaSnapshot) {
+	if qm, _ := ctx.Value(quotaReporterContextKey{}).(*QuotaManager); qm != nil {
+		qm.RecordRealQuota(authID, snapshot)
+	}
+}
+
+// RecordRealQuota records quota reported by the upstream of authID, as the
+// background refreshers do.
+func (m *QuotaManager) RecordRealQuota(authID string, snapshot *RealQuotaSnapshot) {
+	if authID == "" || snapshot == nil {
+		return
+	}
+	state := m.getOrCreateState(authID)
+	state.SetRealQuota(snapshot)
+	m.handleQuotaSnapshotUpdate(state, snapshot)
+}
+
 func (m *QuotaManager) UnregisterAuth(authID string) {
 	m.refreshMu.Lock()
 	if cancel, exists := m.refreshCancels[authID]; exists {
@@ -2,8 +2,12 @@ package provider
 
 import (
 	"context"
+	"net/http"
+	"strconv"
 	"testing"
 	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
 )
 
 func newTestState(tokensUsed, activeRequests int64) *AuthQuotaState {
@@ -264,6 +268,7 @@ func TestQuotaConfig_GetProviderQuotaConfig(t *testing.T) {
 		{"antigravity", false},
 		{"claude", true},
 		{"copilot", true},
+		{"github-copilot", true},
 		{"gemini", true},
 		{"unknown", true},
 	}
@@ -288,6 +293,7 @@ func TestQuotaManager_GetStrategy(t *testing.T) {
 		{"antigravity", "*provider.AntigravityStrategy"},
 		{"claude", "*provider.ClaudeStrategy"},
 		{"copilot", "*provider.CopilotStrategy"},
+		{"github-copilot", "*provider.CopilotStrategy"},
 		{"gemini", "*provider.GeminiStrategy"},
 		{"unknown", "*provider.DefaultStrategy"},
 	}
@@ -365,3 +371,192 @@ func TestAntigravityStrategyTokenPenalty(t *testing.T) {
 		t.Errorf("expected needs-refresh penalty of at least %d, got %d", expectedRefreshPenalty, needsRefreshScore)
 	}
 }
+
+func TestQuotaManager_QuotaOverrides(t *testing.T) {
+	m := NewQuotaManager()
+	requests := QuotaTypeRequests
+	sticky := false
+	m.SetQuotaOverrides(&QuotaOverrides{
+		Providers: map[string]QuotaOverride{
+			"claude": {EstimatedLimit: 2_000_000},
+			"iflow":  {WindowDuration: 24 * time.Hour, QuotaType: &requests, EstimatedLimit: 500},
+		},
+		Auths: map[string]QuotaOverride{
+			"claude-max": {EstimatedLimit: 10_000_000, StickyEnabled: &sticky},
+		},
+	})
+
+	claude := m.ProviderConfig("claude")
+	if claude.EstimatedLimit != 2_000_000 || claude.WindowDuration != 5*time.Hour || !claude.StickyEnabled {
+		t.Errorf("claude config = %+v, want the built-in config with limit 2000000", claude)
+	}
+	if maxAuth := m.AuthConfig("claude", "claude-max"); maxAuth.EstimatedLimit != 10_000_000 || maxAuth.StickyEnabled {
+		t.Errorf("claude-max config = %+v, want its own limit without stickiness", maxAuth)
+	}
+	if other := m.AuthConfig("claude", "claude-pro"); other != claude {
+		t.Errorf("claude-pro config = %+v, want the provider config", other)
+	}
+	if iflow := m.ProviderConfig("iflow"); iflow.QuotaType != QuotaTypeRequests || iflow.EstimatedLimit != 500 || iflow.Provider != "iflow" {
+		t.Errorf("iflow config = %+v", iflow)
+	}
+
+	m.RecordRequestEnd("iflow-a", "iflow", 12_345, false)
+	if used := m.getState("iflow-a").TotalTokensUsed.Load(); used != 1 {
+		t.Errorf("iflow usage = %d, want one request", used)
+	}
+
+	m.SetQuotaOverrides(nil)
+	if got := m.ProviderConfig("claude").EstimatedLimit; got != 500_000 {
+		t.Errorf("claude limit after reset = %d, want the built-in 500000", got)
+	}
+}
+
+func TestClaudeQuotaFromHeaders(t *testing.T) {
+	now := time.Now()
+	reset5h := now.Add(2 * time.Hour).Truncate(time.Second)
+	reset7d := now.Add(72 * time.Hour).Truncate(time.Second)
+
+	h := http.Header{}
+	h.Set("anthropic-ratelimit-unified-status", "allowed")
+	h.Set("anthropic-ratelimit-unified-5h-utilization", "0.25")
+	h.Set("anthropic-ratelimit-unified-5h-reset", strconv.FormatInt(reset5h.Unix(), 10))
+	h.Set("anthropic-ratelimit-unified-7d-utilization", "0.6")
+	h.Set("anthropic-ratelimit-unified-7d-reset", strconv.FormatInt(reset7d.Unix(), 10))
+	snapshot := ClaudeQuotaFromHeaders(h, now)
+	if snapshot == nil || snapshot.RemainingFraction < 0.39 || snapshot.RemainingFraction > 0.41 || !snapshot.WindowResetAt.Equal(reset7d) {
+		t.Errorf("snapshot = %+v, want 40%% left until the 7d reset", snapshot)
+	}
+
+	h.Set("anthropic-ratelimit-unified-status", "rejected")
+	h.Set("anthropic-ratelimit-unified-reset", strconv.FormatInt(reset5h.Unix(), 10))
+	if snapshot = ClaudeQuotaFromHeaders(h, now); snapshot == nil || snapshot.RemainingFraction != 0 || !snapshot.WindowResetAt.Equal(reset5h) {
+		t.Errorf("rejected snapshot = %+v, want nothing left until %v", snapshot, reset5h)
+	}
+
+	if snapshot = ClaudeQuotaFromHeaders(http.Header{"Anthropic-Ratelimit-Tokens-Limit": {"1000"}}, now); snapshot != nil {
+		t.Errorf("snapshot without unified headers = %+v, want nil", snapshot)
+	}
+}
+
+func TestQuotaManager_ReportRealQuota(t *testing.T) {
+	m := NewQuotaManager()
+	manager := NewManager(nil, m, nil)
+	defer manager.Stop()
+	claudeA := &Auth{ID: "claude-a", Provider: "claude"}
+	claudeB := &Auth{ID: "claude-b", Provider: "claude"}
+	resetAt := time.Now().Add(3 * time.Hour)
+
+	ctx := manager.attemptContext(context.Background(), claudeA)
+	ReportRealQuota(ctx, claudeA.ID, &RealQuotaSnapshot{RemainingFraction: 0.4, FetchedAt: time.Now()})
+	ReportRealQuota(manager.attemptContext(context.Background(), claudeB), claudeB.ID,
+		&RealQuotaSnapshot{RemainingFraction: 0.9, FetchedAt: time.Now()})
+	selected, err := m.Pick(context.Background(), "claude", "test", Options{ForceRotate: true}, []*Auth{claudeA, claudeB})
+	if err != nil || selected.ID != "claude-b" {
+		t.Fatalf("Pick = %v, %v; want claude-b, which has more quota left", selected, err)
+	}
+
+	ReportRealQuota(ctx, claudeA.ID, &RealQuotaSnapshot{WindowResetAt: resetAt, FetchedAt: time.Now()})
+	if until := m.GetState(claudeA.ID).CooldownUntil; !until.Equal(resetAt) {
+		t.Errorf("exhausted auth cools down until %v, want the window reset %v", until, resetAt)
+	}
+	ReportRealQuota(context.Background(), claudeB.ID, &RealQuotaSnapshot{FetchedAt: time.Now()})
+	if m.GetState(claudeB.ID).RealQuota.RemainingFraction != 0.9 {
+		t.Errorf("quota reported outside an attempt was recorded")
+	}
+}
+
+func TestCodexQuotaSignals(t *testing.T) {
+	now := time.Now()
+	h := http.Header{}
+	h.Set("x-codex-primary-used-percent", "97.5")
+	h.Set("x-codex-primary-window-minutes", "300")
+	h.Set("x-codex-primary-reset-after-seconds", "1800")
+	h.Set("x-codex-secondary-used-percent", "40")
+	h.Set("x-codex-secondary-window-minutes", "10080")
+	snapshot := CodexQuotaFromHeaders(h, now)
+	if snapshot == nil || len(snapshot.Windows) != 2 || snapshot.RemainingFraction > 0.03 ||
+		!snapshot.WindowResetAt.Equal(now.Add(30*time.Minute)) || snapshot.Windows[0].Duration != 5*time.Hour {
+		t.Fatalf("header snapshot = %+v, want the primary window deciding", snapshot)
+	}
+
+	resetAt := now.Add(4 * time.Hour).Truncate(time.Second)
+	event := []byte(`{"type":"codex.rate_limits","rate_limits":{"primary":{"used_percent":100,"window_minutes":300,"resets_at":` +
+		strconv.FormatInt(resetAt.Unix(), 10) + `},"secondary":{"used_percent":12.5,"window_minutes":10080}}}`)
+	if snapshot = CodexQuotaFromEvent(event, now); snapshot == nil || snapshot.RemainingFraction != 0 || !snapshot.WindowResetAt.Equal(resetAt) {
+		t.Errorf("event snapshot = %+v, want nothing left until %v", snapshot, resetAt)
+	}
+	if snapshot = CodexQuotaFromEvent([]byte(`{"type":"response.completed"}`), now); snapshot != nil {
+		t.Errorf("snapshot from another event = %+v", snapshot)
+	}
+
+	m := NewQuotaManager()
+	nearlyOut := &Auth{ID: "codex-a", Provider: "codex"}
+	busy := &Auth{ID: "codex-b", Provider: "codex"}
+	m.RecordRealQuota(nearlyOut.ID, &RealQuotaSnapshot{RemainingFraction: 0.06, FetchedAt: now})
+	m.RecordRealQuota(busy.ID, &RealQuotaSnapshot{RemainingFraction: 0.5, FetchedAt: now})
+	m.RecordRequestStart(busy.ID)
+	m.RecordRequestStart(busy.ID)
+	selected, err := m.Pick(context.Background(), "codex", "gpt-5", Options{ForceRotate: true}, []*Auth{nearlyOut, busy})
+	if err != nil || selected.ID != busy.ID {
+		t.Errorf("Pick = %v, %v; want codex-b despite its load, codex-a is nearly out", selected, err)
+	}
+
+	m.RecordRealQuota(nearlyOut.ID, CodexQuotaFromEvent(event, now))
+	m.RecordQuotaHit(nearlyOut.ID, "codex", "gpt-5", nil)
+	if until := m.GetState(nearlyOut.ID).CooldownUntil; !until.Equal(resetAt) {
+		t.Errorf("cooldown after the usage limit = %v, want the reported reset %v", until, resetAt)
+	}
+}
+
+func TestCopilotPremiumRequests(t *testing.T) {
+	premium := parseCopilotPremium([]byte(`{"quota_reset_date":"2099-01-01","quota_snapshots":{"chat":{"unlimited":true},` +
+		`"premium_interactions":{"entitlement":300,"remaining":12,"percent_remaining":4,"unlimited":false,"overage_permitted":true}}}`))
+	if premium == nil || premium.Entitlement != 300 || premium.Remaining != 12 || premium.Unlimited || !premium.OveragePermitted ||
+		premium.ResetAt.Year() != 2099 {
+		t.Fatalf("parsed premium = %+v", premium)
+	}
+	if premium = parseCopilotPremium([]byte(`{"login":"octocat"}`)); premium != nil {
+		t.Errorf("premium from a response without quota = %+v", premium)
+	}
+
+	reg := registry.GetGlobalRegistry()
+	low := &Auth{ID: "copilot-low", Provider: "github-copilot"}
+	high := &Auth{ID: "copilot-high", Provider: "github-copilot"}
+	for _, auth := range []*Auth{low, high} {
+		reg.RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{
+			{ID: "claude-opus-4.5", PremiumMultiplier: 3},
+			{ID: "gpt-4.1"},
+		})
+		t.Cleanup(func() { reg.UnregisterClient(auth.ID) })
+	}
+
+	m := NewQuotaManager()
+	strategy := m.getStrategy("github-copilot").(*CopilotStrategy)
+	strategy.premium.Store(low.ID, &copilotPremium{Entitlement: 300, Remaining: 10, OveragePermitted: true})
+	strategy.premium.Store(high.ID, &copilotPremium{Entitlement: 300, Remaining: 200, OveragePermitted: true})
+	ctx := context.Background()
+	selected, err := m.Pick(ctx, "github-copilot", "claude-opus-4.5", Options{ForceRotate: true}, []*Auth{low, high})
+	if err != nil || selected.ID != high.ID {
+		t.Errorf("Pick = %v, %v; want the account with more premium requests left", selected, err)
+	}
+
+	m.RecordModelUsage(low.ID, "github-copilot", "claude-opus-4.5")
+	m.RecordModelUsage(low.ID, "github-copilot", "claude-opus-4.5")
+	m.RecordModelUsage(low.ID, "github-copilot", "gpt-4.1")
+	if remaining := strategy.getPremium(low.ID, time.Now()).Remaining; remaining != 4 {
+		t.Errorf("remaining after two premium and one included request = %v, want 4", remaining)
+	}
+	m.RecordModelUsage(low.ID, "github-copilot", "claude-opus-4.5")
+	if _, err = m.Pick(ctx, "github-copilot", "claude-opus-4.5", Options{}, []*Auth{low}); err != nil {
+		t.Errorf("Pick with overage permitted: %v", err)
+	}
+
+	refuse := true
+	m.SetQuotaOverrides(&QuotaOverrides{Providers: map[string]QuotaOverride{"github-copilot": {RefuseOverage: &refuse}}})
+	if selected, err = m.Pick(ctx, "github-copilot", "claude-opus-4.5", Options{}, []*Auth{low}); err == nil {
+		t.Errorf("Pick with refuse-overage = %v, want no account", selected.ID)
+	}
+	if _, err = m.Pick(ctx, "github-copilot", "gpt-4.1", Options{}, []*Auth{low}); err != nil {
+		t.Errorf("Pick of an included model with refuse-overage: %v", err)
+	}
+}
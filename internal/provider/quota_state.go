@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// quotaStateSaveInterval is how often the auto-refresh loop saves the quota
+// state when it has changed.
+const quotaStateSaveInterval = time.Minute
+
+// savedQuotaState is the quota state of one auth as saved to the store.
+type savedQuotaState struct {
+	Provider string                 `json:"provider"`
+	State    AuthQuotaStateSnapshot `json:"state"`
+	// Models holds the model cooldowns still running when the state was saved.
+	Models map[string]*ModelState `json:"models,omitempty"`
+}
+
+type quotaStateDocument struct {
+	SavedAt time.Time                   `json:"saved_at"`
+	Auths   map[string]*savedQuotaState `json:"auths"`
+}
+
+// RestoreStates loads saved quota state. Each auth's state is applied by
+// RegisterAuth; the parts whose window has reset since it was saved are
+// dropped.
+func (m *QuotaManager) RestoreStates(data []byte) error {
+	var doc quotaStateDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	now := time.Now()
+	restored := make(map[string]*savedQuotaState, len(doc.Auths))
+	for id, saved := range doc.Auths {
+		if saved = m.freshQuotaState(saved, doc.SavedAt, now); saved != nil {
+			restored[id] = saved
+		}
+	}
+	m.restoreMu.Lock()
+	m.restored = restored
+	m.restoreMu.Unlock()
+	return nil
+}
+
+// freshQuotaState drops the parts of saved that no longer apply at now:
+// cooldowns that have ended, and usage counted in a window that has reset.
+func (m *QuotaManager) freshQuotaState(saved *savedQuotaState, savedAt, now time.Time) *savedQuotaState {
+	if saved == nil {
+		return nil
+	}
+	state := &saved.State
+	if !state.CooldownUntil.After(now) {
+		state.CooldownUntil = time.Time{}
+	}
+	if window := m.ProviderConfig(saved.Provider).WindowDuration; window <= 0 || now.Sub(savedAt) >= window {
+		state.TotalTokensUsed = 0
+	}
+	for model, ms := range saved.Models {
+		if ms == nil || !ms.NextRetryAfter.After(now) {
+			delete(saved.Models, model)
+		}
+	}
+	state.ActiveRequests = 0
+	if state.CooldownUntil.IsZero() && state.TotalTokensUsed == 0 && state.LearnedLimit == 0 &&
+		state.LearnedCooldown == 0 && len(saved.Models) == 0 {
+		return nil
+	}
+	return saved
+}
+
+// restoreAuth applies the restored state of auth, once: its counters to the
+// quota state and its model cooldowns to auth.ModelStates.
+func (m *QuotaManager) restoreAuth(auth *Auth) {
+	m.restoreMu.Lock()
+	saved := m.restored[auth.ID]
+	delete(m.restored, auth.ID)
+	m.restoreMu.Unlock()
+	if saved == nil || saved.Provider != auth.Provider {
+		return
+	}
+
+	state := m.getOrCreateState(auth.ID)
+	state.SetCooldownUntil(saved.State.CooldownUntil)
+	state.TotalTokensUsed.Store(saved.State.TotalTokensUsed)
+	state.SetLastExhaustedAt(saved.State.LastExhaustedAt)
+	state.LearnedLimit.Store(saved.State.LearnedLimit)
+	state.SetLearnedCooldown(saved.State.LearnedCooldown)
+
+	for model, ms := range saved.Models {
+		if existing := auth.ModelStates[model]; existing != nil && existing.NextRetryAfter.After(ms.NextRetryAfter) {
+			continue
+		}
+		if auth.ModelStates == nil {
+			auth.ModelStates = make(map[string]*ModelState, len(saved.Models))
+		}
+		auth.ModelStates[model] = ms
+	}
+}
+
+// SaveQuotaState saves the quota state of the registered auths, and the model
+// cooldowns still running, to the store if it supports it and anything has
+// changed since the last save.
+func (m *Manager) SaveQuotaState(ctx context.Context) {
+	m.mu.RLock()
+	store, _ := m.store.(QuotaStateStore)
+	m.mu.RUnlock()
+	qm := m.GetQuotaManager()
+	if store == nil || qm == nil {
+		return
+	}
+
+	now := time.Now()
+	doc := quotaStateDocument{SavedAt: now, Auths: make(map[string]*savedQuotaState)}
+	for _, auth := range m.quotaStateAuths() {
+		saved := &savedQuotaState{Provider: auth.Provider}
+		if state := qm.GetState(auth.ID); state != nil {
+			saved.State = *state
+			saved.State.ActiveRequests = 0
+		}
+		for model, ms := range auth.ModelStates {
+			if ms != nil && ms.NextRetryAfter.After(now) {
+				if saved.Models == nil {
+					saved.Models = make(map[string]*ModelState)
+				}
+				saved.Models[model] = ms
+			}
+		}
+		if qm.freshQuotaState(saved, now, now) != nil {
+			doc.Auths[auth.ID] = saved
+		}
+	}
+
+	// SavedAt changes on every call, so compare the auth states only.
+	auths, err := json.Marshal(doc.Auths)
+	if err != nil {
+		log.Warnf("quota state: marshal failed: %v", err)
+		return
+	}
+	m.quotaStateMu.Lock()
+	defer m.quotaStateMu.Unlock()
+	if m.lastQuotaState != nil && bytes.Equal(auths, m.lastQuotaState) {
+		return
+	}
+	data, err := json.Marshal(doc)
+	if err == nil {
+		err = store.SaveQuotaState(ctx, data)
+	}
+	if err != nil {
+		log.Warnf("quota state: save failed: %v", err)
+		return
+	}
+	m.lastQuotaState = auths
+}
+
+// restoreQuotaState loads the state saved in store into qm, for RegisterAuth
+// to apply.
+func restoreQuotaState(ctx context.Context, store Store, qm *QuotaManager) {
+	stateStore, ok := store.(QuotaStateStore)
+	if !ok || qm == nil {
+		return
+	}
+	data, err := stateStore.LoadQuotaState(ctx)
+	if err != nil {
+		log.Warnf("quota state: load failed: %v", err)
+		return
+	}
+	if len(data) == 0 {
+		return
+	}
+	if err = qm.RestoreStates(data); err != nil {
+		log.Warnf("quota state: ignoring unreadable saved state: %v", err)
+	}
+}
+
+func (m *Manager) quotaStateAuths() []*Auth {
+	if m.registry != nil {
+		return m.registry.List()
+	}
+	return m.snapshotAuths()
+}
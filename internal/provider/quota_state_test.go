@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+type memQuotaStateStore struct {
+	auths []*Auth
+	state []byte
+}
+
+func (s *memQuotaStateStore) List(context.Context) ([]*Auth, error) {
+	out := make([]*Auth, 0, len(s.auths))
+	for _, a := range s.auths {
+		out = append(out, a.Clone())
+	}
+	return out, nil
+}
+
+func (s *memQuotaStateStore) Save(context.Context, *Auth) (string, error) { return "", nil }
+func (s *memQuotaStateStore) Delete(context.Context, string) error        { return nil }
+
+func (s *memQuotaStateStore) LoadQuotaState(context.Context) ([]byte, error) { return s.state, nil }
+func (s *memQuotaStateStore) SaveQuotaState(_ context.Context, data []byte) error {
+	s.state = data
+	return nil
+}
+
+func TestQuotaStateSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := &memQuotaStateStore{auths: []*Auth{
+		{ID: "claude-a", Provider: "claude", Status: StatusActive},
+		{ID: "claude-b", Provider: "claude", Status: StatusActive},
+	}}
+
+	first := NewManager(store, NewQuotaManager(), nil)
+	defer first.Stop()
+	if err := first.Load(ctx); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	qm := first.GetQuotaManager()
+	a := qm.getOrCreateState("claude-a")
+	a.SetCooldownUntil(now.Add(time.Hour))
+	a.TotalTokensUsed.Store(1234)
+	a.LearnedLimit.Store(5000)
+	b := qm.getOrCreateState("claude-b")
+	b.SetCooldownUntil(now.Add(-time.Minute))
+	entry := first.GetAuthEntry("claude-a")
+	for model, retryAt := range map[string]time.Time{"cooling": now.Add(30 * time.Minute), "expired": now.Add(-time.Second)} {
+		entry.UpdateModelState(model, func(ModelStateSnapshot) ModelStateSnapshot {
+			return ModelStateSnapshot{Status: StatusError, Unavailable: true, NextRetryAfter: retryAt.UnixNano()}
+		})
+	}
+	first.SaveQuotaState(ctx)
+	if store.state == nil {
+		t.Fatal("quota state was not saved")
+	}
+
+	second := NewManager(store, NewQuotaManager(), nil)
+	defer second.Stop()
+	if err := second.Load(ctx); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	restored := second.GetQuotaManager().GetState("claude-a")
+	if restored == nil || !restored.CooldownUntil.After(now) || restored.TotalTokensUsed != 1234 || restored.LearnedLimit != 5000 {
+		t.Errorf("restored claude-a state = %+v", restored)
+	}
+	if state := second.GetQuotaManager().GetState("claude-b"); state != nil {
+		t.Errorf("claude-b cooldown had ended but was restored: %+v", state)
+	}
+	models := second.GetAuthEntry("claude-a").ToAuth().ModelStates
+	if ms := models["cooling"]; ms == nil || !ms.NextRetryAfter.After(now) {
+		t.Errorf("running model cooldown was not restored: %+v", models)
+	}
+	if _, ok := models["expired"]; ok {
+		t.Errorf("ended model cooldown was restored")
+	}
+
+	// Once the provider window has reset, only the learned values survive.
+	var doc quotaStateDocument
+	if err := json.Unmarshal(store.state, &doc); err != nil {
+		t.Fatalf("saved state: %v", err)
+	}
+	doc.SavedAt = now.Add(-6 * time.Hour)
+	doc.Auths["claude-a"].State.CooldownUntil = time.Time{}
+	store.state, _ = json.Marshal(doc)
+
+	third := NewManager(store, NewQuotaManager(), nil)
+	defer third.Stop()
+	if err := third.Load(ctx); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if state := third.GetQuotaManager().GetState("claude-a"); state == nil || state.TotalTokensUsed != 0 || state.LearnedLimit != 5000 {
+		t.Errorf("state after the window reset = %+v, want the learned limit only", state)
+	}
+}
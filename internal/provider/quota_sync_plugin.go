@@ -27,4 +27,7 @@ func (p *QuotaSyncPlugin) HandleUsage(ctx context.Context, record usage.Record)
 	}
 
 	p.manager.RecordRequestEnd(record.AuthID, record.Provider, tokens, record.Failed)
+	if !record.Failed {
+		p.manager.RecordModelUsage(record.AuthID, record.Provider, record.Model)
+	}
 }
@@ -47,6 +47,9 @@ func (m *Manager) StartAutoRefresh(parent context.Context, interval time.Duratio
 		cleanupTicker := time.NewTicker(1 * time.Hour)
 		defer cleanupTicker.Stop()
 
+		quotaStateTicker := time.NewTicker(quotaStateSaveInterval)
+		defer quotaStateTicker.Stop()
+
 		m.checkRefreshes(ctx)
 		for {
 			select {
@@ -60,6 +63,8 @@ func (m *Manager) StartAutoRefresh(parent context.Context, interval time.Duratio
 				if removed > 0 {
 					log.Debugf("Cleaned up %d stale provider stats entries", removed)
 				}
+			case <-quotaStateTicker.C:
+				m.SaveQuotaState(ctx)
 			}
 		}
 	}()
@@ -24,8 +24,8 @@ func SetQuotaCooldownDisabled(disable bool) {
 }
 
 // retrySettings retrieves current retry configuration.
-func (m *Manager) retrySettings() (int, time.Duration) {
-	if m == nil {
+func (m *Manager) retrySettings(ctx context.Context) (int, time.Duration) {
+	if m == nil || singleAttempt(ctx) {
 		return 0, 0
 	}
 	return int(m.requestRetry.Load()), time.Duration(m.maxRetryInterval.Load())
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// AuthScope restricts the auths that may serve a request. Empty fields do not
+// restrict.
+type AuthScope struct {
+	// Providers lists the providers the request may use.
+	Providers []string
+	// Labels lists the auth labels the request may use.
+	Labels []string
+	// Tags lists auth tags; an auth must carry at least one of them. Unlike
+	// the other fields, a tag that matches no auth fails the request.
+	Tags []string
+	// AuthID pins the request to one auth.
+	AuthID string
+}
+
+type authScopeContextKey struct{}
+
+type singleAttemptContextKey struct{}
+
+// WithAuthScope returns a context whose requests only use auths allowed by scope.
+func WithAuthScope(ctx context.Context, scope *AuthScope) context.Context {
+	if scope == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, authScopeContextKey{}, scope)
+}
+
+// AuthScopeFromContext returns the scope set by WithAuthScope, or nil.
+func AuthScopeFromContext(ctx context.Context) *AuthScope {
+	if ctx == nil {
+		return nil
+	}
+	scope, _ := ctx.Value(authScopeContextKey{}).(*AuthScope)
+	return scope
+}
+
+// WithSingleAttempt returns a context whose requests are sent once: a failure
+// is returned as is, without retrying, hedging, queueing or moving on to
+// another auth or provider.
+func WithSingleAttempt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, singleAttemptContextKey{}, true)
+}
+
+func singleAttempt(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	single, _ := ctx.Value(singleAttemptContextKey{}).(bool)
+	return single
+}
+
+// AllowsProvider reports whether the scope admits auths of provider.
+func (s *AuthScope) AllowsProvider(provider string) bool {
+	return s == nil || len(s.Providers) == 0 || slices.Contains(s.Providers, provider)
+}
+
+// allows reports whether the scope admits the auth id of provider with label.
+func (s *AuthScope) allows(provider, id, label string) bool {
+	if s == nil {
+		return true
+	}
+	if s.AuthID != "" && s.AuthID != id {
+		return false
+	}
+	return s.AllowsProvider(provider) && (len(s.Labels) == 0 || slices.Contains(s.Labels, label))
+}
+
+// allowsTags reports whether an auth with the given attributes and metadata
+// carries one of the scope's tags.
+func (s *AuthScope) allowsTags(attributes map[string]string, metadata map[string]any) bool {
+	if s == nil || len(s.Tags) == 0 {
+		return true
+	}
+	for _, tag := range AuthTags(attributes, metadata) {
+		if slices.Contains(s.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagMismatchError is returned when no auth of provider carries a tag of the
+// scope.
+func (s *AuthScope) tagMismatchError(provider string) *Error {
+	return &Error{
+		Code:        "auth_tag_not_matched",
+		Message:     fmt.Sprintf("no %s auth is tagged %s", provider, strings.Join(s.Tags, " or ")),
+		HTTPStatus:  http.StatusForbidden,
+		ErrCategory: CategoryUserError,
+	}
+}
+
+// AuthTags returns the lowercased tags of an auth. File auths carry them in the
+// "tags" metadata field, as a list or a comma-separated string; config keys
+// carry them comma-separated in the "tags" attribute.
+func AuthTags(attributes map[string]string, metadata map[string]any) []string {
+	var tags []string
+	add := func(raw string) {
+		for _, tag := range strings.Split(raw, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	add(attributes["tags"])
+	switch v := metadata["tags"].(type) {
+	case string:
+		add(v)
+	case []string:
+		for _, tag := range v {
+			add(tag)
+		}
+	case []any:
+		for _, tag := range v {
+			if str, ok := tag.(string); ok {
+				add(str)
+			}
+		}
+	}
+	return tags
+}
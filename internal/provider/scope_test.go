@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestAuthTags(t *testing.T) {
+	got := AuthTags(map[string]string{"tags": "Work, ci"}, map[string]any{"tags": []any{"work", "Personal", 3}})
+	if want := []string{"work", "ci", "personal"}; !slices.Equal(got, want) {
+		t.Errorf("AuthTags = %v, want %v", got, want)
+	}
+	if got := AuthTags(nil, map[string]any{"tags": "a,b"}); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("AuthTags(string metadata) = %v", got)
+	}
+}
+
+func TestPickNextHonoursAuthTags(t *testing.T) {
+	const model = "tag-test-model"
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+
+	m.RegisterExecutor(&hedgeTestExecutor{})
+	reg := registry.GetGlobalRegistry()
+	for id, tags := range map[string]any{"tag-work": []any{"work"}, "tag-personal": "personal"} {
+		_, _ = m.Register(ctx, &Auth{ID: id, Provider: "hedge-test", Status: StatusActive, Metadata: map[string]any{"tags": tags}})
+		reg.RegisterClient(id, "hedge-test", []*registry.ModelInfo{{ID: model}})
+		defer reg.UnregisterClient(id)
+	}
+
+	scoped := WithAuthScope(ctx, &AuthScope{Tags: []string{"work"}})
+	for i := 0; i < 4; i++ {
+		auth, _, err := m.pickNextFromRegistry(scoped, "hedge-test", model, Options{}, map[string]struct{}{})
+		if err != nil || auth.ID != "tag-work" {
+			t.Fatalf("pick %d = %v, %v; want tag-work", i, auth, err)
+		}
+	}
+
+	// Once the tagged auth has been tried, the request must not fall through
+	// to the other one.
+	_, _, err := m.pickNextFromRegistry(scoped, "hedge-test", model, Options{}, map[string]struct{}{"tag-work": {}})
+	var perr *Error
+	if !errors.As(err, &perr) || perr.Code != "auth_not_found" {
+		t.Fatalf("pick after trying tag-work = %v, want auth_not_found", err)
+	}
+
+	missing := WithAuthScope(ctx, &AuthScope{Tags: []string{"other"}})
+	_, _, err = m.pickNextFromRegistry(missing, "hedge-test", model, Options{}, map[string]struct{}{})
+	if !errors.As(err, &perr) || perr.Code != "auth_tag_not_matched" || perr.StatusCode() != http.StatusForbidden {
+		t.Fatalf("pick with unknown tag = %v, want a 403 auth_tag_not_matched error", err)
+	}
+}
+
+// failingExecutor rejects every request with a retryable error.
+type failingExecutor struct {
+	hedgeTestExecutor
+	calls atomic.Int32
+}
+
+func (e *failingExecutor) Identifier() string { return "single-test" }
+
+func (e *failingExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	e.calls.Add(1)
+	return Response{}, &Error{Code: "overloaded", HTTPStatus: http.StatusServiceUnavailable, Retryable: true, ErrCategory: CategoryTransient}
+}
+
+func TestPinnedAuthAndSingleAttempt(t *testing.T) {
+	const model = "single-test-model"
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+
+	executor := &failingExecutor{}
+	m.RegisterExecutor(executor)
+	reg := registry.GetGlobalRegistry()
+	for _, id := range []string{"single-a", "single-b"} {
+		_, _ = m.Register(ctx, &Auth{ID: id, Provider: "single-test", Status: StatusActive})
+		reg.RegisterClient(id, "single-test", []*registry.ModelInfo{{ID: model}})
+		defer reg.UnregisterClient(id)
+	}
+
+	pinned := WithAuthScope(ctx, &AuthScope{AuthID: "single-b"})
+	for i := 0; i < 3; i++ {
+		auth, _, err := m.pickNextFromRegistry(pinned, "single-test", model, Options{}, map[string]struct{}{})
+		if err != nil || auth.ID != "single-b" {
+			t.Fatalf("pick %d = %v, %v; want the pinned auth", i, auth, err)
+		}
+	}
+
+	single := WithSingleAttempt(ctx)
+	if _, err := m.Execute(single, []string{"single-test"}, Request{Model: model}, Options{}); err == nil {
+		t.Fatal("Execute succeeded, want the upstream error")
+	}
+	if calls := executor.calls.Load(); calls != 1 {
+		t.Errorf("executor called %d times, want 1", calls)
+	}
+}
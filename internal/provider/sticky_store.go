@@ -17,6 +17,17 @@ const (
 type stickyEntry struct {
 	authID   string
 	lastUsed time.Time
+	ttl      time.Duration
+}
+
+// expired reports whether the entry has gone unused for its TTL, which
+// defaults to stickyTTL.
+func (e *stickyEntry) expired(now time.Time) bool {
+	ttl := e.ttl
+	if ttl <= 0 {
+		ttl = stickyTTL
+	}
+	return now.Sub(e.lastUsed) >= ttl
 }
 
 type stickyShard struct {
@@ -71,7 +82,7 @@ func (s *StickyStore) Get(key string) (string, bool) {
 
 	shard.mu.RLock()
 	entry, ok := shard.entries[key]
-	if !ok || now.Sub(entry.lastUsed) >= stickyTTL {
+	if !ok || entry.expired(now) {
 		shard.mu.RUnlock()
 		return "", false
 	}
@@ -90,6 +101,12 @@ func (s *StickyStore) Get(key string) (string, bool) {
 
 // Set stores or updates a sticky entry for the given key.
 func (s *StickyStore) Set(key, authID string) {
+	s.SetWithTTL(key, authID, stickyTTL)
+}
+
+// SetWithTTL stores or updates a sticky entry that expires once it has not
+// been used for ttl.
+func (s *StickyStore) SetWithTTL(key, authID string, ttl time.Duration) {
 	shard := s.getShard(key)
 	now := time.Now()
 
@@ -99,6 +116,7 @@ func (s *StickyStore) Set(key, authID string) {
 	if entry, ok := shard.entries[key]; ok {
 		entry.authID = authID
 		entry.lastUsed = now
+		entry.ttl = ttl
 		return
 	}
 
@@ -110,6 +128,7 @@ func (s *StickyStore) Set(key, authID string) {
 	shard.entries[key] = &stickyEntry{
 		authID:   authID,
 		lastUsed: now,
+		ttl:      ttl,
 	}
 }
 
@@ -118,7 +137,7 @@ func (s *StickyStore) Set(key, authID string) {
 func (s *StickyStore) evictOldest(shard *stickyShard, now time.Time) {
 	// First pass: remove expired entries
 	for key, entry := range shard.entries {
-		if now.Sub(entry.lastUsed) >= stickyTTL {
+		if entry.expired(now) {
 			delete(shard.entries, key)
 		}
 	}
@@ -175,7 +194,7 @@ func (s *StickyStore) cleanupExpired() {
 	for _, shard := range s.shards {
 		shard.mu.Lock()
 		for key, entry := range shard.entries {
-			if now.Sub(entry.lastUsed) >= stickyTTL {
+			if entry.expired(now) {
 				delete(shard.entries, key)
 			}
 		}
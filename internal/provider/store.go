@@ -11,3 +11,12 @@ type Store interface {
 	// Delete removes the auth record identified by id.
 	Delete(ctx context.Context, id string) error
 }
+
+// QuotaStateStore is implemented by stores that can keep the state learned by
+// the QuotaManager across restarts. The state is an opaque JSON document.
+type QuotaStateStore interface {
+	// LoadQuotaState returns the saved state, or nil when none was saved.
+	LoadQuotaState(ctx context.Context) ([]byte, error)
+	// SaveQuotaState replaces the saved state.
+	SaveQuotaState(ctx context.Context, data []byte) error
+}
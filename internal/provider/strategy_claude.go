@@ -1,6 +1,9 @@
 package provider
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,6 +17,11 @@ func (s *ClaudeStrategy) Score(auth *Auth, state *AuthQuotaState, config *Provid
 	var priority int64
 	priority += state.ActiveRequests.Load() * 1000
 
+	if real := state.GetRealQuota(); real != nil && time.Since(real.FetchedAt) < realQuotaFreshness {
+		priority += int64((1.0 - real.RemainingFraction) * 500)
+		return priority
+	}
+
 	limit := state.LearnedLimit.Load()
 	if limit <= 0 && config != nil {
 		limit = config.EstimatedLimit
@@ -47,6 +55,8 @@ func (s *ClaudeStrategy) OnQuotaHit(state *AuthQuotaState, cooldown *time.Durati
 	if cooldown != nil && *cooldown > 0 {
 		state.SetCooldownUntil(now.Add(*cooldown))
 		state.SetLearnedCooldown(*cooldown)
+	} else if real := state.GetRealQuota(); real != nil && real.WindowResetAt.After(now) {
+		state.SetCooldownUntil(real.WindowResetAt)
 	} else if learned := state.GetLearnedCooldown(); learned > 0 {
 		state.SetCooldownUntil(now.Add(learned))
 	} else {
@@ -62,4 +72,60 @@ func (s *ClaudeStrategy) RecordUsage(state *AuthQuotaState, tokens int64) {
 	}
 }
 
+// Unified rate-limit headers Anthropic sends on responses to OAuth (Claude
+// subscription) requests. Utilizations are fractions of the window used, resets
+// are Unix seconds.
+const (
+	claudeUnifiedStatusHeader = "anthropic-ratelimit-unified-status"
+	claudeUnifiedResetHeader  = "anthropic-ratelimit-unified-reset"
+	claudeUnifiedPrefix       = "anthropic-ratelimit-unified-"
+)
+
+// claudeUnifiedWindows are the windows the unified headers report, e.g.
+// anthropic-ratelimit-unified-5h-utilization.
+var claudeUnifiedWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"5h", 5 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+}
+
+// ClaudeQuotaFromHeaders builds a RealQuotaSnapshot from the unified
+// rate-limit headers of a Claude response, or returns nil when it has none.
+func ClaudeQuotaFromHeaders(h http.Header, now time.Time) *RealQuotaSnapshot {
+	var windows []QuotaWindow
+	for _, window := range claudeUnifiedWindows {
+		utilization, err := strconv.ParseFloat(strings.TrimSpace(h.Get(claudeUnifiedPrefix+window.name+"-utilization")), 64)
+		if err != nil {
+			continue
+		}
+		windows = append(windows, QuotaWindow{
+			Name:         window.name,
+			UsedFraction: clampFraction(utilization),
+			Duration:     window.duration,
+			ResetAt:      claudeResetTime(h.Get(claudeUnifiedPrefix + window.name + "-reset")),
+		})
+	}
+	snapshot := snapshotFromWindows(windows, now)
+	if strings.EqualFold(strings.TrimSpace(h.Get(claudeUnifiedStatusHeader)), "rejected") {
+		if snapshot == nil {
+			snapshot = &RealQuotaSnapshot{FetchedAt: now}
+		}
+		snapshot.RemainingFraction = 0
+		if reset := claudeResetTime(h.Get(claudeUnifiedResetHeader)); !reset.IsZero() {
+			snapshot.WindowResetAt = reset
+		}
+	}
+	return snapshot
+}
+
+func claudeResetTime(value string) time.Time {
+	secs, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || secs <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
+
 var _ ProviderStrategy = (*ClaudeStrategy)(nil)
@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// CodexStrategy selects ChatGPT (Codex) accounts by the usage the Codex
+// backend reports for its primary (5h) and secondary (weekly) windows.
+type CodexStrategy struct{}
+
+// codexRotateFraction is the remaining quota below which an account is only
+// picked when no other has more left, so that it runs out as late as possible.
+const codexRotateFraction = 0.10
+
+func (s *CodexStrategy) Score(auth *Auth, state *AuthQuotaState, config *ProviderQuotaConfig) int64 {
+	if state == nil {
+		return 0
+	}
+
+	var priority int64
+	priority += state.ActiveRequests.Load() * 1000
+
+	if real := state.GetRealQuota(); real != nil && time.Since(real.FetchedAt) < realQuotaFreshness {
+		priority += int64((1.0 - real.RemainingFraction) * 500)
+		if real.RemainingFraction < codexRotateFraction {
+			priority += 5000
+		}
+		return priority
+	}
+
+	limit := state.LearnedLimit.Load()
+	if limit <= 0 && config != nil {
+		limit = config.EstimatedLimit
+	}
+	if limit > 0 {
+		priority += int64(float64(state.TotalTokensUsed.Load()) / float64(limit) * 500)
+	}
+
+	return priority
+}
+
+func (s *CodexStrategy) OnQuotaHit(state *AuthQuotaState, cooldown *time.Duration) {
+	if state == nil {
+		return
+	}
+	now := time.Now()
+	state.SetLastExhaustedAt(now)
+
+	tokensUsed := state.TotalTokensUsed.Load()
+	for {
+		currentLimit := state.LearnedLimit.Load()
+		if tokensUsed <= currentLimit {
+			break
+		}
+		if state.LearnedLimit.CompareAndSwap(currentLimit, tokensUsed) {
+			break
+		}
+	}
+
+	// A 429 while a window is used up lasts until that window resets; any
+	// other is a short-term limit.
+	switch real := state.GetRealQuota(); {
+	case real != nil && real.RemainingFraction <= quotaExhaustedThreshold && real.WindowResetAt.After(now):
+		state.SetCooldownUntil(real.WindowResetAt)
+	case cooldown != nil && *cooldown > 0:
+		state.SetCooldownUntil(now.Add(*cooldown))
+		state.SetLearnedCooldown(*cooldown)
+	case state.GetLearnedCooldown() > 0:
+		state.SetCooldownUntil(now.Add(state.GetLearnedCooldown()))
+	default:
+		state.SetCooldownUntil(now.Add(5 * time.Hour))
+	}
+
+	state.TotalTokensUsed.Store(0)
+}
+
+func (s *CodexStrategy) RecordUsage(state *AuthQuotaState, tokens int64) {
+	if state != nil && tokens > 0 {
+		state.TotalTokensUsed.Add(tokens)
+	}
+}
+
+// codexWindows are the windows the Codex backend reports, as named in its
+// headers (x-codex-primary-used-percent) and rate-limit events.
+var codexWindows = []string{"primary", "secondary"}
+
+// CodexQuotaFromHeaders builds a RealQuotaSnapshot from the x-codex-* usage
+// headers of a Codex response, or returns nil when it has none.
+func CodexQuotaFromHeaders(h http.Header, now time.Time) *RealQuotaSnapshot {
+	var windows []QuotaWindow
+	for _, name := range codexWindows {
+		prefix := "x-codex-" + name + "-"
+		used, err := strconv.ParseFloat(strings.TrimSpace(h.Get(prefix+"used-percent")), 64)
+		if err != nil {
+			continue
+		}
+		minutes, _ := strconv.ParseInt(strings.TrimSpace(h.Get(prefix+"window-minutes")), 10, 64)
+		resetAt, _ := strconv.ParseInt(strings.TrimSpace(h.Get(prefix+"reset-at")), 10, 64)
+		resetAfter, _ := strconv.ParseInt(strings.TrimSpace(h.Get(prefix+"reset-after-seconds")), 10, 64)
+		windows = append(windows, codexWindow(name, used, minutes, resetAt, resetAfter, now))
+	}
+	return snapshotFromWindows(windows, now)
+}
+
+// CodexQuotaFromEvent builds a RealQuotaSnapshot from a codex.rate_limits
+// stream event, or returns nil for any other event.
+func CodexQuotaFromEvent(data []byte, now time.Time) *RealQuotaSnapshot {
+	if gjson.GetBytes(data, "type").String() != "codex.rate_limits" {
+		return nil
+	}
+	limits := gjson.GetBytes(data, "rate_limits")
+	var windows []QuotaWindow
+	for _, name := range codexWindows {
+		w := limits.Get(name)
+		if !w.Get("used_percent").Exists() {
+			continue
+		}
+		resetAfter := w.Get("resets_in_seconds").Int()
+		if resetAfter == 0 {
+			resetAfter = w.Get("reset_after_seconds").Int()
+		}
+		windows = append(windows, codexWindow(name, w.Get("used_percent").Float(),
+			w.Get("window_minutes").Int(), w.Get("resets_at").Int(), resetAfter, now))
+	}
+	return snapshotFromWindows(windows, now)
+}
+
+// codexWindow converts the usage of a Codex window, where the reset is either
+// a Unix time or a number of seconds from now.
+func codexWindow(name string, usedPercent float64, minutes, resetAt, resetAfter int64, now time.Time) QuotaWindow {
+	w := QuotaWindow{
+		Name:         name,
+		UsedFraction: clampFraction(usedPercent / 100),
+		Duration:     time.Duration(minutes) * time.Minute,
+	}
+	switch {
+	case resetAt > 0:
+		w.ResetAt = time.Unix(resetAt, 0)
+	case resetAfter > 0:
+		w.ResetAt = now.Add(time.Duration(resetAfter) * time.Second)
+	}
+	return w
+}
+
+var _ ProviderStrategy = (*CodexStrategy)(nil)
@@ -1,16 +1,28 @@
 package provider
 
 import (
+	"context"
+	"math/rand/v2"
 	"sync"
 	"time"
 
+	"github.com/nghyane/llm-mux/internal/registry"
 	"golang.org/x/time/rate"
 )
 
+// CopilotStrategy selects GitHub Copilot accounts. Besides the request rate,
+// it tracks each account's monthly premium request allowance, which premium
+// models use up at their multiplier while included models are free.
 type CopilotStrategy struct {
 	limiters sync.Map
+	// premium maps auth IDs to their *copilotPremium.
+	premium sync.Map
 }
 
+// copilotPremiumPollInterval is how often the premium allowance is fetched;
+// requests made in between are counted locally.
+const copilotPremiumPollInterval = 15 * time.Minute
+
 func (s *CopilotStrategy) Score(auth *Auth, state *AuthQuotaState, config *ProviderQuotaConfig) int64 {
 	var priority int64
 	if state != nil {
@@ -33,10 +45,6 @@ func (s *CopilotStrategy) Score(auth *Auth, state *AuthQuotaState, config *Provi
 }
 
 func (s *CopilotStrategy) getOrCreateLimiter(authID string, config *ProviderQuotaConfig) *rate.Limiter {
-	if v, ok := s.limiters.Load(authID); ok {
-		return v.(*rate.Limiter)
-	}
-
 	estimatedLimit := int64(10_000)
 	windowDuration := 24 * time.Hour
 	if config != nil {
@@ -47,12 +55,7 @@ func (s *CopilotStrategy) getOrCreateLimiter(authID string, config *ProviderQuot
 			windowDuration = config.WindowDuration
 		}
 	}
-
-	tokenInterval := windowDuration / time.Duration(estimatedLimit)
-	burstSize := 100
-	limiter := rate.NewLimiter(rate.Every(tokenInterval), burstSize)
-	actual, _ := s.limiters.LoadOrStore(authID, limiter)
-	return actual.(*rate.Limiter)
+	return loadWindowLimiter(&s.limiters, authID, estimatedLimit, windowDuration, 100)
 }
 
 func (s *CopilotStrategy) OnQuotaHit(state *AuthQuotaState, cooldown *time.Duration) {
@@ -74,8 +77,151 @@ func (s *CopilotStrategy) RecordUsage(state *AuthQuotaState, _ int64) {
 
 func (s *CopilotStrategy) IncrementRequestCount(authID string) {
 	if v, ok := s.limiters.Load(authID); ok {
-		v.(*rate.Limiter).Allow()
+		v.(*windowLimiter).Allow()
+	}
+}
+
+// StartRefresh polls the premium request allowance of auth. The allowance
+// only limits premium models, so it is kept by the strategy instead of being
+// sent as a snapshot that would cool down the whole account.
+func (s *CopilotStrategy) StartRefresh(ctx context.Context, auth *Auth, state *AuthQuotaState) <-chan *RealQuotaSnapshot {
+	ch := make(chan *RealQuotaSnapshot)
+
+	if auth == nil {
+		close(ch)
+		return ch
+	}
+
+	var triggerCh <-chan struct{}
+	if state != nil {
+		triggerCh = state.GetRefreshTrigger()
+	}
+
+	go func() {
+		defer close(ch)
+
+		initialJitter := time.Duration(rand.Float64() * float64(2*time.Second))
+		select {
+		case <-time.After(initialJitter):
+		case <-ctx.Done():
+			return
+		}
+
+		var lastFetch time.Time
+		fetch := func() {
+			lastFetch = time.Now()
+			if premium := fetchCopilotPremium(ctx, extractAccessToken(auth)); premium != nil {
+				s.premium.Store(auth.ID, premium)
+			}
+		}
+
+		fetch()
+
+		ticker := time.NewTicker(copilotPremiumPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-triggerCh:
+				if time.Since(lastFetch) >= minFetchInterval {
+					fetch()
+				}
+			case <-ticker.C:
+				fetch()
+			}
+		}
+	}()
+	return ch
+}
+
+// AllowsModel refuses a premium model on an account without the allowance
+// left for it when going over would fail, or cost, instead.
+func (s *CopilotStrategy) AllowsModel(auth *Auth, model string, config *ProviderQuotaConfig) bool {
+	premium := s.getPremium(auth.ID, time.Now())
+	if premium == nil || premium.Unlimited {
+		return true
+	}
+	multiplier := copilotMultiplier(auth.Provider, model)
+	if multiplier <= 0 || premium.Remaining >= multiplier {
+		return true
 	}
+	refuseOverage := config != nil && config.RefuseOverage
+	return premium.OveragePermitted && !refuseOverage
 }
 
-var _ ProviderStrategy = (*CopilotStrategy)(nil)
+// ScoreModel prefers, for premium models, the accounts with the larger share
+// of their allowance left, and pushes back those that cannot afford model.
+func (s *CopilotStrategy) ScoreModel(auth *Auth, model string) int64 {
+	premium := s.getPremium(auth.ID, time.Now())
+	if premium == nil || premium.Unlimited || premium.Entitlement <= 0 {
+		return 0
+	}
+	multiplier := copilotMultiplier(auth.Provider, model)
+	if multiplier <= 0 {
+		return 0
+	}
+	remaining := premium.Remaining / premium.Entitlement
+	if remaining < 0 {
+		remaining = 0
+	}
+	priority := int64((1.0 - remaining) * 600)
+	if premium.Remaining < multiplier {
+		priority += modelQuotaExhaustedPriority
+	}
+	return priority
+}
+
+// RecordModelUsage takes a request for model off the allowance of authID
+// until the next fetch replaces it.
+func (s *CopilotStrategy) RecordModelUsage(authID, provider, model string) {
+	multiplier := copilotMultiplier(provider, model)
+	if multiplier <= 0 {
+		return
+	}
+	for {
+		v, ok := s.premium.Load(authID)
+		if !ok {
+			return
+		}
+		current := v.(*copilotPremium)
+		if current.Unlimited {
+			return
+		}
+		next := *current
+		next.Remaining -= multiplier
+		if s.premium.CompareAndSwap(authID, current, &next) {
+			return
+		}
+	}
+}
+
+// getPremium returns the allowance of authID, or nil when it is unknown or
+// has been reset since it was fetched.
+func (s *CopilotStrategy) getPremium(authID string, now time.Time) *copilotPremium {
+	v, ok := s.premium.Load(authID)
+	if !ok {
+		return nil
+	}
+	premium := v.(*copilotPremium)
+	if !premium.ResetAt.IsZero() && !now.Before(premium.ResetAt) {
+		return nil
+	}
+	return premium
+}
+
+// copilotMultiplier returns the premium requests a request for model uses,
+// as registered for provider.
+func copilotMultiplier(provider, model string) float64 {
+	if info := registry.GetGlobalRegistry().GetProviderModelInfo(provider, model); info != nil {
+		return info.PremiumMultiplier
+	}
+	return 0
+}
+
+var (
+	_ ProviderStrategy    = (*CopilotStrategy)(nil)
+	_ BackgroundRefresher = (*CopilotStrategy)(nil)
+	_ ModelAwareStrategy  = (*CopilotStrategy)(nil)
+)
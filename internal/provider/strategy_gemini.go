@@ -33,18 +33,17 @@ func (s *GeminiStrategy) Score(auth *Auth, state *AuthQuotaState, config *Provid
 }
 
 func (s *GeminiStrategy) getOrCreateLimiter(authID string, config *ProviderQuotaConfig) *rate.Limiter {
-	if v, ok := s.limiters.Load(authID); ok {
-		return v.(*rate.Limiter)
-	}
-
-	capacity := 60
-	if config != nil && config.EstimatedLimit > 0 {
-		capacity = int(config.EstimatedLimit)
+	capacity := int64(60)
+	window := time.Minute
+	if config != nil {
+		if config.EstimatedLimit > 0 {
+			capacity = config.EstimatedLimit
+		}
+		if config.WindowDuration > 0 {
+			window = config.WindowDuration
+		}
 	}
-
-	limiter := rate.NewLimiter(rate.Every(time.Minute/time.Duration(capacity)), capacity)
-	actual, _ := s.limiters.LoadOrStore(authID, limiter)
-	return actual.(*rate.Limiter)
+	return loadWindowLimiter(&s.limiters, authID, capacity, window, int(capacity))
 }
 
 func (s *GeminiStrategy) OnQuotaHit(state *AuthQuotaState, cooldown *time.Duration) {
@@ -66,7 +65,7 @@ func (s *GeminiStrategy) RecordUsage(state *AuthQuotaState, _ int64) {
 
 func (s *GeminiStrategy) ConsumeToken(authID string) bool {
 	if v, ok := s.limiters.Load(authID); ok {
-		return v.(*rate.Limiter).Allow()
+		return v.(*windowLimiter).Allow()
 	}
 	return true
 }
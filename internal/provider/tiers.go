@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// AuthTierReserve is the "tier" metadata value of reserve auths. They only
+// serve requests when no primary auth of their provider can.
+const AuthTierReserve = "reserve"
+
+// maxAuthWeight caps the "weight" metadata field.
+const maxAuthWeight = 1000
+
+// IsReserveAuth reports whether metadata marks an auth as reserve.
+func IsReserveAuth(metadata map[string]any) bool {
+	tier, _ := metadata["tier"].(string)
+	return strings.EqualFold(strings.TrimSpace(tier), AuthTierReserve)
+}
+
+// AuthWeight returns the share of traffic an auth takes relative to the other
+// auths of its tier, from its "weight" metadata field. It defaults to 1.
+func AuthWeight(metadata map[string]any) int {
+	var weight int
+	switch v := metadata["weight"].(type) {
+	case float64:
+		weight = int(v)
+	case int:
+		weight = v
+	case int64:
+		weight = int(v)
+	case string:
+		weight, _ = strconv.Atoi(strings.TrimSpace(v))
+	}
+	if weight < 1 {
+		return 1
+	}
+	return min(weight, maxAuthWeight)
+}
+
+func entryMetadataMap(entry *AuthEntry) map[string]any {
+	if meta := entry.Metadata(); meta != nil {
+		return meta.Metadata
+	}
+	return nil
+}
+
+// pickByTier picks among the primary entries first and falls back to the
+// reserve ones only when none of those can serve the request.
+func (m *Manager) pickByTier(ctx context.Context, provider, model string, opts Options, entries []*AuthEntry) (*AuthEntry, error) {
+	primary := make([]*AuthEntry, 0, len(entries))
+	var reserve []*AuthEntry
+	for _, entry := range entries {
+		if IsReserveAuth(entryMetadataMap(entry)) {
+			reserve = append(reserve, entry)
+		} else {
+			primary = append(primary, entry)
+		}
+	}
+	if len(primary) > 0 {
+		selected, err := m.pickByCost(ctx, provider, model, opts, primary)
+		if err == nil || len(reserve) == 0 {
+			return selected, err
+		}
+	}
+	return m.pickByCost(ctx, provider, model, opts, reserve)
+}
+
+// primaryAuths returns the auths that are not reserve, or all of them when
+// every auth is reserve.
+func primaryAuths(auths []*Auth) []*Auth {
+	primary := make([]*Auth, 0, len(auths))
+	for _, auth := range auths {
+		if !IsReserveAuth(auth.Metadata) {
+			primary = append(primary, auth)
+		}
+	}
+	if len(primary) == 0 {
+		return auths
+	}
+	return primary
+}
+
+// entriesWeighted reports whether any entry has a weight other than 1.
+func entriesWeighted(entries []*AuthEntry) bool {
+	for _, entry := range entries {
+		if AuthWeight(entryMetadataMap(entry)) != 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// pickWeighted picks one of n candidates at random in proportion to their
+// weights, which must be positive.
+func pickWeighted(n int, weight func(i int) float64) int {
+	var total float64
+	for i := 0; i < n; i++ {
+		total += weight(i)
+	}
+	r := rand.Float64() * total
+	for i := 0; i < n; i++ {
+		if r -= weight(i); r < 0 {
+			return i
+		}
+	}
+	return n - 1
+}
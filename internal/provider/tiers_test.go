@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestAuthWeight(t *testing.T) {
+	cases := []struct {
+		weight any
+		want   int
+	}{
+		{nil, 1},
+		{float64(3), 3},
+		{"4", 4},
+		{0, 1},
+		{-2, 1},
+		{float64(5000), maxAuthWeight},
+	}
+	for _, tc := range cases {
+		if got := AuthWeight(map[string]any{"weight": tc.weight}); got != tc.want {
+			t.Errorf("AuthWeight(%v) = %d, want %d", tc.weight, got, tc.want)
+		}
+	}
+}
+
+func TestPickByTierUsesReserveLast(t *testing.T) {
+	const model = "tier-test-model"
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+
+	reg := registry.GetGlobalRegistry()
+	register := func(id string, metadata map[string]any) *AuthEntry {
+		_, _ = m.registry.Register(ctx, &Auth{ID: id, Provider: "claude", Status: StatusActive, Metadata: metadata})
+		reg.RegisterClient(id, "claude", []*registry.ModelInfo{{ID: model}})
+		t.Cleanup(func() { reg.UnregisterClient(id) })
+		return m.registry.GetEntry(id)
+	}
+	primary := register("tier-primary", map[string]any{})
+	register("tier-reserve", map[string]any{"tier": "reserve"})
+	entries := m.registry.ListByProvider("claude")
+
+	for i := 0; i < 5; i++ {
+		selected, err := m.pickByTier(ctx, "claude", model, Options{}, entries)
+		if err != nil || selected.ID() != "tier-primary" {
+			t.Fatalf("pick %d = %v, %v; want the primary auth", i, selected, err)
+		}
+		selected.DecrementActiveRequests()
+	}
+
+	primary.SetCooldown(time.Now().Add(time.Hour))
+	selected, err := m.pickByTier(ctx, "claude", model, Options{}, entries)
+	if err != nil || selected.ID() != "tier-reserve" {
+		t.Fatalf("picked %v, %v; want the reserve auth while the primary cools down", selected, err)
+	}
+}
+
+func TestRegistryPickHonoursWeights(t *testing.T) {
+	const model = "weight-test-model"
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+
+	for id, weight := range map[string]float64{"weight-max": 4, "weight-pro": 1} {
+		_, _ = m.registry.Register(ctx, &Auth{ID: id, Provider: "claude", Status: StatusActive, Metadata: map[string]any{"weight": weight}})
+	}
+	entries := m.registry.ListByProvider("claude")
+
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		selected, err := m.registry.Pick(ctx, "claude", model, Options{}, entries)
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		counts[selected.ID()]++
+		selected.DecrementActiveRequests()
+	}
+	if ratio := float64(counts["weight-max"]) / float64(counts["weight-pro"]); ratio < 3 || ratio > 5.5 {
+		t.Errorf("traffic ratio = %.2f (%v), want about 4", ratio, counts)
+	}
+}
@@ -32,9 +32,27 @@ var geminiModels = []*ModelInfo{
 		Desc("State-of-the-art image generation and editing model.").Version("2.5").Created(1756166400).Limits(geminiInputLimit, 8192).B(),
 	Gemini("gemini-2.5-flash-image").Display("Gemini 2.5 Flash Image").
 		Desc("State-of-the-art image generation and editing model.").Version("2.5").Created(1759363200).Limits(geminiInputLimit, 8192).B(),
+	Gemini("gemini-2.5-flash-preview-tts").Display("Gemini 2.5 Flash Preview TTS").
+		Desc("Text-to-speech model with controllable style and prebuilt voices.").Version("2.5").Created(1747180800).Limits(8192, 16384).B(),
+	Gemini("gemini-2.5-pro-preview-tts").Display("Gemini 2.5 Pro Preview TTS").
+		Desc("Text-to-speech model with controllable style and prebuilt voices.").Version("2.5").Created(1747180800).Limits(8192, 16384).B(),
 	Gemini("gemini-2.5-computer-use-preview-10-2025").Upstream("rev19-uic3-1p").Display("Gemini 2.5 Computer Use Preview").B(),
 }
 
+// geminiEmbeddingModels defines embedding models served by the Gemini API, AI Studio and Vertex.
+var geminiEmbeddingModels = []*ModelInfo{
+	Embedding("gemini-embedding-001", "google").Display("Gemini Embedding 001").
+		Desc("Text embedding model with up to 3072 output dimensions").Limits(2048, 1).B(),
+}
+
+// vertexEmbeddingModels defines embedding models only available on Vertex AI.
+var vertexEmbeddingModels = []*ModelInfo{
+	Embedding("text-embedding-005", "google").Display("Text Embedding 005").
+		Desc("English and code text embedding model").Limits(2048, 1).B(),
+	Embedding("text-multilingual-embedding-002", "google").Display("Text Multilingual Embedding 002").
+		Desc("Multilingual text embedding model").Limits(2048, 1).B(),
+}
+
 // claudeViaAntigravityModels defines Claude models accessed via Antigravity (gemini-cli only).
 var claudeViaAntigravityModels = []*ModelInfo{
 	ClaudeVia("claude-sonnet-4-5", "antigravity").Display("Claude Sonnet 4.5").
@@ -102,6 +120,15 @@ func GetGeminiModelsForProvider(providerType string) []*ModelInfo {
 		models = append(models, clone)
 	}
 
+	// Embedding models are only served by API-key style providers
+	switch providerType {
+	case "vertex":
+		models = append(models, cloneModels(vertexEmbeddingModels, providerType)...)
+		fallthrough
+	case "gemini", "aistudio":
+		models = append(models, cloneModels(geminiEmbeddingModels, providerType)...)
+	}
+
 	// Add Claude via Antigravity models only for gemini-cli
 	if providerType == "gemini-cli" {
 		for _, m := range claudeViaAntigravityModels {
@@ -113,8 +140,20 @@ func GetGeminiModelsForProvider(providerType string) []*ModelInfo {
 	return models
 }
 
+func cloneModels(src []*ModelInfo, providerType string) []*ModelInfo {
+	out := make([]*ModelInfo, len(src))
+	for i, m := range src {
+		out[i] = cloneModelWithType(m, providerType)
+	}
+	return out
+}
+
 // cloneModelWithType creates a deep copy of a ModelInfo with a new Type.
+// Embedding models keep ModelTypeEmbedding.
 func cloneModelWithType(src *ModelInfo, providerType string) *ModelInfo {
+	if src.Type == ModelTypeEmbedding {
+		providerType = ModelTypeEmbedding
+	}
 	clone := &ModelInfo{
 		ID:                         src.ID,
 		Object:                     src.Object,
@@ -14,8 +14,13 @@ type ModelBuilder struct {
 var (
 	defaultGeminiMethods = []string{"generateContent", "countTokens", "createCachedContent", "batchGenerateContent"}
 	defaultClaudeMethods = []string{"generateContent"}
+	defaultEmbedMethods  = []string{"embedContent", "batchEmbedContents"}
 )
 
+// ModelTypeEmbedding is the Type of models that only serve embedding requests.
+// It is kept regardless of which provider serves the model.
+const ModelTypeEmbedding = "embedding"
+
 const (
 	geminiInputLimit  = 1048576
 	geminiOutputLimit = 65536
@@ -41,6 +46,18 @@ func Gemini(id string) *ModelBuilder {
 	}}
 }
 
+// Embedding creates a builder for text embedding models.
+func Embedding(id, owner string) *ModelBuilder {
+	return &ModelBuilder{info: &ModelInfo{
+		ID:                         id,
+		Object:                     "model",
+		OwnedBy:                    owner,
+		Type:                       ModelTypeEmbedding,
+		Name:                       "models/" + id,
+		SupportedGenerationMethods: defaultEmbedMethods,
+	}}
+}
+
 // Claude creates a builder for native Claude API models.
 func Claude(id string) *ModelBuilder {
 	return &ModelBuilder{info: &ModelInfo{
@@ -223,6 +240,12 @@ func (b *ModelBuilder) Priority(p int) *ModelBuilder {
 	return b
 }
 
+// Premium sets the Copilot premium request multiplier.
+func (b *ModelBuilder) Premium(multiplier float64) *ModelBuilder {
+	b.info.PremiumMultiplier = multiplier
+	return b
+}
+
 // B returns the constructed ModelInfo (short for Build).
 func (b *ModelBuilder) B() *ModelInfo {
 	return b.info
@@ -99,27 +99,28 @@ func GetClineModels() []*ModelInfo {
 	}
 }
 
-// GetGitHubCopilotModels returns models via GitHub Copilot API (Priority=2 fallback)
+// GetGitHubCopilotModels returns models via GitHub Copilot API (Priority=2 fallback).
+// Models without a premium multiplier are included in every plan.
 func GetGitHubCopilotModels() []*ModelInfo {
 	return []*ModelInfo{
 		// OpenAI models via GitHub Copilot
 		Copilot("gpt-4.1").Display("GPT-4.1").Desc("OpenAI GPT-4.1 via GitHub Copilot").Created(1754524800).B(),
 		Copilot("gpt-4o").Display("GPT-4o").Desc("OpenAI GPT-4o via GitHub Copilot").Created(1715558400).B(),
-		Copilot("gpt-5").Display("GPT-5").Desc("OpenAI GPT-5 via GitHub Copilot").Created(1762473600).B(),
+		Copilot("gpt-5").Display("GPT-5").Desc("OpenAI GPT-5 via GitHub Copilot").Created(1762473600).Premium(1).B(),
 		Copilot("gpt-5-mini").Display("GPT-5 Mini").Desc("OpenAI GPT-5 Mini via GitHub Copilot").Created(1762473600).B(),
-		Copilot("gpt-5.1").Display("GPT-5.1").Desc("OpenAI GPT-5.1 via GitHub Copilot").Created(1763424000).B(),
-		Copilot("gpt-5.2").Display("GPT-5.2").Desc("OpenAI GPT-5.2 via GitHub Copilot").Created(1763424000).B(),
+		Copilot("gpt-5.1").Display("GPT-5.1").Desc("OpenAI GPT-5.1 via GitHub Copilot").Created(1763424000).Premium(1).B(),
+		Copilot("gpt-5.2").Display("GPT-5.2").Desc("OpenAI GPT-5.2 via GitHub Copilot").Created(1763424000).Premium(1).B(),
 		// Claude models via GitHub Copilot
-		Copilot("claude-sonnet-4").Display("Claude Sonnet 4").Desc("Anthropic Claude Sonnet 4 via GitHub Copilot").Created(1763424000).B(),
-		Copilot("claude-sonnet-4.5").Display("Claude Sonnet 4.5").Desc("Anthropic Claude Sonnet 4.5 via GitHub Copilot").Created(1763424000).B(),
-		Copilot("claude-haiku-4.5").Display("Claude Haiku 4.5").Desc("Anthropic Claude Haiku 4.5 via GitHub Copilot").Created(1763424000).B(),
-		Copilot("claude-opus-4.5").Display("Claude Opus 4.5").Desc("Anthropic Claude Opus 4.5 via GitHub Copilot").Created(1763424000).B(),
+		Copilot("claude-sonnet-4").Display("Claude Sonnet 4").Desc("Anthropic Claude Sonnet 4 via GitHub Copilot").Created(1763424000).Premium(1).B(),
+		Copilot("claude-sonnet-4.5").Display("Claude Sonnet 4.5").Desc("Anthropic Claude Sonnet 4.5 via GitHub Copilot").Created(1763424000).Premium(1).B(),
+		Copilot("claude-haiku-4.5").Display("Claude Haiku 4.5").Desc("Anthropic Claude Haiku 4.5 via GitHub Copilot").Created(1763424000).Premium(0.33).B(),
+		Copilot("claude-opus-4.5").Display("Claude Opus 4.5").Desc("Anthropic Claude Opus 4.5 via GitHub Copilot").Created(1763424000).Premium(3).B(),
 		// Google models via GitHub Copilot
-		Copilot("gemini-2.5-pro").Display("Gemini 2.5 Pro").Desc("Google Gemini 2.5 Pro via GitHub Copilot").Created(1763424000).B(),
-		Copilot("gemini-3-flash").Display("Gemini 3 Flash").Desc("Google Gemini 3 Flash via GitHub Copilot").Created(1763424000).B(),
-		Copilot("gemini-3-pro-preview").Display("Gemini 3 Pro Preview").Desc("Google Gemini 3 Pro Preview via GitHub Copilot").Created(1763424000).B(),
+		Copilot("gemini-2.5-pro").Display("Gemini 2.5 Pro").Desc("Google Gemini 2.5 Pro via GitHub Copilot").Created(1763424000).Premium(1).B(),
+		Copilot("gemini-3-flash").Display("Gemini 3 Flash").Desc("Google Gemini 3 Flash via GitHub Copilot").Created(1763424000).Premium(0.33).B(),
+		Copilot("gemini-3-pro-preview").Display("Gemini 3 Pro Preview").Desc("Google Gemini 3 Pro Preview via GitHub Copilot").Created(1763424000).Premium(1).B(),
 		// xAI models via GitHub Copilot
-		Copilot("grok-code-fast-1").Display("Grok Code Fast 1").Desc("xAI Grok Code Fast 1 via GitHub Copilot").Created(1763424000).B(),
+		Copilot("grok-code-fast-1").Display("Grok Code Fast 1").Desc("xAI Grok Code Fast 1 via GitHub Copilot").Created(1763424000).Premium(0.25).B(),
 	}
 }
 
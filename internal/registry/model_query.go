@@ -152,6 +152,15 @@ func (r *ModelRegistry) GetModelInfo(modelID string) *ModelInfo {
 	return nil
 }
 
+// GetProviderModelInfo returns the model as registered by provider, or nil.
+func (r *ModelRegistry) GetProviderModelInfo(provider, modelID string) *ModelInfo {
+	s := r.snapshot()
+	if reg, ok := s.models[provider+":"+modelID]; ok && reg != nil {
+		return reg.Info
+	}
+	return nil
+}
+
 func (r *ModelRegistry) GetAvailableProviders() []string {
 	s := r.snapshot()
 
@@ -25,6 +25,7 @@ type ModelInfo struct {
 	SupportedParameters        []string         `json:"supported_parameters,omitempty"`
 	Thinking                   *ThinkingSupport `json:"thinking,omitempty"`
 	Priority                   int              `json:"priority,omitempty"`
+	PremiumMultiplier          float64          `json:"premium_multiplier,omitempty"` // Copilot premium requests per request; 0 when included
 	UpstreamName               string           `json:"-"`
 	Hidden                     bool             `json:"-"`
 }
@@ -0,0 +1,109 @@
+package responses
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/sqlstore"
+)
+
+const (
+	defaultMaxEntries = 10000
+	defaultTTL        = 30 * 24 * time.Hour
+	cleanupInterval   = time.Hour
+)
+
+var (
+	defaultMu    sync.RWMutex
+	defaultStore Store = NewMemoryStore(defaultMaxEntries, defaultTTL)
+	stopCleanup  chan struct{}
+)
+
+// Default returns the process-wide response store, or nil when storage is disabled.
+func Default() Store {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultStore
+}
+
+// Initialize configures the process-wide store from cfg and starts TTL cleanup.
+func Initialize(cfg *config.Config) error {
+	ttl := defaultTTL
+	if d, err := time.ParseDuration(cfg.Responses.TTL); err == nil && d > 0 {
+		ttl = d
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(cfg.Responses.Store))
+	if mode == "" {
+		mode = "memory"
+		if cfg.Usage.DSN != "" {
+			mode = "database"
+		}
+	}
+
+	var store Store
+	switch mode {
+	case "none":
+	case "database":
+		db, err := sqlstore.Open(cfg.Usage.DSN)
+		if err != nil {
+			return err
+		}
+		if db == nil {
+			log.Warn("responses: store is \"database\" but usage.dsn is empty, using memory")
+			store = NewMemoryStore(cfg.Responses.MaxEntries, ttl)
+			break
+		}
+		sqlStore, err := NewSQLStore(db, ttl)
+		if err != nil {
+			return err
+		}
+		store = sqlStore
+	default:
+		store = NewMemoryStore(cfg.Responses.MaxEntries, ttl)
+	}
+
+	Stop()
+	defaultMu.Lock()
+	defaultStore = store
+	if store != nil {
+		stopCleanup = make(chan struct{})
+		go cleanupLoop(store, ttl, stopCleanup)
+	}
+	defaultMu.Unlock()
+	log.Infof("responses: using %s store", mode)
+	return nil
+}
+
+// Stop halts the background cleanup loop.
+func Stop() {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if stopCleanup != nil {
+		close(stopCleanup)
+		stopCleanup = nil
+	}
+}
+
+func cleanupLoop(store Store, ttl time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if n, err := store.Cleanup(ctx, time.Now().Add(-ttl)); err != nil {
+				log.Warnf("responses: cleanup failed: %v", err)
+			} else if n > 0 {
+				log.Debugf("responses: removed %d expired responses", n)
+			}
+			cancel()
+		}
+	}
+}
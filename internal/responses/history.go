@@ -0,0 +1,148 @@
+package responses
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+// Expand inlines the history referenced by previous_response_id into a
+// Responses API request body. It returns the payload to send upstream, with
+// previous_response_id removed, and the complete input history for this turn
+// so it can be stored with the new response.
+func Expand(ctx context.Context, store Store, payload []byte, apiKey string) (out []byte, history []byte, err error) {
+	root := gjson.ParseBytes(payload)
+	current := normalizeInput(root.Get("input"))
+
+	prevID := root.Get("previous_response_id").String()
+	if prevID == "" {
+		return payload, joinItems(current), nil
+	}
+
+	prev, err := store.Get(ctx, prevID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if prev.APIKey != apiKey {
+		return nil, nil, ErrNotFound
+	}
+
+	// Stored input messages carry ids assigned by NewRecord; those are not part
+	// of the input message schema, so they are kept in history only.
+	items := make([]string, 0, 16)
+	upstream := make([]string, 0, 16)
+	for _, item := range gjson.ParseBytes(prev.Input).Array() {
+		items = append(items, item.Raw)
+		upstream = append(upstream, stripInputID(item))
+	}
+	for _, item := range gjson.GetBytes(prev.Response, "output").Array() {
+		items = append(items, item.Raw)
+		upstream = append(upstream, item.Raw)
+	}
+	items = append(items, current...)
+	upstream = append(upstream, current...)
+	history = joinItems(items)
+
+	out, err = sjson.SetRawBytes(payload, "input", joinItems(upstream))
+	if err != nil {
+		return nil, nil, err
+	}
+	out, _ = sjson.DeleteBytes(out, "previous_response_id")
+	return out, history, nil
+}
+
+// ShouldStore reports whether the request asks for its response to be stored.
+// The Responses API stores by default.
+func ShouldStore(payload []byte) bool {
+	v := gjson.GetBytes(payload, "store")
+	return !v.Exists() || v.Bool()
+}
+
+// NewRecord builds a Record from a completed response object. Input items
+// without an id get one so that input_items can be paginated.
+// It returns nil when the response carries no id.
+func NewRecord(response, history []byte, apiKey, previousResponseID string) *Record {
+	root := gjson.ParseBytes(response)
+	id := root.Get("id").String()
+	if id == "" {
+		return nil
+	}
+	createdAt := time.Now()
+	if ts := root.Get("created_at").Int(); ts > 0 {
+		createdAt = time.Unix(ts, 0)
+	}
+	if previousResponseID != "" {
+		response, _ = sjson.SetBytes(response, "previous_response_id", previousResponseID)
+	}
+	return &Record{
+		ID:                 id,
+		Model:              root.Get("model").String(),
+		APIKey:             apiKey,
+		PreviousResponseID: previousResponseID,
+		Input:              withItemIDs(history),
+		Response:           response,
+		CreatedAt:          createdAt,
+	}
+}
+
+func normalizeInput(input gjson.Result) []string {
+	if input.Type == gjson.String {
+		item, _ := json.Marshal(map[string]any{
+			"type": "message",
+			"role": "user",
+			"content": []map[string]any{
+				{"type": "input_text", "text": input.String()},
+			},
+		})
+		return []string{string(item)}
+	}
+	arr := input.Array()
+	items := make([]string, 0, len(arr))
+	for _, item := range arr {
+		items = append(items, item.Raw)
+	}
+	return items
+}
+
+// withItemIDs assigns a "msg_" id to every item of history that has none.
+func withItemIDs(history []byte) []byte {
+	arr := gjson.ParseBytes(history).Array()
+	items := make([]string, 0, len(arr))
+	for _, item := range arr {
+		raw := item.Raw
+		if !item.Get("id").Exists() {
+			raw, _ = sjson.Set(raw, "id", newItemID())
+		}
+		items = append(items, raw)
+	}
+	return joinItems(items)
+}
+
+// stripInputID removes the id from user, system and developer messages.
+func stripInputID(item gjson.Result) string {
+	if item.Get("role").String() == "assistant" || !item.Get("id").Exists() {
+		return item.Raw
+	}
+	if t := item.Get("type").String(); t != "" && t != "message" {
+		return item.Raw
+	}
+	raw, _ := sjson.Delete(item.Raw, "id")
+	return raw
+}
+
+func newItemID() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return "msg_" + hex.EncodeToString(b[:])
+}
+
+func joinItems(items []string) []byte {
+	return []byte("[" + strings.Join(items, ",") + "]")
+}
@@ -0,0 +1,125 @@
+package responses
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestExpandChainsPreviousResponse(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10, 0)
+
+	first := []byte(`{"model":"gpt-5","input":"hello"}`)
+	payload, history, err := Expand(ctx, store, first, "key-a")
+	if err != nil {
+		t.Fatalf("Expand first turn: %v", err)
+	}
+	if string(payload) != string(first) {
+		t.Fatalf("first turn payload changed: %s", payload)
+	}
+	if got := gjson.GetBytes(history, "0.content.0.text").String(); got != "hello" {
+		t.Fatalf("string input not normalized, history=%s", history)
+	}
+
+	resp := []byte(`{"id":"resp_1","model":"gpt-5","created_at":1700000000,"output":[{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"hi"}]}]}`)
+	rec := NewRecord(resp, history, "key-a", "")
+	if err := store.Put(ctx, rec); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	second := []byte(`{"model":"gpt-5","previous_response_id":"resp_1","input":[{"type":"message","role":"user","content":"again"}]}`)
+	payload, history, err = Expand(ctx, store, second, "key-a")
+	if err != nil {
+		t.Fatalf("Expand second turn: %v", err)
+	}
+	if gjson.GetBytes(payload, "previous_response_id").Exists() {
+		t.Errorf("previous_response_id not removed: %s", payload)
+	}
+	items := gjson.GetBytes(payload, "input").Array()
+	if len(items) != 3 {
+		t.Fatalf("expected 3 input items, got %d: %s", len(items), payload)
+	}
+	if items[1].Get("id").String() != "msg_1" || items[2].Get("content").String() != "again" {
+		t.Errorf("unexpected history order: %s", payload)
+	}
+	if gjson.GetBytes(payload, "input.0.id").Exists() {
+		t.Errorf("stored input id sent upstream: %s", payload)
+	}
+	if len(gjson.ParseBytes(history).Array()) != 3 || !gjson.GetBytes(history, "0.id").Exists() {
+		t.Errorf("history lost stored item ids: %s", history)
+	}
+}
+
+func TestNewRecordAssignsInputItemIDs(t *testing.T) {
+	history := []byte(`[{"type":"message","role":"user","content":"a"},{"type":"message","id":"msg_keep","role":"user","content":"b"}]`)
+	rec := NewRecord([]byte(`{"id":"resp_1"}`), history, "", "")
+	items := gjson.ParseBytes(rec.Input).Array()
+	if !strings.HasPrefix(items[0].Get("id").String(), "msg_") {
+		t.Errorf("item without id was not assigned one: %s", rec.Input)
+	}
+	if items[1].Get("id").String() != "msg_keep" {
+		t.Errorf("existing id was replaced: %s", rec.Input)
+	}
+}
+
+func TestExpandRejectsOtherOwner(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10, 0)
+	_ = store.Put(ctx, &Record{ID: "resp_1", APIKey: "key-a", Input: []byte(`[]`), Response: []byte(`{}`), CreatedAt: time.Now()})
+
+	_, _, err := Expand(ctx, store, []byte(`{"previous_response_id":"resp_1","input":"x"}`), "key-b")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestShouldStore(t *testing.T) {
+	cases := map[string]bool{
+		`{}`:              true,
+		`{"store":true}`:  true,
+		`{"store":false}`: false,
+	}
+	for body, want := range cases {
+		if got := ShouldStore([]byte(body)); got != want {
+			t.Errorf("ShouldStore(%s) = %v, want %v", body, got, want)
+		}
+	}
+}
+
+func TestMemoryStoreEvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2, 0)
+	now := time.Now()
+	for i, id := range []string{"a", "b", "c"} {
+		_ = store.Put(ctx, &Record{ID: id, CreatedAt: now.Add(time.Duration(i) * time.Minute)})
+	}
+	if _, err := store.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected oldest record to be evicted")
+	}
+
+	n, err := store.Cleanup(ctx, now.Add(90*time.Second))
+	if err != nil || n != 1 {
+		t.Fatalf("Cleanup removed %d (err %v), want 1", n, err)
+	}
+	if _, err := store.Get(ctx, "c"); err != nil {
+		t.Errorf("expected c to survive cleanup: %v", err)
+	}
+}
+
+func TestMemoryStoreGetHonorsTTL(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10, time.Hour)
+	_ = store.Put(ctx, &Record{ID: "old", CreatedAt: time.Now().Add(-2 * time.Hour)})
+	_ = store.Put(ctx, &Record{ID: "new", CreatedAt: time.Now()})
+	if _, err := store.Get(ctx, "old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired record still returned, err=%v", err)
+	}
+	if _, err := store.Get(ctx, "new"); err != nil {
+		t.Errorf("fresh record not returned: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package responses
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/sqlstore"
+)
+
+// SQLStore persists responses in the usage database (SQLite or Postgres).
+type SQLStore struct {
+	db  *sqlstore.DB
+	ttl time.Duration
+}
+
+// NewSQLStore creates the responses table if needed and returns a store backed by db.
+// Records older than ttl are not returned by Get.
+func NewSQLStore(db *sqlstore.DB, ttl time.Duration) (*SQLStore, error) {
+	err := db.Migrate(
+		`CREATE TABLE IF NOT EXISTS stored_responses (
+			id TEXT PRIMARY KEY,
+			model TEXT NOT NULL DEFAULT '',
+			api_key TEXT NOT NULL DEFAULT '',
+			previous_response_id TEXT NOT NULL DEFAULT '',
+			input TEXT NOT NULL,
+			response TEXT NOT NULL,
+			created_at BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_stored_responses_created_at ON stored_responses(created_at)`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLStore{db: db, ttl: ttl}, nil
+}
+
+func (s *SQLStore) Put(ctx context.Context, rec *Record) error {
+	_, err := s.db.ExecContext(ctx, s.db.Rebind(`
+		INSERT INTO stored_responses (id, model, api_key, previous_response_id, input, response, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			model = excluded.model,
+			api_key = excluded.api_key,
+			previous_response_id = excluded.previous_response_id,
+			input = excluded.input,
+			response = excluded.response,
+			created_at = excluded.created_at`),
+		rec.ID, rec.Model, rec.APIKey, rec.PreviousResponseID, string(rec.Input), string(rec.Response), rec.CreatedAt.UnixMilli())
+	return err
+}
+
+func (s *SQLStore) Get(ctx context.Context, id string) (*Record, error) {
+	row := s.db.QueryRowContext(ctx, s.db.Rebind(`
+		SELECT id, model, api_key, previous_response_id, input, response, created_at
+		FROM stored_responses WHERE id = ?`), id)
+	var (
+		rec             Record
+		input, response string
+		createdAt       int64
+	)
+	if err := row.Scan(&rec.ID, &rec.Model, &rec.APIKey, &rec.PreviousResponseID, &input, &response, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	rec.Input = []byte(input)
+	rec.Response = []byte(response)
+	rec.CreatedAt = time.UnixMilli(createdAt)
+	if expired(rec.CreatedAt, s.ttl) {
+		return nil, ErrNotFound
+	}
+	return &rec, nil
+}
+
+func (s *SQLStore) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, s.db.Rebind(`DELETE FROM stored_responses WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLStore) Cleanup(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.db.Rebind(`DELETE FROM stored_responses WHERE created_at < ?`), before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
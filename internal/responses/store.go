@@ -0,0 +1,122 @@
+// Package responses keeps completed Responses API results so that clients can
+// chain turns with previous_response_id regardless of the provider that served
+// the previous turn.
+package responses
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when a response does not exist, has expired, or
+// belongs to another API key.
+var ErrNotFound = errors.New("response not found")
+
+// Record is a stored response together with the full input history that produced it.
+type Record struct {
+	ID                 string
+	Model              string
+	APIKey             string // Owner of the response; empty when proxy auth is disabled
+	PreviousResponseID string
+	Input              []byte // JSON array of input items, including inherited history
+	Response           []byte // Response object as returned to the client
+	CreatedAt          time.Time
+}
+
+// Store persists response records. Implementations must be safe for concurrent use.
+type Store interface {
+	Put(ctx context.Context, rec *Record) error
+	Get(ctx context.Context, id string) (*Record, error)
+	Delete(ctx context.Context, id string) error
+	// Cleanup removes records created before the given time.
+	Cleanup(ctx context.Context, before time.Time) (int64, error)
+}
+
+// MemoryStore is a bounded in-process Store. When full, the oldest record is evicted.
+type MemoryStore struct {
+	mu      sync.Mutex
+	max     int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// NewMemoryStore creates a MemoryStore holding at most maxEntries records.
+// Records older than ttl are no longer returned by Get; ttl <= 0 keeps them
+// until they are evicted.
+func NewMemoryStore(maxEntries int, ttl time.Duration) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	return &MemoryStore{
+		max:     maxEntries,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// expired reports whether a record created at createdAt has outlived ttl.
+// The cleanup loop only runs periodically, so lookups check it as well.
+func expired(createdAt time.Time, ttl time.Duration) bool {
+	return ttl > 0 && time.Since(createdAt) > ttl
+}
+
+func (s *MemoryStore) Put(_ context.Context, rec *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[rec.ID]; ok {
+		el.Value = rec
+		s.order.MoveToBack(el)
+		return nil
+	}
+	s.entries[rec.ID] = s.order.PushBack(rec)
+	for s.order.Len() > s.max {
+		oldest := s.order.Front()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*Record).ID)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[id]
+	if !ok || expired(el.Value.(*Record).CreatedAt, s.ttl) {
+		return nil, ErrNotFound
+	}
+	return el.Value.(*Record), nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.order.Remove(el)
+	delete(s.entries, id)
+	return nil
+}
+
+func (s *MemoryStore) Cleanup(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var removed int64
+	for el := s.order.Front(); el != nil; {
+		next := el.Next()
+		rec := el.Value.(*Record)
+		if rec.CreatedAt.Before(before) {
+			s.order.Remove(el)
+			delete(s.entries, rec.ID)
+			removed++
+		}
+		el = next
+	}
+	return removed, nil
+}
@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// EstimateEmbeddingUsage counts input tokens locally for upstreams that do not
+// report usage on embedding calls (Gemini batchEmbedContents).
+func EstimateEmbeddingUsage(model string, input []string) *ir.Usage {
+	enc, err := tokenizerForModel(model)
+	if err != nil {
+		return nil
+	}
+	var total int64
+	for _, text := range input {
+		ids, _, errEnc := enc.Encode(text)
+		if errEnc != nil {
+			return nil
+		}
+		total += int64(len(ids))
+	}
+	return &ir.Usage{PromptTokens: total, TotalTokens: total}
+}
+
+// FinishEmbedding fills in missing usage, publishes it and renders the response in
+// the client's format.
+func FinishEmbedding(ctx context.Context, reporter *UsageReporter, to provider.Format, req *ir.UnifiedEmbeddingRequest, resp *ir.UnifiedEmbeddingResponse) (provider.Response, error) {
+	if resp.Usage == nil {
+		resp.Usage = EstimateEmbeddingUsage(req.Model, req.Input)
+	}
+	if resp.Usage != nil {
+		reporter.Publish(ctx, resp.Usage)
+	}
+	payload, err := stream.TranslateEmbeddingResponse(to, resp, req)
+	if err != nil {
+		return provider.Response{}, err
+	}
+	return provider.Response{Payload: payload}, nil
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
 	"github.com/nghyane/llm-mux/internal/sseutil"
 	"github.com/nghyane/llm-mux/internal/streamutil"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/nghyane/llm-mux/internal/util"
@@ -277,6 +278,45 @@ func (e *AIStudioExecutor) CountTokens(ctx context.Context, auth *provider.Auth,
 	return provider.Response{Payload: resp.Body}, nil
 }
 
+// Embed implements provider.EmbeddingExecutor by relaying batchEmbedContents through the websocket.
+func (e *AIStudioExecutor) Embed(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	embedReq, err := stream.ConvertEmbeddingRequestToIR(opts.SourceFormat, req.Model, req.Payload, req.Metadata)
+	if err != nil {
+		return resp, fmt.Errorf("translate request: %w", err)
+	}
+	body, err := from_ir.ToGeminiEmbeddingRequest(embedReq)
+	if err != nil {
+		return resp, fmt.Errorf("translate request: %w", err)
+	}
+
+	wsReq := &wsrelay.HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     e.buildEndpoint(req.Model, "batchEmbedContents", ""),
+		Headers: http.Header{"Content-Type": []string{"application/json"}},
+		Body:    body,
+	}
+	var authID string
+	if auth != nil {
+		authID = auth.ID
+	}
+	wsResp, err := e.relay.NonStream(ctx, authID, wsReq)
+	if err != nil {
+		return resp, err
+	}
+	if wsResp.Status < 200 || wsResp.Status >= 300 {
+		return resp, executor.NewStatusError(wsResp.Status, string(wsResp.Body), nil)
+	}
+
+	parsed, err := to_ir.ParseGeminiEmbeddingResponse(wsResp.Body)
+	if err != nil {
+		return resp, err
+	}
+	return executor.FinishEmbedding(ctx, reporter, opts.SourceFormat, embedReq, parsed)
+}
+
 func (e *AIStudioExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
 	_ = ctx
 	return auth, nil
@@ -132,6 +132,7 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 		}
 		return resp, err
 	}
+	reportClaudeQuota(ctx, auth, httpResp)
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
 		b, _ := io.ReadAll(httpResp.Body)
 		log.Debugf("request error, error status: %d, error body: %s", httpResp.StatusCode, executor.SummarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
@@ -235,6 +236,7 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 		}
 		return nil, err
 	}
+	reportClaudeQuota(ctx, auth, httpResp)
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
 		b, _ := io.ReadAll(httpResp.Body)
 		log.Debugf("request error, error status: %d, error body: %s", httpResp.StatusCode, executor.SummarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
@@ -542,6 +544,15 @@ func applyClaudeHeaders(r *http.Request, auth *provider.Auth, apiKey string, str
 	util.ApplyCustomHeadersFromAttrs(r, attrs)
 }
 
+// reportClaudeQuota passes the unified rate-limit headers of a response on to
+// quota-aware auth selection.
+func reportClaudeQuota(ctx context.Context, auth *provider.Auth, resp *http.Response) {
+	if auth == nil {
+		return
+	}
+	provider.ReportRealQuota(ctx, auth.ID, provider.ClaudeQuotaFromHeaders(resp.Header, time.Now()))
+}
+
 func claudeCreds(a *provider.Auth) (apiKey, baseURL string) {
 	return executor.ExtractCreds(a, executor.ClaudeCredsConfig)
 }
@@ -43,7 +43,7 @@ func (e *ClineExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, nil)
+	body, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, stream.FileOwnerMetadata(req.Metadata))
 	if err != nil {
 		return resp, err
 	}
@@ -110,7 +110,7 @@ func (e *ClineExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, nil)
+	body, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, stream.FileOwnerMetadata(req.Metadata))
 	if err != nil {
 		return nil, err
 	}
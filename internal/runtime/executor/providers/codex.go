@@ -80,6 +80,7 @@ func (e *CodexExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 			log.Errorf("codex executor: close response body error: %v", errClose)
 		}
 	}()
+	reportCodexQuota(ctx, auth, provider.CodexQuotaFromHeaders(httpResp.Header, time.Now()))
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
 		result := executor.HandleHTTPError(httpResp, "codex executor")
 		return resp, result.Error
@@ -96,6 +97,10 @@ func (e *CodexExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 		}
 
 		line = bytes.TrimSpace(line[5:])
+		if quota := provider.CodexQuotaFromEvent(line, time.Now()); quota != nil {
+			reportCodexQuota(ctx, auth, quota)
+			continue
+		}
 		if gjson.GetBytes(line, "type").String() != "response.completed" {
 			continue
 		}
@@ -154,6 +159,7 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 		}
 		return nil, err
 	}
+	reportCodexQuota(ctx, auth, provider.CodexQuotaFromHeaders(httpResp.Header, time.Now()))
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
 		data, readErr := io.ReadAll(httpResp.Body)
 		if errClose := httpResp.Body.Close(); errClose != nil {
@@ -171,6 +177,9 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	translator := stream.NewStreamTranslator(e.Cfg, from, from.String(), req.Model, messageID, streamCtx)
 	processor := &codexStreamProcessor{
 		translator: translator,
+		reportQuota: func(quota *provider.RealQuotaSnapshot) {
+			reportCodexQuota(ctx, auth, quota)
+		},
 	}
 
 	return stream.RunSSEStream(ctx, httpResp.Body, reporter, processor, stream.StreamConfig{
@@ -180,10 +189,18 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 }
 
 type codexStreamProcessor struct {
-	translator *stream.StreamTranslator
+	translator  *stream.StreamTranslator
+	reportQuota func(*provider.RealQuotaSnapshot)
 }
 
 func (p *codexStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, error) {
+	if bytes.Contains(line, []byte("codex.rate_limits")) {
+		payload := bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(line), []byte("data:")))
+		if quota := provider.CodexQuotaFromEvent(payload, time.Now()); quota != nil {
+			p.reportQuota(quota)
+			return nil, nil, nil
+		}
+	}
 	events, err := to_ir.ParseOpenAIChunk(line)
 	if err != nil {
 		return nil, nil, err
@@ -548,6 +565,14 @@ func applyCodexHeaders(r *http.Request, auth *provider.Auth, token string) {
 	util.ApplyCustomHeadersFromAttrs(r, attrs)
 }
 
+// reportCodexQuota passes the window usage the Codex backend reported on to
+// quota-aware auth selection.
+func reportCodexQuota(ctx context.Context, auth *provider.Auth, quota *provider.RealQuotaSnapshot) {
+	if auth != nil {
+		provider.ReportRealQuota(ctx, auth.ID, quota)
+	}
+}
+
 func codexCreds(a *provider.Auth) (apiKey, baseURL string) {
 	return executor.ExtractCreds(a, executor.CodexCredsConfig)
 }
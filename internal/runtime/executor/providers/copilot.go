@@ -49,7 +49,7 @@ func (e *CopilotExecutor) Execute(ctx context.Context, auth *provider.Auth, req
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, errTranslate := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, nil)
+	body, errTranslate := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, stream.FileOwnerMetadata(req.Metadata))
 	if errTranslate != nil {
 		return resp, errTranslate
 	}
@@ -112,7 +112,7 @@ func (e *CopilotExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, errTranslate := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, nil)
+	body, errTranslate := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, stream.FileOwnerMetadata(req.Metadata))
 	if errTranslate != nil {
 		return nil, errTranslate
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
 	"github.com/nghyane/llm-mux/internal/sseutil"
 	"github.com/nghyane/llm-mux/internal/streamutil"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/preprocess"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
@@ -378,6 +379,70 @@ func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 	return provider.Response{Payload: data}, nil
 }
 
+// Embed implements provider.EmbeddingExecutor using batchEmbedContents.
+func (e *GeminiExecutor) Embed(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	apiKey, bearer := geminiCreds(auth)
+
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	embedReq, err := stream.ConvertEmbeddingRequestToIR(opts.SourceFormat, req.Model, req.Payload, req.Metadata)
+	if err != nil {
+		return resp, fmt.Errorf("translate request: %w", err)
+	}
+	body, err := from_ir.ToGeminiEmbeddingRequest(embedReq)
+	if err != nil {
+		return resp, fmt.Errorf("translate request: %w", err)
+	}
+
+	baseURL := resolveGeminiBaseURL(auth)
+	ub := executor.GetURLBuilder()
+	defer ub.Release()
+	ub.Grow(128)
+	ub.WriteString(baseURL)
+	ub.WriteString("/")
+	ub.WriteString(executor.GeminiGLAPIVersion)
+	ub.WriteString("/models/")
+	ub.WriteString(req.Model)
+	ub.WriteString(":batchEmbedContents")
+	url := ub.String()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	executor.SetCommonHeaders(httpReq, "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("x-goog-api-key", apiKey)
+	} else if bearer != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	applyGeminiHeaders(httpReq, auth)
+
+	httpClient := e.NewHTTPClient(ctx, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return resp, executor.NewTimeoutError("request timed out")
+		}
+		return resp, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return resp, executor.HandleHTTPError(httpResp, "gemini executor").Error
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	parsed, err := to_ir.ParseGeminiEmbeddingResponse(data)
+	if err != nil {
+		return resp, err
+	}
+	return executor.FinishEmbedding(ctx, reporter, opts.SourceFormat, embedReq, parsed)
+}
+
 func (e *GeminiExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
 	if auth == nil {
 		return nil, fmt.Errorf("gemini executor: auth is nil")
@@ -0,0 +1,165 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// geminiLivePath is the Live API (BidiGenerateContent) WebSocket path, relative
+// to the Gemini base URL.
+const geminiLivePath = "/ws/google.ai.generativelanguage." + executor.GeminiGLAPIVersion + ".GenerativeService.BidiGenerateContent"
+
+// geminiLiveSetupTimeout bounds the wait for setupComplete after the setup
+// message is sent.
+const geminiLiveSetupTimeout = 30 * time.Second
+
+// DialLive opens a Live API session with the auth's credential and sends the
+// client's setup message, req.Payload, with the model replaced by req.Model.
+// It waits for setupComplete so that rejected credentials and exhausted quota
+// surface here, where the manager can still move on to another auth.
+func (e *GeminiExecutor) DialLive(ctx context.Context, auth *provider.Auth, req provider.Request, _ provider.Options) (conn provider.LiveConn, err error) {
+	apiKey, bearer := geminiCreds(auth)
+
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	setup, err := sjson.SetBytes(req.Payload, "setup.model", "models/"+req.Model)
+	if err != nil {
+		return nil, fmt.Errorf("translate request: %w", err)
+	}
+
+	liveURL, err := geminiLiveURL(resolveGeminiBaseURL(auth), apiKey)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	if apiKey == "" && bearer != "" {
+		header.Set("Authorization", "Bearer "+bearer)
+	}
+	applyGeminiHeaders(&http.Request{Header: header}, auth)
+
+	ws, httpResp, err := executor.NewProxyAwareWSDialer(e.Cfg, auth).DialContext(ctx, liveURL, header)
+	if err != nil {
+		if httpResp != nil {
+			defer func() { _ = httpResp.Body.Close() }()
+			return nil, executor.HandleHTTPError(httpResp, "gemini executor").Error
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, executor.NewTimeoutError("request timed out")
+		}
+		return nil, err
+	}
+
+	if err = ws.WriteMessage(websocket.TextMessage, setup); err != nil {
+		_ = ws.Close()
+		return nil, err
+	}
+	_ = ws.SetReadDeadline(time.Now().Add(geminiLiveSetupTimeout))
+	firstType, first, err := ws.ReadMessage()
+	_ = ws.SetReadDeadline(time.Time{})
+	if err != nil {
+		_ = ws.Close()
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			return nil, executor.NewStatusError(geminiLiveCloseStatus(closeErr), closeErr.Text, nil)
+		}
+		return nil, err
+	}
+	if !gjson.GetBytes(first, "setupComplete").Exists() {
+		_ = ws.Close()
+		return nil, executor.NewStatusError(http.StatusBadGateway, "gemini executor: unexpected live setup response: "+string(first), nil)
+	}
+
+	return &geminiLiveConn{
+		Conn:      ws,
+		ctx:       ctx,
+		e:         e,
+		auth:      auth,
+		model:     req.Model,
+		setup:     first,
+		setupType: firstType,
+	}, nil
+}
+
+// geminiLiveURL turns the HTTP base URL into the Live WebSocket URL. API keys
+// travel in the key query parameter, as the Live API does not read headers for
+// them on the upgrade request.
+func geminiLiveURL(baseURL, apiKey string) (string, error) {
+	u, err := url.Parse(baseURL + geminiLivePath)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	if apiKey != "" {
+		q := u.Query()
+		q.Set("key", apiKey)
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
+}
+
+// geminiLiveCloseStatus maps the close frame the Live API sends when it rejects
+// a session to the HTTP status the manager uses for cooldowns.
+func geminiLiveCloseStatus(ce *websocket.CloseError) int {
+	text := strings.ToLower(ce.Text)
+	switch {
+	case strings.Contains(text, "quota") || strings.Contains(text, "resource exhausted") || strings.Contains(text, "rate limit"):
+		return http.StatusTooManyRequests
+	case strings.Contains(text, "api key") || strings.Contains(text, "unauthenticated") || strings.Contains(text, "credential"):
+		return http.StatusUnauthorized
+	case strings.Contains(text, "permission"):
+		return http.StatusForbidden
+	case ce.Code == websocket.CloseInvalidFramePayloadData || ce.Code == websocket.ClosePolicyViolation:
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// geminiLiveConn relays a Live API session and records usage for every server
+// message that carries usageMetadata. The Live API reports usage once per
+// model turn, so each report becomes its own usage record.
+type geminiLiveConn struct {
+	*websocket.Conn
+	ctx   context.Context
+	e     *GeminiExecutor
+	auth  *provider.Auth
+	model string
+	// setup is the setupComplete message read by DialLive, handed to the
+	// caller on the first ReadMessage.
+	setup     []byte
+	setupType int
+}
+
+func (c *geminiLiveConn) ReadMessage() (int, []byte, error) {
+	if c.setup != nil {
+		data := c.setup
+		c.setup = nil
+		return c.setupType, data, nil
+	}
+	messageType, data, err := c.Conn.ReadMessage()
+	if err != nil {
+		return messageType, data, err
+	}
+	if usage := to_ir.ParseGeminiUsage(data); usage != nil {
+		c.e.NewUsageReporter(c.ctx, c.e.Identifier(), c.model, c.auth).Publish(c.ctx, usage)
+	}
+	return messageType, data, nil
+}
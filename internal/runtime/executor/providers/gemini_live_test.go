@@ -0,0 +1,109 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/usage"
+	"github.com/tidwall/gjson"
+)
+
+type usageCapture chan usage.Record
+
+func (u usageCapture) HandleUsage(_ context.Context, record usage.Record) { u <- record }
+
+func TestGeminiDialLiveRelaysAndCountsUsage(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != geminiLivePath || r.URL.Query().Get("key") != "k" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, setup, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if got := gjson.GetBytes(setup, "setup.model").String(); got != "models/gemini-live-2.5-flash-preview" {
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "model "+got))
+			return
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"setupComplete":{}}`))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.WriteMessage(websocket.BinaryMessage, []byte(`{"serverContent":{"turnComplete":true},"echo":`+string(msg)+`}`))
+		_ = conn.WriteMessage(websocket.BinaryMessage, []byte(`{"usageMetadata":{"promptTokenCount":7,"responseTokenCount":5,"totalTokenCount":12}}`))
+	}))
+	defer srv.Close()
+
+	records := make(usageCapture, 4)
+	usage.RegisterPlugin(records)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	usage.StartDefault(ctx)
+
+	e := NewGeminiExecutor(&config.Config{})
+	auth := &provider.Auth{ID: "live-auth", Provider: "gemini", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	req := provider.Request{Model: "gemini-live-2.5-flash-preview", Payload: []byte(`{"setup":{"model":"models/alias"}}`)}
+	conn, err := e.DialLive(ctx, auth, req, provider.Options{})
+	if err != nil {
+		t.Fatalf("DialLive: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, msg, err := conn.ReadMessage(); err != nil || !gjson.GetBytes(msg, "setupComplete").Exists() {
+		t.Fatalf("first message = %s, %v; want setupComplete", msg, err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"realtimeInput":{"text":"hi"}}`)); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	if _, msg, err := conn.ReadMessage(); err != nil || gjson.GetBytes(msg, "echo.realtimeInput.text").String() != "hi" {
+		t.Fatalf("relayed message = %s, %v", msg, err)
+	}
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("reading usage message: %v", err)
+	}
+
+	select {
+	case record := <-records:
+		if record.AuthID != "live-auth" || record.Usage == nil || record.Usage.PromptTokens != 7 || record.Usage.CompletionTokens != 5 {
+			t.Errorf("usage record = %+v", record)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no usage recorded for usageMetadata")
+	}
+}
+
+func TestGeminiDialLiveRejectedSetup(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _, _ = conn.ReadMessage()
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "You exceeded your current quota"))
+	}))
+	defer srv.Close()
+
+	e := NewGeminiExecutor(&config.Config{})
+	auth := &provider.Auth{ID: "a", Provider: "gemini", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	_, err := e.DialLive(context.Background(), auth, provider.Request{Model: "m", Payload: []byte(`{"setup":{}}`)}, provider.Options{})
+	se, ok := err.(provider.StatusCodeError)
+	if !ok || se.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("DialLive error = %v, want a 429 status error", err)
+	}
+}
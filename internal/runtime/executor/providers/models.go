@@ -237,6 +237,13 @@ func ParseGLAPIModels(body []byte, providerType string) []*registry.ModelInfo {
 			InputTokenLimit:  int(inputTokenLimit),
 			OutputTokenLimit: int(outputTokenLimit),
 		}
+		for _, method := range value.Get("supportedGenerationMethods").Array() {
+			if method.String() == "embedContent" {
+				modelInfo.Type = registry.ModelTypeEmbedding
+				modelInfo.SupportedGenerationMethods = []string{"embedContent", "batchEmbedContents"}
+				break
+			}
+		}
 
 		registry.ApplyGeminiMeta(modelInfo)
 
@@ -15,6 +15,9 @@ import (
 	"github.com/nghyane/llm-mux/internal/runtime/executor"
 	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
 	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/nghyane/llm-mux/internal/util"
 	"github.com/tidwall/sjson"
 )
@@ -43,7 +46,7 @@ func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *provider.Auth,
 	}
 
 	from := opts.SourceFormat
-	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, opts.Stream, nil)
+	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, opts.Stream, stream.FileOwnerMetadata(req.Metadata))
 	if err != nil {
 		return resp, err
 	}
@@ -115,7 +118,7 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *provider
 		return nil, err
 	}
 	from := opts.SourceFormat
-	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, nil)
+	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, true, stream.FileOwnerMetadata(req.Metadata))
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +171,7 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *provider
 
 func (e *OpenAICompatExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
 	from := opts.SourceFormat
-	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, nil)
+	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, stream.FileOwnerMetadata(req.Metadata))
 	if err != nil {
 		return provider.Response{}, err
 	}
@@ -193,6 +196,83 @@ func (e *OpenAICompatExecutor) CountTokens(ctx context.Context, auth *provider.A
 	return provider.Response{Payload: usageJSON}, nil
 }
 
+// Embed implements provider.EmbeddingExecutor against the upstream /embeddings endpoint.
+// OpenAI-format requests are forwarded as-is so that token-array inputs keep working.
+func (e *OpenAICompatExecutor) Embed(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	baseURL, apiKey := e.resolveCredentials(auth)
+	if baseURL == "" {
+		err = executor.NewStatusError(http.StatusUnauthorized, "missing provider baseURL", nil)
+		return
+	}
+
+	from := opts.SourceFormat
+	passthrough := from == provider.FormatOpenAI
+	var embedReq *ir.UnifiedEmbeddingRequest
+	body := req.Payload
+	if !passthrough {
+		embedReq, err = stream.ConvertEmbeddingRequestToIR(from, req.Model, req.Payload, req.Metadata)
+		if err != nil {
+			return resp, fmt.Errorf("translate request: %w", err)
+		}
+		if body, err = from_ir.ToOpenAIEmbeddingRequest(embedReq); err != nil {
+			return resp, fmt.Errorf("translate request: %w", err)
+		}
+	}
+	upstreamModel := req.Model
+	if modelOverride := e.resolveUpstreamModel(req.Model, auth); modelOverride != "" {
+		upstreamModel = modelOverride
+	}
+	body = e.overrideModel(body, upstreamModel)
+
+	url := strings.TrimSuffix(baseURL, "/") + "/embeddings"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	executor.SetCommonHeaders(httpReq, "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+
+	httpClient := e.NewHTTPClient(ctx, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return resp, executor.NewTimeoutError("request timed out")
+		}
+		return resp, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return resp, executor.HandleHTTPError(httpResp, "openai-compat executor").Error
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if passthrough {
+		reporter.Publish(ctx, executor.ExtractUsageFromOpenAIResponse(data))
+		reporter.EnsurePublished(ctx)
+		data, _ = sjson.SetBytes(data, "model", req.Model)
+		return provider.Response{Payload: data}, nil
+	}
+	parsed, err := to_ir.ParseOpenAIEmbeddingResponse(data)
+	if err != nil {
+		return resp, err
+	}
+	return executor.FinishEmbedding(ctx, reporter, from, embedReq, parsed)
+}
+
 func (e *OpenAICompatExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
 	_ = ctx
 	return auth, nil
@@ -19,6 +19,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/runtime/executor"
 	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
 	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/nghyane/llm-mux/internal/util"
@@ -353,6 +354,97 @@ func (e *VertexExecutor) countTokensWithStrategy(ctx context.Context, auth *prov
 	return provider.Response{Payload: data}, nil
 }
 
+// vertexEmbeddingBatchSize returns how many instances one :predict call may carry.
+// gemini-embedding-001 accepts a single instance; the text-embedding models take up to 250.
+func vertexEmbeddingBatchSize(model string) int {
+	if strings.HasPrefix(model, "gemini-embedding") {
+		return 1
+	}
+	return 250
+}
+
+// Embed implements provider.EmbeddingExecutor using the publisher model :predict endpoint.
+// Inputs are split into as many calls as the model's instance limit requires and the
+// results are merged back in input order.
+func (e *VertexExecutor) Embed(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	strategy, err := e.resolveStrategy(auth)
+	if err != nil {
+		return resp, err
+	}
+
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.TrackFailure(ctx, &err)
+
+	embedReq, err := stream.ConvertEmbeddingRequestToIR(opts.SourceFormat, req.Model, req.Payload, req.Metadata)
+	if err != nil {
+		return resp, fmt.Errorf("translate request: %w", err)
+	}
+
+	token, errTok := strategy.GetToken(ctx, e.Cfg, auth)
+	if errTok != nil {
+		log.Errorf("vertex executor: access token error: %v", errTok)
+		return resp, executor.NewStatusError(500, "internal server error", nil)
+	}
+
+	merged := &ir.UnifiedEmbeddingResponse{Model: req.Model}
+	size := vertexEmbeddingBatchSize(req.Model)
+	for offset := 0; offset < len(embedReq.Input); offset += size {
+		chunk := *embedReq
+		chunk.Input = embedReq.Input[offset:min(offset+size, len(embedReq.Input))]
+		parsed, errChunk := e.predictEmbeddings(ctx, strategy, auth, token, &chunk, opts)
+		if errChunk != nil {
+			return resp, errChunk
+		}
+		for _, emb := range parsed.Embeddings {
+			emb.Index += offset
+			merged.Embeddings = append(merged.Embeddings, emb)
+		}
+		if parsed.Usage != nil {
+			if merged.Usage == nil {
+				merged.Usage = &ir.Usage{}
+			}
+			merged.Usage.PromptTokens += parsed.Usage.PromptTokens
+			merged.Usage.TotalTokens += parsed.Usage.TotalTokens
+		}
+	}
+	return executor.FinishEmbedding(ctx, reporter, opts.SourceFormat, embedReq, merged)
+}
+
+// predictEmbeddings sends one :predict call for the inputs of req.
+func (e *VertexExecutor) predictEmbeddings(ctx context.Context, strategy VertexAuthStrategy, auth *provider.Auth, token string, req *ir.UnifiedEmbeddingRequest, opts provider.Options) (*ir.UnifiedEmbeddingResponse, error) {
+	body, err := from_ir.ToVertexEmbeddingRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("translate request: %w", err)
+	}
+
+	url := strategy.BuildURL(req.Model, "predict", opts)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	executor.SetCommonHeaders(httpReq, "application/json")
+	strategy.ApplyAuth(httpReq, token)
+	applyGeminiHeaders(httpReq, auth)
+
+	httpClient := e.NewHTTPClient(ctx, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, executor.NewTimeoutError("request timed out")
+		}
+		return nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return nil, executor.HandleHTTPError(httpResp, "gemini-vertex executor").Error
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return to_ir.ParseVertexEmbeddingResponse(data)
+}
+
 func (e *VertexExecutor) Refresh(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
 	return auth, nil
 }
@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func TestVertexEmbedSplitsPerInstanceLimit(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		instances := gjson.GetBytes(body, "instances").Array()
+		if len(instances) != 1 {
+			t.Errorf("expected one instance per call, got %d", len(instances))
+		}
+		// Echo the input length back as the single vector value.
+		n := len(instances[0].Get("content").String())
+		_, _ = w.Write([]byte(`{"predictions":[{"embeddings":{"values":[` + strconv.Itoa(n) + `],"statistics":{"token_count":2}}}]}`))
+	}))
+	defer srv.Close()
+
+	e := NewVertexExecutor(&config.Config{})
+	auth := &provider.Auth{ID: "v", Provider: "vertex", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	req := provider.Request{
+		Model:   "gemini-embedding-001",
+		Payload: []byte(`{"model":"gemini-embedding-001","input":["a","bb","ccc"]}`),
+	}
+	resp, err := e.Embed(context.Background(), auth, req, provider.Options{SourceFormat: provider.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("upstream calls = %d, want 3", got)
+	}
+	data := gjson.GetBytes(resp.Payload, "data").Array()
+	if len(data) != 3 {
+		t.Fatalf("got %d embeddings, want 3", len(data))
+	}
+	for i, d := range data {
+		if d.Get("index").Int() != int64(i) || d.Get("embedding.0").Int() != int64(i+1) {
+			t.Errorf("embedding %d = %s", i, d.Raw)
+		}
+	}
+	if got := gjson.GetBytes(resp.Payload, "usage.prompt_tokens").Int(); got != 6 {
+		t.Errorf("prompt_tokens = %d, want 6", got)
+	}
+}
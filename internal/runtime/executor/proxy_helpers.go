@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/nghyane/llm-mux/internal/config"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
@@ -47,6 +48,53 @@ func NewProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *prov
 	return httpClient
 }
 
+// NewProxyAwareWSDialer returns a WebSocket dialer that honours the same proxy
+// settings as NewProxyAwareHTTPClient: the auth proxy first, then the global one.
+func NewProxyAwareWSDialer(cfg *config.Config, auth *provider.Auth) *websocket.Dialer {
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 30 * time.Second,
+	}
+
+	var proxyURL string
+	if auth != nil {
+		proxyURL = strings.TrimSpace(auth.ProxyURL)
+	}
+	if proxyURL == "" && cfg != nil {
+		proxyURL = strings.TrimSpace(cfg.ProxyURL)
+	}
+	if proxyURL == "" {
+		return dialer
+	}
+
+	parsedURL, errParse := url.Parse(proxyURL)
+	if errParse != nil {
+		log.Errorf("parse proxy URL failed: %v", errParse)
+		return dialer
+	}
+	switch parsedURL.Scheme {
+	case "socks5":
+		var proxyAuth *proxy.Auth
+		if parsedURL.User != nil {
+			username := parsedURL.User.Username()
+			password, _ := parsedURL.User.Password()
+			proxyAuth = &proxy.Auth{User: username, Password: password}
+		}
+		socks, errSOCKS5 := proxy.SOCKS5("tcp", parsedURL.Host, proxyAuth, proxy.Direct)
+		if errSOCKS5 != nil {
+			log.Errorf("create SOCKS5 dialer failed: %v", errSOCKS5)
+			return dialer
+		}
+		dialer.Proxy = nil
+		dialer.NetDial = socks.Dial
+	case "http", "https":
+		dialer.Proxy = http.ProxyURL(parsedURL)
+	default:
+		log.Errorf("unsupported proxy scheme: %s", parsedURL.Scheme)
+	}
+	return dialer
+}
+
 func buildProxyTransport(proxyURLStr string) *http.Transport {
 	if proxyURLStr == "" {
 		return nil
@@ -0,0 +1,46 @@
+package stream
+
+import (
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+)
+
+// ConvertEmbeddingRequestToIR parses an embedding request in the client's format.
+// The routed model name replaces whatever the client sent.
+func ConvertEmbeddingRequestToIR(from provider.Format, model string, payload []byte, metadata map[string]any) (*ir.UnifiedEmbeddingRequest, error) {
+	var (
+		req *ir.UnifiedEmbeddingRequest
+		err error
+	)
+	if provider.IsGeminiFormat(from.String()) {
+		req, err = to_ir.ParseGeminiEmbeddingRequest(payload)
+	} else {
+		req, err = to_ir.ParseOpenAIEmbeddingRequest(payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if model != "" {
+		req.Model = model
+	}
+	if metadata != nil {
+		req.Metadata = make(map[string]any, len(metadata))
+		for k, v := range metadata {
+			req.Metadata[k] = v
+		}
+	}
+	return req, nil
+}
+
+// TranslateEmbeddingResponse renders an embedding response in the client's format.
+// Gemini clients get the single embedContent shape when the request metadata
+// action is "embedContent", and the batch shape otherwise.
+func TranslateEmbeddingResponse(to provider.Format, resp *ir.UnifiedEmbeddingResponse, req *ir.UnifiedEmbeddingRequest) ([]byte, error) {
+	if provider.IsGeminiFormat(to.String()) {
+		action, _ := req.Metadata["action"].(string)
+		return from_ir.ToGeminiEmbeddingResponse(resp, action != "embedContent")
+	}
+	return from_ir.ToOpenAIEmbeddingResponse(resp, req.Model, req.EncodingFormat)
+}
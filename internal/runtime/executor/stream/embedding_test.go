@@ -0,0 +1,37 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestTranslateEmbeddingResponseKeepsGeminiAction(t *testing.T) {
+	resp := &ir.UnifiedEmbeddingResponse{Embeddings: []ir.Embedding{{Index: 0, Values: []float64{0.5, 1}}, {Index: 1, Values: []float64{2}}}}
+
+	tests := []struct {
+		action string
+		path   string
+		want   string
+	}{
+		{"embedContent", "embedding.values", "[0.5,1]"},
+		{"batchEmbedContents", "embeddings.#.values", "[[0.5,1],[2]]"},
+	}
+	for _, tt := range tests {
+		req, err := ConvertEmbeddingRequestToIR(provider.FormatGemini, "gemini-embedding-001",
+			[]byte(`{"requests":[{"content":{"parts":[{"text":"a"}]}},{"content":{"parts":[{"text":"b"}]}}]}`),
+			map[string]any{"action": tt.action})
+		if err != nil {
+			t.Fatalf("%s: ConvertEmbeddingRequestToIR: %v", tt.action, err)
+		}
+		out, err := TranslateEmbeddingResponse(provider.FormatGemini, resp, req)
+		if err != nil {
+			t.Fatalf("%s: TranslateEmbeddingResponse: %v", tt.action, err)
+		}
+		if got := gjson.GetBytes(out, tt.path).Raw; got != tt.want {
+			t.Errorf("%s: %s = %s, want %s (%s)", tt.action, tt.path, got, tt.want, out)
+		}
+	}
+}
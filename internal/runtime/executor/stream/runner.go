@@ -148,6 +148,12 @@ func RunSSEStream(
 				log.Errorf("%s: panic in stream goroutine: %v", cfg.ExecutorName, r)
 			}
 		}()
+		defer func() {
+			// A cancelled hedge attempt may stop before its usage arrives.
+			if reporter != nil && provider.HedgeLost(ctx) {
+				reporter.EnsurePublished(ctx)
+			}
+		}()
 
 		// Use StreamReader for context-aware cancellation and idle detection
 		idleTimeout := cfg.IdleTimeout
@@ -1,6 +1,8 @@
 package stream
 
 import (
+	"bytes"
+
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
@@ -141,6 +143,16 @@ func NormalizeIRLimits(model string, req *ir.UnifiedChatRequest) {
 	}
 }
 
+// FileOwnerMetadata returns only the stored-file owner of metadata, for
+// translations that must not pick up the thinking overrides it may carry.
+func FileOwnerMetadata(metadata map[string]any) map[string]any {
+	owner, ok := metadata[ir.MetaFileOwner]
+	if !ok {
+		return nil
+	}
+	return map[string]any{ir.MetaFileOwner: owner}
+}
+
 func ExtractThinkingFromMetadata(metadata map[string]any) (budget *int, include *bool, hasOverride bool) {
 	if metadata == nil {
 		return nil, nil, false
@@ -176,7 +188,9 @@ func TranslateToClaude(cfg *config.Config, from provider.Format, model string, p
 
 func TranslateToOpenAI(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
 	fromStr := from.String()
-	if fromStr == "openai" || fromStr == "cline" {
+	// OpenAI payloads pass through as-is unless they reference files, which
+	// only the IR path resolves.
+	if (fromStr == "openai" || fromStr == "cline") && (metadata[ir.MetaFileOwner] == nil || !bytes.Contains(payload, []byte(`"file_id"`))) {
 		return sseutil.ApplyPayloadConfig(cfg, model, payload), nil
 	}
 
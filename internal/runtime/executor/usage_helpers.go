@@ -102,23 +102,30 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, u *ir.Usage, fai
 	if r == nil {
 		return
 	}
-	if u == nil && !failed {
+	// A hedged attempt cancelled in favour of its duplicate did not fail, but
+	// the upstream request was still made and is recorded.
+	hedgeLost := provider.HedgeLost(ctx)
+	if hedgeLost {
+		failed = false
+	}
+	if u == nil && !failed && !hedgeLost {
 		return
 	}
-	if u != nil && u.TotalTokens == 0 && u.PromptTokens == 0 && u.CompletionTokens == 0 && !failed {
+	if u != nil && u.TotalTokens == 0 && u.PromptTokens == 0 && u.CompletionTokens == 0 && !failed && !hedgeLost {
 		return
 	}
 	r.once.Do(func() {
 		usage.PublishRecord(ctx, usage.Record{
-			Provider:    r.provider,
-			Model:       r.model,
-			Source:      r.source,
-			APIKey:      r.apiKey,
-			AuthID:      r.authID,
-			AuthIndex:   r.authIndex,
-			RequestedAt: r.requestedAt,
-			Failed:      failed,
-			Usage:       u,
+			Provider:       r.provider,
+			Model:          r.model,
+			Source:         r.source,
+			APIKey:         r.apiKey,
+			AuthID:         r.authID,
+			AuthIndex:      r.authIndex,
+			RequestedAt:    r.requestedAt,
+			Failed:         failed,
+			HedgeCancelled: hedgeLost,
+			Usage:          u,
 		})
 	})
 }
@@ -129,15 +136,16 @@ func (r *usageReporter) ensurePublished(ctx context.Context) {
 	}
 	r.once.Do(func() {
 		usage.PublishRecord(ctx, usage.Record{
-			Provider:    r.provider,
-			Model:       r.model,
-			Source:      r.source,
-			APIKey:      r.apiKey,
-			AuthID:      r.authID,
-			AuthIndex:   r.authIndex,
-			RequestedAt: r.requestedAt,
-			Failed:      false,
-			Usage:       nil,
+			Provider:       r.provider,
+			Model:          r.model,
+			Source:         r.source,
+			APIKey:         r.apiKey,
+			AuthID:         r.authID,
+			AuthIndex:      r.authIndex,
+			RequestedAt:    r.requestedAt,
+			Failed:         false,
+			HedgeCancelled: provider.HedgeLost(ctx),
+			Usage:          nil,
 		})
 	})
 }
@@ -151,6 +159,9 @@ func apiKeyFromContext(ctx context.Context) string {
 	if ctx == nil {
 		return ""
 	}
+	if apiKey := usage.APIKeyFromContext(ctx); apiKey != "" {
+		return apiKey
+	}
 	ginCtx, ok := ctx.Value("gin").(*gin.Context)
 	if !ok || ginCtx == nil {
 		return ""
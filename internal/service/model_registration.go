@@ -215,12 +215,16 @@ func handleOpenAICompatProvider(a *provider.Auth, compatProviderKey, compatDispl
 				if modelID == "" {
 					modelID = m.Name
 				}
+				modelType := "openai-compatibility"
+				if strings.EqualFold(m.Type, registry.ModelTypeEmbedding) {
+					modelType = registry.ModelTypeEmbedding
+				}
 				ms = append(ms, &ModelInfo{
 					ID:          modelID,
 					Object:      "model",
 					Created:     time.Now().Unix(),
 					OwnedBy:     p.Name,
-					Type:        "openai-compatibility",
+					Type:        modelType,
 					DisplayName: m.Name,
 				})
 			}
@@ -293,6 +293,73 @@ func (s *Service) applyRetryConfig(cfg *config.Config) {
 	}
 }
 
+// applyRoutingConfig pushes the routing strategy, pricing catalog, hedging
+// policy, admission queue, cache affinity and quota overrides to the core
+// manager.
+func (s *Service) applyRoutingConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	pricing := cfg.Pricing
+	s.coreManager.SetCostRouting(cfg.Routing.Strategy == config.RoutingStrategyCost, func(model string) (float64, bool) {
+		price, ok := pricing.Price(model)
+		return price.Blended(), ok
+	})
+
+	if hedging := cfg.Routing.Hedging; hedging.Enabled {
+		minDelay, maxDelay := hedging.Delays()
+		s.coreManager.SetHedging(&provider.HedgePolicy{
+			Percentile: hedging.EffectivePercentile(),
+			MinDelay:   minDelay,
+			MaxDelay:   maxDelay,
+		})
+	} else {
+		s.coreManager.SetHedging(nil)
+	}
+
+	if admission := cfg.Routing.Admission; admission.Enabled {
+		s.coreManager.SetAdmission(&provider.AdmissionPolicy{
+			MaxWait:  admission.EffectiveMaxWait(),
+			MaxDepth: admission.MaxDepth,
+		})
+	} else {
+		s.coreManager.SetAdmission(nil)
+	}
+
+	if affinity := cfg.Routing.CacheAffinity; affinity.Enabled {
+		ttl, providerTTL := affinity.TTLs()
+		s.coreManager.SetCacheAffinity(&provider.CacheAffinityPolicy{TTL: ttl, ProviderTTL: providerTTL})
+	} else {
+		s.coreManager.SetCacheAffinity(nil)
+	}
+
+	s.coreManager.SetQuotaOverrides(&provider.QuotaOverrides{
+		Providers: quotaOverrides(cfg.Quota.Providers),
+		Auths:     quotaOverrides(cfg.Quota.Auths),
+	})
+}
+
+func quotaOverrides(entries map[string]config.QuotaOverride) map[string]provider.QuotaOverride {
+	if len(entries) == 0 {
+		return nil
+	}
+	out := make(map[string]provider.QuotaOverride, len(entries))
+	for key, entry := range entries {
+		override := provider.QuotaOverride{
+			WindowDuration: entry.WindowDuration(),
+			EstimatedLimit: entry.Limit,
+			StaggerBucket:  entry.StaggerDuration(),
+			StickyEnabled:  entry.Sticky,
+			RefuseOverage:  entry.RefuseOverage,
+		}
+		if quotaType, ok := provider.ParseQuotaType(entry.Type); ok {
+			override.QuotaType = &quotaType
+		}
+		out[key] = override
+	}
+	return out
+}
+
 func openAICompatInfoFromAuth(a *provider.Auth) (providerKey string, compatName string, ok bool) {
 	if a == nil {
 		return "", "", false
@@ -367,6 +434,7 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.applyRetryConfig(s.cfg)
+	s.applyRoutingConfig(s.cfg)
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
@@ -456,6 +524,7 @@ func (s *Service) Run(ctx context.Context) error {
 			return
 		}
 		s.applyRetryConfig(newCfg)
+		s.applyRoutingConfig(newCfg)
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
 		}
@@ -529,6 +598,7 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		}
 		if s.coreManager != nil {
 			s.coreManager.StopAutoRefresh()
+			s.coreManager.SaveQuotaState(ctx)
 			if qm := s.coreManager.GetQuotaManager(); qm != nil {
 				qm.Stop()
 			}
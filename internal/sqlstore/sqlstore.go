@@ -0,0 +1,166 @@
+// Package sqlstore opens the database named by a usage-style DSN for feature
+// stores that need durable state beyond usage records. Handles are shared per
+// DSN so that every store talks to the same SQLite file or Postgres pool.
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nghyane/llm-mux/internal/config"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Dialect identifies the SQL flavour behind a DB handle.
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// DB wraps a shared *sql.DB together with its dialect.
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+var (
+	mu     sync.Mutex
+	shared = make(map[string]*DB)
+)
+
+// Open returns the shared handle for dsn, opening it on first use.
+// It returns (nil, nil) when dsn is empty.
+func Open(dsn string) (*DB, error) {
+	parsed, err := config.ParseDSN(dsn)
+	if err != nil || parsed == nil {
+		return nil, err
+	}
+	switch parsed.Backend {
+	case "sqlite":
+		return OpenSQLite(parsed.Path)
+	case "postgres":
+		return openShared(parsed.URL, func() (*DB, error) { return openPostgres(parsed.URL) })
+	default:
+		return nil, fmt.Errorf("unknown backend type: %q", parsed.Backend)
+	}
+}
+
+// OpenSQLite returns the shared handle for the SQLite database at path. The
+// usage backend opens its file through here as well, so that every writer goes
+// through one connection instead of contending for the file lock.
+func OpenSQLite(path string) (*DB, error) {
+	if strings.HasPrefix(path, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return openShared("sqlite://"+filepath.Clean(path), func() (*DB, error) { return openSQLite(path) })
+}
+
+func openShared(key string, open func() (*DB, error)) (*DB, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if db, ok := shared[key]; ok {
+		return db, nil
+	}
+	db, err := open()
+	if err != nil {
+		return nil, err
+	}
+	shared[key] = db
+	return db, nil
+}
+
+// StateDSN returns the DSN for stores that must survive restarts: usage.dsn when
+// set, otherwise a SQLite database in the config directory.
+func StateDSN(cfg *config.Config) string {
+	if cfg != nil && strings.TrimSpace(cfg.Usage.DSN) != "" {
+		return cfg.Usage.DSN
+	}
+	dir := config.CredentialsDir()
+	if dir == "" {
+		return ""
+	}
+	return "sqlite://" + filepath.Join(dir, "state.db")
+}
+
+// CloseAll closes every shared handle. Used on shutdown.
+func CloseAll() {
+	mu.Lock()
+	defer mu.Unlock()
+	for dsn, db := range shared {
+		_ = db.Close()
+		delete(shared, dsn)
+	}
+}
+
+func openSQLite(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)&_pragma=cache_size(-64000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// A single connection serialises writers from every store sharing the file.
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	return &DB{DB: db, Dialect: DialectSQLite}, nil
+}
+
+func openPostgres(url string) (*DB, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return &DB{DB: db, Dialect: DialectPostgres}, nil
+}
+
+// Rebind rewrites '?' placeholders to '$n' for Postgres. Queries must not
+// contain literal question marks.
+func (d *DB) Rebind(query string) string {
+	if d.Dialect != DialectPostgres {
+		return query
+	}
+	var sb strings.Builder
+	sb.Grow(len(query) + 8)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' {
+			n++
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(n))
+			continue
+		}
+		sb.WriteByte(query[i])
+	}
+	return sb.String()
+}
+
+// Migrate executes each statement in order. Statements must be idempotent
+// (CREATE ... IF NOT EXISTS) and use types understood by both dialects
+// (TEXT, BIGINT, BOOLEAN).
+func (d *DB) Migrate(statements ...string) error {
+	for _, stmt := range statements {
+		if _, err := d.Exec(stmt); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+	return nil
+}
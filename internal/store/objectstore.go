@@ -26,6 +26,7 @@ import (
 const (
 	objectStoreConfigKey  = "config/config.yaml"
 	objectStoreAuthPrefix = "auths"
+	objectStoreQuotaKey   = "state/quota-state.json"
 )
 
 // ObjectStoreConfig captures configuration for the object storage-backed token store.
@@ -325,6 +326,28 @@ func (s *ObjectTokenStore) PersistConfig(ctx context.Context) error {
 	return s.putObject(ctx, objectStoreConfigKey, data, "application/x-yaml")
 }
 
+// LoadQuotaState implements provider.QuotaStateStore.
+func (s *ObjectTokenStore) LoadQuotaState(ctx context.Context) ([]byte, error) {
+	object, err := s.client.GetObject(ctx, s.cfg.Bucket, s.prefixedKey(objectStoreQuotaKey), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("object store: fetch quota state: %w", err)
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if isObjectNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("object store: read quota state: %w", err)
+	}
+	return data, nil
+}
+
+// SaveQuotaState implements provider.QuotaStateStore.
+func (s *ObjectTokenStore) SaveQuotaState(ctx context.Context, data []byte) error {
+	return s.putObject(ctx, objectStoreQuotaKey, data, "application/json")
+}
+
 func (s *ObjectTokenStore) ensureBucket(ctx context.Context) error {
 	exists, err := s.client.BucketExists(ctx, s.cfg.Bucket)
 	if err != nil {
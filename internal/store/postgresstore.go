@@ -25,6 +25,7 @@ const (
 	defaultConfigTable = "config_store"
 	defaultAuthTable   = "auth_store"
 	defaultConfigKey   = "config"
+	quotaStateKey      = "quota-state"
 )
 
 // PostgresStoreConfig captures configuration required to initialize a Postgres-backed store.
@@ -557,6 +558,35 @@ func (s *PostgresStore) persistConfig(ctx context.Context, data []byte) error {
 	return nil
 }
 
+// LoadQuotaState implements provider.QuotaStateStore. The state is kept as a
+// row of the config table.
+func (s *PostgresStore) LoadQuotaState(ctx context.Context) ([]byte, error) {
+	query := fmt.Sprintf("SELECT content FROM %s WHERE id = $1", s.fullTableName(s.cfg.ConfigTable))
+	var content string
+	err := s.db.QueryRowContext(ctx, query, quotaStateKey).Scan(&content)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("postgres store: load quota state: %w", err)
+	}
+	return []byte(content), nil
+}
+
+// SaveQuotaState implements provider.QuotaStateStore.
+func (s *PostgresStore) SaveQuotaState(ctx context.Context, data []byte) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, content, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (id)
+		DO UPDATE SET content = EXCLUDED.content, updated_at = NOW()
+	`, s.fullTableName(s.cfg.ConfigTable))
+	if _, err := s.db.ExecContext(ctx, query, quotaStateKey, string(data)); err != nil {
+		return fmt.Errorf("postgres store: upsert quota state: %w", err)
+	}
+	return nil
+}
+
 func (s *PostgresStore) deleteConfigRecord(ctx context.Context) error {
 	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", s.fullTableName(s.cfg.ConfigTable))
 	if _, err := s.db.ExecContext(ctx, query, defaultConfigKey); err != nil {
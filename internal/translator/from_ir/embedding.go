@@ -0,0 +1,126 @@
+package from_ir
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ToOpenAIEmbeddingRequest converts an embedding request to OpenAI /v1/embeddings format.
+// The upstream is always asked for float vectors; base64 encoding is applied on the way back.
+func ToOpenAIEmbeddingRequest(req *ir.UnifiedEmbeddingRequest) ([]byte, error) {
+	m := map[string]any{
+		"model": req.Model,
+		"input": req.Input,
+	}
+	if req.Dimensions != nil {
+		m["dimensions"] = *req.Dimensions
+	}
+	if req.User != "" {
+		m["user"] = req.User
+	}
+	return json.Marshal(m)
+}
+
+// ToGeminiEmbeddingRequest converts an embedding request to a Gemini batchEmbedContents body.
+func ToGeminiEmbeddingRequest(req *ir.UnifiedEmbeddingRequest) ([]byte, error) {
+	requests := make([]map[string]any, 0, len(req.Input))
+	for _, text := range req.Input {
+		r := map[string]any{
+			"model":   "models/" + req.Model,
+			"content": map[string]any{"parts": []any{map[string]any{"text": text}}},
+		}
+		if req.TaskType != "" {
+			r["taskType"] = req.TaskType
+		}
+		if req.Title != "" {
+			r["title"] = req.Title
+		}
+		if req.Dimensions != nil {
+			r["outputDimensionality"] = *req.Dimensions
+		}
+		requests = append(requests, r)
+	}
+	return json.Marshal(map[string]any{"requests": requests})
+}
+
+// ToVertexEmbeddingRequest converts an embedding request to a Vertex AI :predict body.
+func ToVertexEmbeddingRequest(req *ir.UnifiedEmbeddingRequest) ([]byte, error) {
+	instances := make([]map[string]any, 0, len(req.Input))
+	for _, text := range req.Input {
+		inst := map[string]any{"content": text}
+		if req.TaskType != "" {
+			inst["task_type"] = req.TaskType
+		}
+		if req.Title != "" {
+			inst["title"] = req.Title
+		}
+		instances = append(instances, inst)
+	}
+	m := map[string]any{"instances": instances}
+	if req.Dimensions != nil {
+		m["parameters"] = map[string]any{"outputDimensionality": *req.Dimensions}
+	}
+	return json.Marshal(m)
+}
+
+// ToOpenAIEmbeddingResponse converts an embedding response to OpenAI format.
+// encodingFormat "base64" packs each vector as little-endian float32.
+func ToOpenAIEmbeddingResponse(resp *ir.UnifiedEmbeddingResponse, model, encodingFormat string) ([]byte, error) {
+	data := make([]map[string]any, 0, len(resp.Embeddings))
+	for _, e := range resp.Embeddings {
+		var vec any = e.Values
+		if encodingFormat == "base64" {
+			vec = encodeBase64Float32(e.Values)
+		}
+		data = append(data, map[string]any{
+			"object":    "embedding",
+			"index":     e.Index,
+			"embedding": vec,
+		})
+	}
+	var prompt, total int64
+	if resp.Usage != nil {
+		prompt, total = resp.Usage.PromptTokens, resp.Usage.TotalTokens
+		if total == 0 {
+			total = prompt
+		}
+	}
+	return json.Marshal(map[string]any{
+		"object": "list",
+		"data":   data,
+		"model":  model,
+		"usage": map[string]any{
+			"prompt_tokens": prompt,
+			"total_tokens":  total,
+		},
+	})
+}
+
+// ToGeminiEmbeddingResponse converts an embedding response to Gemini format.
+// When batch is false the single embedContent shape is produced.
+func ToGeminiEmbeddingResponse(resp *ir.UnifiedEmbeddingResponse, batch bool) ([]byte, error) {
+	if !batch {
+		var values []float64
+		if len(resp.Embeddings) > 0 {
+			values = resp.Embeddings[0].Values
+		}
+		return json.Marshal(map[string]any{"embedding": map[string]any{"values": values}})
+	}
+	embeddings := make([]map[string]any, len(resp.Embeddings))
+	for i, e := range resp.Embeddings {
+		embeddings[i] = map[string]any{"values": e.Values}
+	}
+	return json.Marshal(map[string]any{"embeddings": embeddings})
+}
+
+func encodeBase64Float32(values []float64) string {
+	buf := make([]byte, len(values)*4)
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(v)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
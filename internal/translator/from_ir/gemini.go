@@ -2,6 +2,7 @@ package from_ir
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/tidwall/gjson"
@@ -80,6 +81,9 @@ func (p *GeminiProvider) applyGenerationConfig(root map[string]any, req *ir.Unif
 	if len(req.ResponseModality) > 0 {
 		gc["responseModalities"] = req.ResponseModality
 	}
+	if req.AudioConfig != nil && req.AudioConfig.Voice != "" && slices.Contains(req.ResponseModality, "AUDIO") {
+		gc["speechConfig"] = map[string]any{"voiceConfig": map[string]any{"prebuiltVoiceConfig": map[string]any{"voiceName": geminiVoice(req.AudioConfig.Voice)}}}
+	}
 	if req.ImageConfig != nil && req.ImageConfig.AspectRatio != "" && req.Model != "gemini-2.5-flash-image-preview" {
 		gc["imageConfig"] = map[string]any{"aspectRatio": req.ImageConfig.AspectRatio, "imageSize": req.ImageConfig.ImageSize}
 	}
@@ -327,6 +331,29 @@ func (p *GeminiProvider) fixImageAspectRatioForPreview(root map[string]any, aspe
 	}
 }
 
+// openAIVoices maps the OpenAI TTS voices onto Gemini prebuilt voices of a
+// similar character. Other names are passed through as Gemini voice names.
+var openAIVoices = map[string]string{
+	"alloy":   "Kore",
+	"ash":     "Orus",
+	"ballad":  "Enceladus",
+	"coral":   "Aoede",
+	"echo":    "Charon",
+	"fable":   "Puck",
+	"nova":    "Leda",
+	"onyx":    "Fenrir",
+	"sage":    "Sulafat",
+	"shimmer": "Zephyr",
+	"verse":   "Iapetus",
+}
+
+func geminiVoice(voice string) string {
+	if v, ok := openAIVoices[voice]; ok {
+		return v
+	}
+	return voice
+}
+
 func ToGeminiResponse(messages []ir.Message, usage *ir.Usage, model string) ([]byte, error) {
 	return ToGeminiResponseMeta(messages, usage, model, nil)
 }
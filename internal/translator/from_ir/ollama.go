@@ -423,26 +423,51 @@ func mapFinishReasonToOllama(r ir.FinishReason) string {
 	}
 }
 
-func ToOllamaShowResponse(mn string) []byte {
+// ToOllamaShowResponse builds the /api/show response for model mn from its
+// registry entry. It reports false when no provider offers the model.
+func ToOllamaShowResponse(mn string) ([]byte, bool) {
+	info := findModelInfoByName(mn)
+	if info == nil {
+		return nil, false
+	}
 	cl, mt, ar := 128000, 16384, "transformer"
-	if info := findModelInfoByName(mn); info != nil {
-		if info.Type != "" {
-			ar = info.Type
-		}
-		if info.ContextLength > 0 {
-			cl = info.ContextLength
-		} else if info.InputTokenLimit > 0 {
-			cl = info.InputTokenLimit
-		}
-		if info.MaxCompletionTokens > 0 {
-			mt = info.MaxCompletionTokens
-		} else if info.OutputTokenLimit > 0 {
-			mt = info.OutputTokenLimit
-		}
+	if info.Type != "" {
+		ar = info.Type
+	}
+	if info.ContextLength > 0 {
+		cl = info.ContextLength
+	} else if info.InputTokenLimit > 0 {
+		cl = info.InputTokenLimit
+	}
+	if info.MaxCompletionTokens > 0 {
+		mt = info.MaxCompletionTokens
+	} else if info.OutputTokenLimit > 0 {
+		mt = info.OutputTokenLimit
+	}
+	res := map[string]any{"license": "", "modelfile": "# Modelfile for " + mn + "\nFROM " + mn, "parameters": fmt.Sprintf("num_ctx %d\nnum_predict %d\ntemperature 0.7\ntop_p 0.9", cl, mt), "template": "{{ if .System }}{{ .System }}\n{{ end }}{{ .Prompt }}", "details": map[string]any{"parent_model": "", "format": "gguf", "family": ar, "families": []string{ar}, "parameter_size": "0B", "quantization_level": "Q4_K_M"}, "model_info": map[string]any{"general.architecture": ar, "general.basename": mn, "general.file_type": 2, "general.parameter_count": 0, "general.quantization_version": 2, "general.context_length": cl, "llama.context_length": cl, "llama.rope.freq_base": 10000.0, ar + ".context_length": cl}, "capabilities": ollamaCapabilities(info)}
+	if info.Created > 0 {
+		res["modified_at"] = time.Unix(info.Created, 0).UTC().Format(time.RFC3339)
 	}
-	res := map[string]any{"license": "", "modelfile": "# Modelfile for " + mn + "\nFROM " + mn, "parameters": fmt.Sprintf("num_ctx %d\nnum_predict %d\ntemperature 0.7\ntop_p 0.9", cl, mt), "template": "{{ if .System }}{{ .System }}\n{{ end }}{{ .Prompt }}", "details": map[string]any{"parent_model": "", "format": "gguf", "family": "Ollama", "families": []string{"Ollama"}, "parameter_size": "0B", "quantization_level": "Q4_K_M"}, "model_info": map[string]any{"general.architecture": ar, "general.basename": mn, "general.file_type": 2, "general.parameter_count": 0, "general.quantization_version": 2, "general.context_length": cl, "llama.context_length": cl, "llama.rope.freq_base": 10000.0, ar + ".context_length": cl}, "capabilities": []string{"tools", "vision", "completion"}}
 	jb, _ := json.Marshal(res)
-	return jb
+	return jb, true
+}
+
+// visionModelTypes are the model types whose models all accept images.
+var visionModelTypes = map[string]bool{"gemini": true, "claude": true, "codex": true, "github-copilot": true, "kiro": true, "antigravity": true}
+
+// ollamaCapabilities derives the Ollama capability list of a model.
+func ollamaCapabilities(info *registry.ModelInfo) []string {
+	if info.Type == registry.ModelTypeEmbedding {
+		return []string{"embedding"}
+	}
+	caps := []string{"completion", "tools"}
+	if visionModelTypes[info.Type] {
+		caps = append(caps, "vision")
+	}
+	if info.Thinking != nil {
+		caps = append(caps, "thinking")
+	}
+	return caps
 }
 
 func findModelInfoByName(mn string) *registry.ModelInfo {
@@ -457,6 +482,9 @@ func findModelInfoByName(mn string) *registry.ModelInfo {
 			cid = id[idx+2:]
 		}
 		if strings.EqualFold(cid, mn) {
+			if info := reg.GetModelInfo(cid); info != nil {
+				return info
+			}
 			info := &registry.ModelInfo{ID: cid}
 			if v, ok := m["type"].(string); ok {
 				info.Type = v
@@ -335,7 +335,7 @@ func buildResponsesUserMessage(msg ir.Message) any {
 					i["filename"] = p.File.Filename
 				}
 				if p.File.FileData != "" {
-					i["file_data"] = p.File.FileData
+					i["file_data"] = fileDataURI(p.File)
 				}
 				c = append(c, i)
 			}
@@ -396,6 +396,9 @@ func ToOpenAIChatCompletionCandidates(cs []ir.CandidateResult, us *ir.Usage, mod
 		if tcs != nil {
 			mc["tool_calls"] = tcs
 		}
+		if imgs := buildOpenAIImages(*m); imgs != nil {
+			mc["images"] = imgs
+		}
 		co := map[string]any{"index": c.Index, "finish_reason": ir.MapFinishReasonToOpenAI(c.FinishReason), "message": mc}
 		if c.Logprobs != nil {
 			co["logprobs"] = c.Logprobs
@@ -448,6 +451,9 @@ func ToOpenAIChatCompletionMeta(ms []ir.Message, us *ir.Usage, model, mid string
 		if tcs != nil {
 			mc["tool_calls"] = tcs
 		}
+		if imgs := buildOpenAIImages(*m); imgs != nil {
+			mc["images"] = imgs
+		}
 		if ap := findAudioContent(*m); ap != nil {
 			ao := map[string]any{}
 			if ap.ID != "" {
@@ -679,6 +685,14 @@ func convertMessageToOpenAI(msg ir.Message) map[string]any {
 	return res
 }
 
+// fileDataURI returns the inline data of f as the data URI OpenAI expects.
+func fileDataURI(f *ir.FilePart) string {
+	if strings.HasPrefix(f.FileData, "data:") || f.MimeType == "" {
+		return f.FileData
+	}
+	return "data:" + f.MimeType + ";base64," + f.FileData
+}
+
 func buildOpenAIUserMessage(msg ir.Message) map[string]any {
 	ps := make([]any, 0, len(msg.Content))
 	for i := range msg.Content {
@@ -692,6 +706,22 @@ func buildOpenAIUserMessage(msg ir.Message) map[string]any {
 			if p.Image != nil {
 				ps = append(ps, map[string]any{"type": "image_url", "image_url": map[string]string{"url": fmt.Sprintf("data:%s;base64,%s", p.Image.MimeType, p.Image.Data)}})
 			}
+		case ir.ContentTypeFile:
+			if p.File != nil {
+				f := map[string]any{}
+				if p.File.FileID != "" {
+					f["file_id"] = p.File.FileID
+				}
+				if p.File.FileData != "" {
+					f["file_data"] = fileDataURI(p.File)
+				}
+				if p.File.Filename != "" {
+					f["filename"] = p.File.Filename
+				}
+				if len(f) > 0 {
+					ps = append(ps, map[string]any{"type": "file", "file": f})
+				}
+			}
 		case ir.ContentTypeAudio:
 			if p.Audio != nil && p.Audio.Data != "" {
 				ia := map[string]any{"data": p.Audio.Data}
@@ -992,6 +1022,18 @@ func buildOpenAIGroundingMetadata(gm *ir.GroundingMetadata) map[string]any {
 	return res
 }
 
+// buildOpenAIImages returns the generated images of m as image_url parts, in
+// the same shape as the images field of streaming deltas.
+func buildOpenAIImages(m ir.Message) []any {
+	var imgs []any
+	for _, p := range m.Content {
+		if p.Type == ir.ContentTypeImage && p.Image != nil && p.Image.Data != "" {
+			imgs = append(imgs, map[string]any{"type": "image_url", "image_url": map[string]string{"url": fmt.Sprintf("data:%s;base64,%s", p.Image.MimeType, p.Image.Data)}})
+		}
+	}
+	return imgs
+}
+
 func findAudioContent(m ir.Message) *ir.AudioPart {
 	for _, p := range m.Content {
 		if p.Type == ir.ContentTypeAudio && p.Audio != nil {
@@ -46,6 +46,38 @@ func BuildImagePart(img *ir.ImagePart) map[string]any {
 	return nil
 }
 
+// BuildFilePart creates a document content part from IR.
+// Supports inline data (base64 or a data URI) and file references (files/, gs://).
+func BuildFilePart(file *ir.FilePart) map[string]any {
+	if file == nil {
+		return nil
+	}
+	if file.FileData != "" {
+		mimeType, data := file.MimeType, file.FileData
+		if rest, ok := strings.CutPrefix(data, "data:"); ok {
+			if meta, payload, found := strings.Cut(rest, ","); found {
+				mimeType, _, _ = strings.Cut(meta, ";")
+				data = payload
+			}
+		}
+		return map[string]any{
+			"inlineData": map[string]any{
+				"mimeType": mimeType,
+				"data":     data,
+			},
+		}
+	}
+	if u := file.FileURL; strings.HasPrefix(u, "files/") || strings.HasPrefix(u, "gs://") {
+		return map[string]any{
+			"fileData": map[string]any{
+				"mimeType": file.MimeType,
+				"fileUri":  u,
+			},
+		}
+	}
+	return nil
+}
+
 // BuildAudioPart creates an audio content part from IR.
 func BuildAudioPart(audio *ir.AudioPart) map[string]any {
 	if audio == nil {
@@ -137,6 +169,10 @@ func BuildUserParts(content []ir.ContentPart) []any {
 			if p := BuildImagePart(part.Image); p != nil {
 				parts = append(parts, p)
 			}
+		case ir.ContentTypeFile:
+			if p := BuildFilePart(part.File); p != nil {
+				parts = append(parts, p)
+			}
 		case ir.ContentTypeAudio:
 			if p := BuildAudioPart(part.Audio); p != nil {
 				parts = append(parts, p)
@@ -31,6 +31,7 @@ const (
 	MetaClaudeMetadata = "claude:metadata"
 
 	// Internal flags (prefixed with _ to indicate internal use)
+	MetaFileOwner            = "_file_owner"             // API key whose stored files the request may reference by id
 	MetaForceDisableThinking = "_force_disable_thinking" // Set by translator_wrapper for non-streaming Claude via Antigravity
 )
 
@@ -531,6 +532,31 @@ type UnifiedChatRequest struct {
 	StreamOptions *StreamOptionsConfig // Stream configuration options
 }
 
+// UnifiedEmbeddingRequest is the format-neutral representation of an embedding request.
+type UnifiedEmbeddingRequest struct {
+	Model          string
+	Input          []string       // One entry per text to embed, order is preserved in the response
+	Dimensions     *int           // Requested output dimensionality (OpenAI dimensions / Gemini outputDimensionality)
+	EncodingFormat string         // "float" (default) or "base64" (OpenAI only)
+	TaskType       string         // Gemini task type (e.g., "RETRIEVAL_QUERY", "SEMANTIC_SIMILARITY")
+	Title          string         // Gemini document title (RETRIEVAL_DOCUMENT only)
+	User           string         // End-user identifier (OpenAI)
+	Metadata       map[string]any // Additional provider-specific metadata
+}
+
+// Embedding is a single embedding vector tied to the index of its input.
+type Embedding struct {
+	Index  int
+	Values []float64
+}
+
+// UnifiedEmbeddingResponse is the format-neutral representation of an embedding response.
+type UnifiedEmbeddingResponse struct {
+	Model      string
+	Embeddings []Embedding
+	Usage      *Usage
+}
+
 // FunctionCallingConfig controls function calling behavior.
 type FunctionCallingConfig struct {
 	Mode                        string   // "AUTO", "ANY", "NONE"
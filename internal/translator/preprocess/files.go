@@ -0,0 +1,87 @@
+package preprocess
+
+import (
+	"encoding/base64"
+	"strings"
+	"sync/atomic"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// FileResolver loads a stored file that owner may reference by id. ok is false
+// when id is not such a file, e.g. because it is a provider-native file id.
+type FileResolver func(owner, id string) (data []byte, mimeType, filename string, ok bool)
+
+var fileResolver atomic.Pointer[FileResolver]
+
+// SetFileResolver registers the resolver used to inline file references.
+func SetFileResolver(r FileResolver) {
+	fileResolver.Store(&r)
+}
+
+// applyFileResolution inlines ImagePart.FileID and FilePart.FileID references
+// to files stored by the proxy, so they reach providers that cannot see the
+// proxy's file ids. The request owner comes from ir.MetaFileOwner; without it
+// references are left alone. A resolved file becomes an image or a document
+// part according to its MIME type, whichever way it was referenced.
+func applyFileResolution(req *ir.UnifiedChatRequest) {
+	r := fileResolver.Load()
+	if r == nil || *r == nil {
+		return
+	}
+	owner, ok := req.Metadata[ir.MetaFileOwner].(string)
+	if !ok {
+		return
+	}
+	resolve := *r
+	for i := range req.Messages {
+		for j := range req.Messages[i].Content {
+			part := &req.Messages[i].Content[j]
+			switch {
+			case part.Type == ir.ContentTypeImage && part.Image != nil && part.Image.FileID != "":
+				resolvePart(resolve, owner, part.Image.FileID, part)
+			case part.Type == ir.ContentTypeFile && part.File != nil && part.File.FileID != "":
+				resolvePart(resolve, owner, part.File.FileID, part)
+			case part.Type == ir.ContentTypeToolResult && part.ToolResult != nil:
+				for _, img := range part.ToolResult.Images {
+					if img.FileID != "" {
+						if data, mimeType, _, ok := resolve(owner, img.FileID); ok {
+							*img = ir.ImagePart{MimeType: mimeType, Data: base64.StdEncoding.EncodeToString(data), Detail: img.Detail}
+						}
+					}
+				}
+				for _, f := range part.ToolResult.Files {
+					if f.FileID != "" {
+						if data, mimeType, filename, ok := resolve(owner, f.FileID); ok {
+							*f = ir.FilePart{Filename: filename, MimeType: mimeType, FileData: base64.StdEncoding.EncodeToString(data)}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func resolvePart(resolve FileResolver, owner, id string, part *ir.ContentPart) {
+	data, mimeType, filename, ok := resolve(owner, id)
+	if !ok {
+		return
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	if strings.HasPrefix(mimeType, "image/") {
+		detail := ""
+		if part.Image != nil {
+			detail = part.Image.Detail
+		}
+		part.Type = ir.ContentTypeImage
+		part.Image = &ir.ImagePart{MimeType: mimeType, Data: encoded, Detail: detail}
+		part.File = nil
+		return
+	}
+	if part.File != nil && part.File.Filename != "" {
+		filename = part.File.Filename
+	}
+	part.Type = ir.ContentTypeFile
+	part.File = &ir.FilePart{Filename: filename, MimeType: mimeType, FileData: encoded}
+	part.Image = nil
+}
@@ -0,0 +1,64 @@
+package preprocess
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestApplyFileResolution(t *testing.T) {
+	stored := map[string]struct{ data, mime, name string }{
+		"file-img": {"png-bytes", "image/png", "cat.png"},
+		"file-pdf": {"pdf-bytes", "application/pdf", "doc.pdf"},
+	}
+	SetFileResolver(func(owner, id string) ([]byte, string, string, bool) {
+		f, ok := stored[id]
+		if !ok || owner != "key-a" {
+			return nil, "", "", false
+		}
+		return []byte(f.data), f.mime, f.name, true
+	})
+	defer SetFileResolver(nil)
+
+	newReq := func(owner any) *ir.UnifiedChatRequest {
+		req := &ir.UnifiedChatRequest{
+			Metadata: map[string]any{},
+			Messages: []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{
+				{Type: ir.ContentTypeImage, Image: &ir.ImagePart{FileID: "file-img", Detail: "high"}},
+				{Type: ir.ContentTypeFile, File: &ir.FilePart{FileID: "file-pdf"}},
+				{Type: ir.ContentTypeFile, File: &ir.FilePart{FileID: "file-img"}},
+				{Type: ir.ContentTypeFile, File: &ir.FilePart{FileID: "file_011native"}},
+			}}},
+		}
+		if owner != nil {
+			req.Metadata[ir.MetaFileOwner] = owner
+		}
+		return req
+	}
+
+	req := newReq("key-a")
+	applyFileResolution(req)
+	parts := req.Messages[0].Content
+	if img := parts[0].Image; img == nil || img.FileID != "" || img.MimeType != "image/png" || img.Detail != "high" ||
+		img.Data != base64.StdEncoding.EncodeToString([]byte("png-bytes")) {
+		t.Errorf("image part = %+v", parts[0].Image)
+	}
+	if f := parts[1].File; parts[1].Type != ir.ContentTypeFile || f.FileID != "" || f.MimeType != "application/pdf" || f.Filename != "doc.pdf" {
+		t.Errorf("file part = %+v", parts[1].File)
+	}
+	if parts[2].Type != ir.ContentTypeImage || parts[2].Image == nil || parts[2].File != nil {
+		t.Errorf("image referenced as a file was not turned into an image part: %+v", parts[2])
+	}
+	if f := parts[3].File; f.FileID != "file_011native" || f.FileData != "" {
+		t.Errorf("unknown file id was changed: %+v", f)
+	}
+
+	for _, owner := range []any{nil, "key-b"} {
+		req := newReq(owner)
+		applyFileResolution(req)
+		if req.Messages[0].Content[0].Image.FileID != "file-img" {
+			t.Errorf("owner %v: reference was resolved", owner)
+		}
+	}
+}
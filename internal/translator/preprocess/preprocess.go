@@ -16,6 +16,7 @@ func Apply(req *ir.UnifiedChatRequest) error {
 
 	info := registry.GetGlobalRegistry().GetModelInfo(req.Model)
 
+	applyFileResolution(req)
 	applyThinkingNormalization(req, info)
 	applyLimits(req, info)
 	applyProviderDefaults(req, info)
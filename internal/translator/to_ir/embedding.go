@@ -0,0 +1,194 @@
+package to_ir
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ErrTokenEmbeddingInput is returned when an OpenAI embedding request carries
+// pre-tokenized input, which cannot be translated to other providers.
+var ErrTokenEmbeddingInput = errors.New("embedding input must be a string or an array of strings")
+
+// ParseOpenAIEmbeddingRequest parses an OpenAI /v1/embeddings request body.
+func ParseOpenAIEmbeddingRequest(rawJSON []byte) (*ir.UnifiedEmbeddingRequest, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &ir.UnifiedEmbeddingRequest{
+		Model:          root.Get("model").String(),
+		EncodingFormat: root.Get("encoding_format").String(),
+		User:           root.Get("user").String(),
+	}
+	if v := root.Get("dimensions"); v.Exists() && v.Int() > 0 {
+		d := int(v.Int())
+		req.Dimensions = &d
+	}
+
+	input := root.Get("input")
+	switch {
+	case input.Type == gjson.String:
+		req.Input = []string{input.String()}
+	case input.IsArray():
+		for _, item := range input.Array() {
+			if item.Type != gjson.String {
+				return nil, ErrTokenEmbeddingInput
+			}
+			req.Input = append(req.Input, item.String())
+		}
+	}
+	if len(req.Input) == 0 {
+		return nil, errors.New("embedding input is required")
+	}
+	return req, nil
+}
+
+// ParseGeminiEmbeddingRequest parses a Gemini embedContent or batchEmbedContents
+// request body. Task type, title and dimensionality are taken from the first
+// request of a batch since the IR carries them once per call.
+func ParseGeminiEmbeddingRequest(rawJSON []byte) (*ir.UnifiedEmbeddingRequest, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := root.Get("requests").Array()
+	if len(requests) == 0 {
+		requests = []gjson.Result{root}
+	}
+
+	req := &ir.UnifiedEmbeddingRequest{
+		Model: strings.TrimPrefix(requests[0].Get("model").String(), "models/"),
+	}
+	first := requests[0]
+	req.TaskType = first.Get("taskType").String()
+	req.Title = first.Get("title").String()
+	if v := first.Get("outputDimensionality"); v.Exists() && v.Int() > 0 {
+		d := int(v.Int())
+		req.Dimensions = &d
+	}
+
+	for _, r := range requests {
+		var sb strings.Builder
+		for _, part := range r.Get("content.parts").Array() {
+			sb.WriteString(part.Get("text").String())
+		}
+		req.Input = append(req.Input, sb.String())
+	}
+	if len(req.Input) == 0 {
+		return nil, errors.New("embedding content is required")
+	}
+	return req, nil
+}
+
+// ParseOpenAIEmbeddingResponse parses an OpenAI embeddings response.
+// Both float arrays and base64-encoded float32 vectors are accepted.
+func ParseOpenAIEmbeddingResponse(rawJSON []byte) (*ir.UnifiedEmbeddingResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ir.UnifiedEmbeddingResponse{Model: root.Get("model").String()}
+	for i, item := range root.Get("data").Array() {
+		idx := i
+		if v := item.Get("index"); v.Exists() {
+			idx = int(v.Int())
+		}
+		emb := item.Get("embedding")
+		var values []float64
+		if emb.Type == gjson.String {
+			values, err = decodeBase64Float32(emb.String())
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			values = parseFloatArray(emb)
+		}
+		resp.Embeddings = append(resp.Embeddings, ir.Embedding{Index: idx, Values: values})
+	}
+
+	if u := root.Get("usage"); u.Exists() {
+		resp.Usage = &ir.Usage{
+			PromptTokens: u.Get("prompt_tokens").Int(),
+			TotalTokens:  u.Get("total_tokens").Int(),
+		}
+		if resp.Usage.TotalTokens == 0 {
+			resp.Usage.TotalTokens = resp.Usage.PromptTokens
+		}
+	}
+	return resp, nil
+}
+
+// ParseGeminiEmbeddingResponse parses a Gemini embedContent or batchEmbedContents response.
+func ParseGeminiEmbeddingResponse(rawJSON []byte) (*ir.UnifiedEmbeddingResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ir.UnifiedEmbeddingResponse{}
+	if single := root.Get("embedding"); single.Exists() {
+		resp.Embeddings = []ir.Embedding{{Index: 0, Values: parseFloatArray(single.Get("values"))}}
+	}
+	for i, item := range root.Get("embeddings").Array() {
+		resp.Embeddings = append(resp.Embeddings, ir.Embedding{Index: i, Values: parseFloatArray(item.Get("values"))})
+	}
+	if u := root.Get("usageMetadata"); u.Exists() {
+		prompt := u.Get("promptTokenCount").Int()
+		resp.Usage = &ir.Usage{PromptTokens: prompt, TotalTokens: prompt}
+	}
+	return resp, nil
+}
+
+// ParseVertexEmbeddingResponse parses a Vertex AI :predict response for text embedding models.
+func ParseVertexEmbeddingResponse(rawJSON []byte) (*ir.UnifiedEmbeddingResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ir.UnifiedEmbeddingResponse{}
+	var tokens int64
+	for i, p := range root.Get("predictions").Array() {
+		emb := p.Get("embeddings")
+		resp.Embeddings = append(resp.Embeddings, ir.Embedding{Index: i, Values: parseFloatArray(emb.Get("values"))})
+		tokens += emb.Get("statistics.token_count").Int()
+	}
+	if tokens > 0 {
+		resp.Usage = &ir.Usage{PromptTokens: tokens, TotalTokens: tokens}
+	}
+	return resp, nil
+}
+
+func parseFloatArray(v gjson.Result) []float64 {
+	arr := v.Array()
+	values := make([]float64, len(arr))
+	for i, f := range arr {
+		values[i] = f.Float()
+	}
+	return values
+}
+
+func decodeBase64Float32(s string) ([]float64, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw)%4 != 0 {
+		return nil, errors.New("invalid base64 embedding length")
+	}
+	values := make([]float64, len(raw)/4)
+	for i := range values {
+		values[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:])))
+	}
+	return values, nil
+}
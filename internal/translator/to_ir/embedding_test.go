@@ -0,0 +1,109 @@
+package to_ir
+
+import (
+	"errors"
+	"testing"
+)
+
+// ==================== ParseOpenAIEmbeddingRequest Tests ====================
+
+func TestParseOpenAIEmbeddingRequest_String(t *testing.T) {
+	req, err := ParseOpenAIEmbeddingRequest([]byte(`{"model":"text-embedding-3-small","input":"hello","dimensions":256}`))
+	if err != nil {
+		t.Fatalf("ParseOpenAIEmbeddingRequest failed: %v", err)
+	}
+	if len(req.Input) != 1 || req.Input[0] != "hello" {
+		t.Errorf("Input = %v, want [hello]", req.Input)
+	}
+	if req.Dimensions == nil || *req.Dimensions != 256 {
+		t.Errorf("Dimensions = %v, want 256", req.Dimensions)
+	}
+}
+
+func TestParseOpenAIEmbeddingRequest_Array(t *testing.T) {
+	req, err := ParseOpenAIEmbeddingRequest([]byte(`{"model":"m","input":["a","b"],"encoding_format":"base64"}`))
+	if err != nil {
+		t.Fatalf("ParseOpenAIEmbeddingRequest failed: %v", err)
+	}
+	if len(req.Input) != 2 || req.Input[1] != "b" {
+		t.Errorf("Input = %v, want [a b]", req.Input)
+	}
+	if req.EncodingFormat != "base64" {
+		t.Errorf("EncodingFormat = %q, want base64", req.EncodingFormat)
+	}
+}
+
+func TestParseOpenAIEmbeddingRequest_TokenInputRejected(t *testing.T) {
+	_, err := ParseOpenAIEmbeddingRequest([]byte(`{"model":"m","input":[[1,2,3]]}`))
+	if !errors.Is(err, ErrTokenEmbeddingInput) {
+		t.Errorf("err = %v, want ErrTokenEmbeddingInput", err)
+	}
+}
+
+// ==================== Gemini Embedding Tests ====================
+
+func TestParseGeminiEmbeddingRequest_Batch(t *testing.T) {
+	input := `{"requests":[
+		{"model":"models/gemini-embedding-001","content":{"parts":[{"text":"one"}]},"taskType":"RETRIEVAL_QUERY","outputDimensionality":768},
+		{"model":"models/gemini-embedding-001","content":{"parts":[{"text":"two"}]}}
+	]}`
+	req, err := ParseGeminiEmbeddingRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseGeminiEmbeddingRequest failed: %v", err)
+	}
+	if req.Model != "gemini-embedding-001" {
+		t.Errorf("Model = %q, want gemini-embedding-001", req.Model)
+	}
+	if len(req.Input) != 2 || req.Input[0] != "one" || req.Input[1] != "two" {
+		t.Errorf("Input = %v, want [one two]", req.Input)
+	}
+	if req.TaskType != "RETRIEVAL_QUERY" {
+		t.Errorf("TaskType = %q, want RETRIEVAL_QUERY", req.TaskType)
+	}
+	if req.Dimensions == nil || *req.Dimensions != 768 {
+		t.Errorf("Dimensions = %v, want 768", req.Dimensions)
+	}
+}
+
+func TestParseGeminiEmbeddingResponse_Single(t *testing.T) {
+	resp, err := ParseGeminiEmbeddingResponse([]byte(`{"embedding":{"values":[0.5,-0.25]}}`))
+	if err != nil {
+		t.Fatalf("ParseGeminiEmbeddingResponse failed: %v", err)
+	}
+	if len(resp.Embeddings) != 1 || len(resp.Embeddings[0].Values) != 2 || resp.Embeddings[0].Values[1] != -0.25 {
+		t.Errorf("Embeddings = %+v, want one vector [0.5 -0.25]", resp.Embeddings)
+	}
+}
+
+func TestParseVertexEmbeddingResponse_Usage(t *testing.T) {
+	input := `{"predictions":[
+		{"embeddings":{"values":[1,2],"statistics":{"token_count":3}}},
+		{"embeddings":{"values":[3,4],"statistics":{"token_count":4}}}
+	]}`
+	resp, err := ParseVertexEmbeddingResponse([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseVertexEmbeddingResponse failed: %v", err)
+	}
+	if len(resp.Embeddings) != 2 || resp.Embeddings[1].Index != 1 {
+		t.Errorf("Embeddings = %+v, want 2 indexed vectors", resp.Embeddings)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 7 {
+		t.Errorf("Usage = %+v, want PromptTokens 7", resp.Usage)
+	}
+}
+
+func TestParseOpenAIEmbeddingResponse_Base64(t *testing.T) {
+	// 1.0 and -2.0 as little-endian float32
+	input := `{"data":[{"index":0,"embedding":"AACAPwAAAMA="}],"usage":{"prompt_tokens":2,"total_tokens":2}}`
+	resp, err := ParseOpenAIEmbeddingResponse([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIEmbeddingResponse failed: %v", err)
+	}
+	values := resp.Embeddings[0].Values
+	if len(values) != 2 || values[0] != 1 || values[1] != -2 {
+		t.Errorf("Values = %v, want [1 -2]", values)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 2 {
+		t.Errorf("Usage = %+v, want TotalTokens 2", resp.Usage)
+	}
+}
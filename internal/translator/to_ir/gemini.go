@@ -405,7 +405,10 @@ func parseGeminiCandidate(candidate gjson.Result, schemaCtx *ir.ToolSchemaContex
 				},
 				ThoughtSignature: ts,
 			})
-		} else if img := parseGeminiInlineImage(part); img != nil {
+		} else if img := parseGeminiInlineImage(part); img != nil && strings.HasPrefix(img.MimeType, "audio/") {
+			// Speech output of TTS models.
+			msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeAudio, Audio: &ir.AudioPart{MimeType: img.MimeType, Data: img.Data}})
+		} else if img != nil {
 			msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeImage, Image: img, ThoughtSignature: ts})
 		} else if len(ts) > 0 {
 			msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeReasoning, Reasoning: "", ThoughtSignature: ts})
@@ -804,15 +807,26 @@ func parseGeminiMeta(parsed gjson.Result) *ir.OpenAIMeta {
 	return meta
 }
 
+// ParseGeminiUsage extracts usageMetadata from a Gemini response or Live API
+// server message, returning nil when the message carries none.
+func ParseGeminiUsage(data []byte) *ir.Usage {
+	return parseGeminiUsage(gjson.ParseBytes(data))
+}
+
 func parseGeminiUsage(parsed gjson.Result) *ir.Usage {
 	u := parsed.Get("usageMetadata")
 	if !u.Exists() {
 		return nil
 	}
 	thoughtsTokens := int32(u.Get("thoughtsTokenCount").Int())
+	completion := u.Get("candidatesTokenCount")
+	if !completion.Exists() {
+		// Live API messages report output as responseTokenCount.
+		completion = u.Get("responseTokenCount")
+	}
 	usage := &ir.Usage{
 		PromptTokens:       u.Get("promptTokenCount").Int(),
-		CompletionTokens:   u.Get("candidatesTokenCount").Int(),
+		CompletionTokens:   completion.Int(),
 		TotalTokens:        u.Get("totalTokenCount").Int(),
 		ThoughtsTokenCount: thoughtsTokens,
 	}
@@ -239,7 +239,7 @@ func parseResponsesContentPart(p gjson.Result) *ir.ContentPart {
 			return &ir.ContentPart{Type: ir.ContentTypeImage, Image: &ir.ImagePart{URL: v}}
 		}
 		if v := p.Get("file_id").String(); v != "" {
-			return &ir.ContentPart{Type: ir.ContentTypeImage, Image: &ir.ImagePart{Data: v}}
+			return &ir.ContentPart{Type: ir.ContentTypeImage, Image: &ir.ImagePart{FileID: v}}
 		}
 	case "input_file":
 		fp := &ir.FilePart{FileID: p.Get("file_id").String(), FileURL: p.Get("file_url").String(), Filename: p.Get("filename").String(), FileData: p.Get("file_data").String()}
@@ -516,7 +516,8 @@ func parseOpenAIContentPart(item gjson.Result, msg *ir.Message) *ir.ContentPart
 		}
 	case "input_audio":
 		if v := item.Get("input_audio"); v.Exists() {
-			return &ir.ContentPart{Type: ir.ContentTypeAudio, Audio: &ir.AudioPart{Data: v.Get("data").String(), Format: v.Get("format").String()}}
+			f := v.Get("format").String()
+			return &ir.ContentPart{Type: ir.ContentTypeAudio, Audio: &ir.AudioPart{Data: v.Get("data").String(), Format: f, MimeType: audioMimeType(f)}}
 		}
 	case "file":
 		fn, fd, fid, fu := item.Get("file.filename").String(), item.Get("file.file_data").String(), item.Get("file.file_id").String(), item.Get("file.url").String()
@@ -607,6 +608,21 @@ func parseThinkingConfig(root gjson.Result) *ir.ThinkingConfig {
 	return tc
 }
 
+// audioMimeType maps an OpenAI input_audio format onto its MIME type.
+func audioMimeType(format string) string {
+	switch format {
+	case "":
+		return "audio/wav"
+	case "mp3":
+		return "audio/mpeg"
+	case "pcm16":
+		return "audio/pcm"
+	case "m4a":
+		return "audio/mp4"
+	}
+	return "audio/" + format
+}
+
 func parseDataURI(url string) *ir.ImagePart {
 	if !strings.HasPrefix(url, "data:") {
 		return nil
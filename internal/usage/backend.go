@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/json"
 )
 
 // Backend defines the persistence contract for usage records.
@@ -37,6 +38,10 @@ type Backend interface {
 	// QueryModelStats returns per-model statistics since the given time.
 	QueryModelStats(ctx context.Context, since time.Time) ([]ModelStats, error)
 
+	// QueryAPIKeyTokens returns the total tokens used by a client API key since
+	// the given time.
+	QueryAPIKeyTokens(ctx context.Context, apiKey string, since time.Time) (int64, error)
+
 	// Cleanup removes records older than the given time.
 	Cleanup(ctx context.Context, before time.Time) (int64, error)
 
@@ -81,3 +86,16 @@ func NewBackend(cfg BackendConfig) (Backend, error) {
 		return nil, fmt.Errorf("unknown backend type: %q", parsed.Backend)
 	}
 }
+
+// encodeMetadata returns the stored form of a record's metadata: a JSON object,
+// or "" when there is none.
+func encodeMetadata(metadata map[string]string) string {
+	if len(metadata) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,44 @@
+package usage
+
+import "context"
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a context that attributes usage recorded under it to apiKey.
+// Requests that do not run inside a gin handler, such as batch lines, use it to
+// keep per-key accounting.
+func WithAPIKey(ctx context.Context, apiKey string) context.Context {
+	if apiKey == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyContextKey{}, apiKey)
+}
+
+// APIKeyFromContext returns the API key set by WithAPIKey, or "".
+func APIKeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	apiKey, _ := ctx.Value(apiKeyContextKey{}).(string)
+	return apiKey
+}
+
+type metadataContextKey struct{}
+
+// WithMetadata returns a context whose usage records carry metadata, such as
+// the tags of the virtual key that made the request.
+func WithMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	if len(metadata) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, metadataContextKey{}, metadata)
+}
+
+// MetadataFromContext returns the metadata set by WithMetadata, or nil.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	metadata, _ := ctx.Value(metadataContextKey{}).(map[string]string)
+	return metadata
+}
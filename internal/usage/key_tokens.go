@@ -0,0 +1,159 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// keyTokenSyncInterval is how often the totals of a key are re-read from the
+// backend, which also picks up records written by other instances sharing it.
+const keyTokenSyncInterval = time.Minute
+
+var defaultKeyTokens = NewKeyTokens()
+
+func init() {
+	RegisterPlugin(defaultKeyTokens)
+}
+
+// DefaultKeyTokens returns the shared per-key token tracker.
+func DefaultKeyTokens() *KeyTokens { return defaultKeyTokens }
+
+// TokenTotals is the token usage of one client API key in the current UTC day
+// and calendar month.
+type TokenTotals struct {
+	Day   int64
+	Month int64
+}
+
+// KeyTokens tracks the tokens used by client API keys. A key's totals are
+// loaded from the usage backend when it is first looked up, so budgets
+// survive restarts, and kept current from the records published here.
+type KeyTokens struct {
+	mu   sync.Mutex
+	keys map[string]*keyTokenTotals
+}
+
+type keyTokenTotals struct {
+	dayStart, monthStart time.Time
+	day, month           int64
+	syncedAt             time.Time
+	syncing              bool
+	// sinceSync holds tokens recorded while a sync was running.
+	sinceSyncDay, sinceSyncMonth int64
+}
+
+// NewKeyTokens returns an empty tracker.
+func NewKeyTokens() *KeyTokens {
+	return &KeyTokens{keys: make(map[string]*keyTokenTotals)}
+}
+
+// HandleUsage implements Plugin. Only keys that have been looked up are
+// tracked.
+func (k *KeyTokens) HandleUsage(ctx context.Context, record Record) {
+	apiKey := record.APIKey
+	if apiKey == "" {
+		apiKey = APIKeyFromContext(ctx)
+	}
+	if apiKey == "" || record.Usage == nil {
+		return
+	}
+	tokens := normaliseUsage(record.Usage).TotalTokens
+	if tokens <= 0 {
+		return
+	}
+	at := record.RequestedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	t := k.keys[apiKey]
+	if t == nil {
+		return
+	}
+	t.roll(at.UTC())
+	t.day += tokens
+	t.month += tokens
+	if t.syncing {
+		t.sinceSyncDay += tokens
+		t.sinceSyncMonth += tokens
+	}
+}
+
+// Totals returns the tokens apiKey used in the current day and month. With a
+// usage backend, the totals are refreshed from it when older than a minute.
+func (k *KeyTokens) Totals(ctx context.Context, apiKey string) TokenTotals {
+	now := time.Now().UTC()
+	backend := activeBackend
+	k.mu.Lock()
+	t := k.keys[apiKey]
+	if t == nil {
+		t = &keyTokenTotals{}
+		k.keys[apiKey] = t
+	}
+	t.roll(now)
+	totals := TokenTotals{Day: t.day, Month: t.month}
+	refresh := backend != nil && !t.syncing && now.Sub(t.syncedAt) >= keyTokenSyncInterval
+	if refresh {
+		t.syncing = true
+		t.sinceSyncDay, t.sinceSyncMonth = 0, 0
+	}
+	dayStart, monthStart := t.dayStart, t.monthStart
+	k.mu.Unlock()
+
+	if !refresh {
+		return totals
+	}
+
+	day, month, err := queryKeyTokens(ctx, backend, apiKey, dayStart, monthStart)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	t.syncing = false
+	t.syncedAt = now
+	if err != nil {
+		log.Debugf("usage: failed to load token totals for api key: %v", err)
+		return TokenTotals{Day: t.day, Month: t.month}
+	}
+	// A period may have rolled over while the backend was queried.
+	if t.dayStart.Equal(dayStart) {
+		t.day = day + t.sinceSyncDay
+	}
+	if t.monthStart.Equal(monthStart) {
+		t.month = month + t.sinceSyncMonth
+	}
+	return TokenTotals{Day: t.day, Month: t.month}
+}
+
+// roll resets the totals whose period ended before now.
+func (t *keyTokenTotals) roll(now time.Time) {
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if dayStart.After(t.dayStart) {
+		t.dayStart, t.day, t.sinceSyncDay = dayStart, 0, 0
+	}
+	if monthStart.After(t.monthStart) {
+		t.monthStart, t.month, t.sinceSyncMonth = monthStart, 0, 0
+	}
+}
+
+// queryKeyTokens reads the day and month totals of apiKey from backend after
+// writing out its pending records.
+func queryKeyTokens(ctx context.Context, backend Backend, apiKey string, dayStart, monthStart time.Time) (day, month int64, err error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+	if err = backend.Flush(ctx); err != nil {
+		return 0, 0, err
+	}
+	if month, err = backend.QueryAPIKeyTokens(ctx, apiKey, monthStart); err != nil {
+		return 0, 0, err
+	}
+	if day, err = backend.QueryAPIKeyTokens(ctx, apiKey, dayStart); err != nil {
+		return 0, 0, err
+	}
+	return day, month, nil
+}
@@ -54,6 +54,9 @@ func (p *LoggerPlugin) HandleUsage(ctx context.Context, record Record) {
 	}
 	tokens := normaliseUsage(record.Usage)
 	statsKey := record.APIKey
+	if statsKey == "" {
+		statsKey = APIKeyFromContext(ctx)
+	}
 	if statsKey == "" {
 		statsKey = resolveAPIIdentifier(ctx, record)
 	}
@@ -82,6 +85,7 @@ func (p *LoggerPlugin) HandleUsage(ctx context.Context, record Record) {
 			Source:                   record.Source,
 			RequestedAt:              timestamp,
 			Failed:                   failed,
+			HedgeCancelled:           record.HedgeCancelled,
 			InputTokens:              tokens.PromptTokens,
 			OutputTokens:             tokens.CompletionTokens,
 			ReasoningTokens:          tokens.ReasoningTokens,
@@ -91,6 +95,7 @@ func (p *LoggerPlugin) HandleUsage(ctx context.Context, record Record) {
 			CacheCreationInputTokens: tokens.CacheCreationInputTokens,
 			CacheReadInputTokens:     tokens.CacheReadInputTokens,
 			ToolUsePromptTokens:      tokens.ToolUsePromptTokens,
+			Metadata:                 MetadataFromContext(ctx),
 		})
 	}
 }
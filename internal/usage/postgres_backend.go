@@ -109,9 +109,14 @@ func ensurePostgresSchema(ctx context.Context, pool *pgxpool.Pool) error {
 		cache_creation_input_tokens BIGINT NOT NULL DEFAULT 0,
 		cache_read_input_tokens BIGINT NOT NULL DEFAULT 0,
 		tool_use_prompt_tokens BIGINT NOT NULL DEFAULT 0,
+		metadata TEXT NOT NULL DEFAULT '',
+		hedge_cancelled BOOLEAN NOT NULL DEFAULT FALSE,
 		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 	);
 
+	ALTER TABLE usage_records ADD COLUMN IF NOT EXISTS metadata TEXT NOT NULL DEFAULT '';
+	ALTER TABLE usage_records ADD COLUMN IF NOT EXISTS hedge_cancelled BOOLEAN NOT NULL DEFAULT FALSE;
+
 	CREATE INDEX IF NOT EXISTS idx_usage_requested_at ON usage_records(requested_at);
 	CREATE INDEX IF NOT EXISTS idx_usage_api_key ON usage_records(api_key);
 	CREATE INDEX IF NOT EXISTS idx_usage_provider_model ON usage_records(provider, model);
@@ -386,6 +391,20 @@ func (b *PostgresBackend) QueryModelStats(ctx context.Context, since time.Time)
 	return results, rows.Err()
 }
 
+// QueryAPIKeyTokens returns the total tokens used by apiKey since the given time.
+func (b *PostgresBackend) QueryAPIKeyTokens(ctx context.Context, apiKey string, since time.Time) (int64, error) {
+	var total int64
+	err := b.pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(total_tokens), 0)
+		FROM usage_records
+		WHERE api_key = $1 AND requested_at >= $2
+	`, apiKey, since).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query api key tokens: %w", err)
+	}
+	return total, nil
+}
+
 // Cleanup removes records older than the given time.
 func (b *PostgresBackend) Cleanup(ctx context.Context, before time.Time) (int64, error) {
 	result, err := b.pool.Exec(ctx, `
@@ -453,7 +472,7 @@ func (b *PostgresBackend) writeBatch(ctx context.Context, records []UsageRecord)
 		"requested_at", "failed", "input_tokens", "output_tokens",
 		"reasoning_tokens", "cached_tokens", "total_tokens",
 		"audio_tokens", "cache_creation_input_tokens", "cache_read_input_tokens",
-		"tool_use_prompt_tokens",
+		"tool_use_prompt_tokens", "metadata", "hedge_cancelled",
 	}
 
 	_, err := b.pool.CopyFrom(
@@ -480,6 +499,8 @@ func (b *PostgresBackend) writeBatch(ctx context.Context, records []UsageRecord)
 				r.CacheCreationInputTokens,
 				r.CacheReadInputTokens,
 				r.ToolUsePromptTokens,
+				encodeMetadata(r.Metadata),
+				r.HedgeCancelled,
 			}, nil
 		}),
 	)
@@ -4,14 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	log "github.com/nghyane/llm-mux/internal/logging"
-	_ "modernc.org/sqlite"
+	"github.com/nghyane/llm-mux/internal/sqlstore"
 )
 
 // SQLiteBackend implements the Backend interface using SQLite.
@@ -58,6 +56,8 @@ func initSchema(db *sql.DB) error {
 		cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0,
 		cache_read_input_tokens INTEGER NOT NULL DEFAULT 0,
 		tool_use_prompt_tokens INTEGER NOT NULL DEFAULT 0,
+		metadata TEXT NOT NULL DEFAULT '',
+		hedge_cancelled BOOLEAN NOT NULL DEFAULT 0,
 		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
 
@@ -79,6 +79,8 @@ func migrateSchema(db *sql.DB) error {
 		"cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0",
 		"cache_read_input_tokens INTEGER NOT NULL DEFAULT 0",
 		"tool_use_prompt_tokens INTEGER NOT NULL DEFAULT 0",
+		"metadata TEXT NOT NULL DEFAULT ''",
+		"hedge_cancelled BOOLEAN NOT NULL DEFAULT 0",
 	}
 
 	for _, colDef := range migrations {
@@ -103,35 +105,16 @@ func NewSQLiteBackend(dbPath string, cfg BackendConfig) (*SQLiteBackend, error)
 		return nil, fmt.Errorf("SQLite path is required")
 	}
 
-	// Expand ~ to home directory
-	if strings.HasPrefix(dbPath, "~") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		dbPath = filepath.Join(home, dbPath[1:])
-	}
-
-	// Ensure parent directory exists
-	dbDir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create database directory: %w", err)
-	}
-
-	// Open database with WAL mode
-	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=-64000")
+	// The handle is shared with the other stores that live in the same file
+	// and is closed by sqlstore.CloseAll, not by Stop.
+	handle, err := sqlstore.OpenSQLite(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
-
-	// Set connection pool settings for SQLite
-	db.SetMaxOpenConns(1) // SQLite works best with single connection
-	db.SetMaxIdleConns(1)
-	db.SetConnMaxLifetime(0)
+	db := handle.DB
 
 	// Initialize schema
 	if err := initSchema(db); err != nil {
-		_ = db.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
 
@@ -177,7 +160,6 @@ func (b *SQLiteBackend) Stop() error {
 		return nil
 	}
 
-	var err error
 	b.stopOnce.Do(func() {
 		// Signal stop to all goroutines
 		close(b.stopChan)
@@ -188,14 +170,9 @@ func (b *SQLiteBackend) Stop() error {
 
 		// Wait for workers to finish
 		b.wg.Wait()
-
-		// Close database
-		if b.db != nil {
-			err = b.db.Close()
-		}
 	})
 
-	return err
+	return nil
 }
 
 // Enqueue adds a usage record to the write queue.
@@ -435,6 +412,20 @@ func (b *SQLiteBackend) QueryModelStats(ctx context.Context, since time.Time) ([
 	return results, rows.Err()
 }
 
+// QueryAPIKeyTokens returns the total tokens used by apiKey since the given time.
+func (b *SQLiteBackend) QueryAPIKeyTokens(ctx context.Context, apiKey string, since time.Time) (int64, error) {
+	var total int64
+	err := b.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(total_tokens), 0)
+		FROM usage_records
+		WHERE api_key = ? AND requested_at >= ?
+	`, apiKey, since).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query api key tokens: %w", err)
+	}
+	return total, nil
+}
+
 // Cleanup removes records older than the given time.
 func (b *SQLiteBackend) Cleanup(ctx context.Context, before time.Time) (int64, error) {
 	result, err := b.db.ExecContext(ctx, `
@@ -515,8 +506,9 @@ func (b *SQLiteBackend) writeBatch(ctx context.Context, records []UsageRecord) e
 			provider, model, api_key, auth_id, auth_index, source,
 			requested_at, failed, input_tokens, output_tokens,
 			reasoning_tokens, cached_tokens, total_tokens,
-			audio_tokens, cache_creation_input_tokens, cache_read_input_tokens, tool_use_prompt_tokens
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			audio_tokens, cache_creation_input_tokens, cache_read_input_tokens, tool_use_prompt_tokens,
+			metadata, hedge_cancelled
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		_ = tx.Rollback()
@@ -543,6 +535,8 @@ func (b *SQLiteBackend) writeBatch(ctx context.Context, records []UsageRecord) e
 			record.CacheCreationInputTokens,
 			record.CacheReadInputTokens,
 			record.ToolUsePromptTokens,
+			encodeMetadata(record.Metadata),
+			record.HedgeCancelled,
 		)
 		if err != nil {
 			_ = tx.Rollback()
@@ -19,7 +19,10 @@ type Record struct {
 	Source      string
 	RequestedAt time.Time
 	Failed      bool
-	Usage       *ir.Usage
+	// HedgeCancelled marks an attempt dropped because a hedged duplicate of
+	// the request answered first. Its tokens may be missing.
+	HedgeCancelled bool
+	Usage          *ir.Usage
 }
 
 // UsageRecord represents a single usage record for persistence.
@@ -41,6 +44,11 @@ type UsageRecord struct {
 	CacheCreationInputTokens int64
 	CacheReadInputTokens     int64
 	ToolUsePromptTokens      int64
+	// Metadata holds the tags of the client key, stored as JSON.
+	Metadata map[string]string
+	// HedgeCancelled marks an attempt dropped because a hedged duplicate of
+	// the request answered first.
+	HedgeCancelled bool
 }
 
 // Plugin consumes usage records emitted by the proxy runtime.
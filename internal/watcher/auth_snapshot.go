@@ -94,6 +94,12 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 					proxy = strings.TrimSpace(prov.ProxyURL)
 				}
 				auth := createProviderAuth(idGen, pName, lbl, key, strings.TrimSpace(prov.BaseURL), proxy, prov.Headers, prov.Models, prov.ExcludedModels, cfg, now)
+				if prov.Subscription {
+					auth.Attributes["subscription"] = "true"
+				}
+				if len(prov.Tags) > 0 {
+					auth.Attributes["tags"] = strings.Join(prov.Tags, ",")
+				}
 				out = append(out, auth)
 			}
 		}